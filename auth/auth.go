@@ -0,0 +1,64 @@
+// Package auth holds the pieces of authentication that do not depend on
+// storage: password hashing, bearer tokens and the caller carried by a
+// request context.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"subd/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// tokenBytes is the entropy of a bearer token.
+const tokenBytes = 32
+
+// dummyHash is compared against when a login names an unknown user, so
+// that the answer takes as long as for a wrong password.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+
+func HashPassword(password string) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
+
+// CheckPassword tells whether password matches hash; a nil hash never
+// matches but costs the same.
+func CheckPassword(hash []byte, password string) bool {
+	if hash == nil {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+}
+
+// NewToken returns a random bearer token.
+func NewToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// TokenHash is what the store keeps of a token.
+func TokenHash(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+type callerKey struct{}
+
+// WithCaller returns a context carrying the authenticated caller.
+func WithCaller(ctx context.Context, caller models.Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom returns the caller of an authenticated request.
+func CallerFrom(ctx context.Context) (models.Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(models.Caller)
+	return caller, ok
+}
@@ -1,10 +1,36 @@
 package main
 
 import (
+	"fmt"
+	"log"
+	"os"
+
+	"subd/config"
 	"subd/server"
 )
 
 func main() {
-	s := server.NewServer()
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if cfg.PrintConfig {
+		dump, err := cfg.Dump()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(string(dump))
+		return
+	}
+
+	if len(cfg.Args) > 0 && cfg.Args[0] == "migrate" {
+		if err := server.Migrate(cfg, cfg.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	s := server.NewServer(cfg)
 	s.ListenAndServe()
 }
@@ -0,0 +1,341 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+type Pool struct {
+	MaxConns int32 `yaml:"max_conns"`
+	MinConns int32 `yaml:"min_conns"`
+}
+
+type Timeouts struct {
+	// Statement is the PostgreSQL statement_timeout, 0 disables it.
+	Statement time.Duration `yaml:"statement"`
+	// Request bounds the context of every HTTP request.
+	Request time.Duration `yaml:"request"`
+	// Drain is how long the server keeps accepting requests, with its
+	// readiness probe failing, once a termination signal arrives.
+	Drain time.Duration `yaml:"drain"`
+	// Shutdown is how long in-flight requests may take to finish once the
+	// server stops accepting them.
+	Shutdown time.Duration `yaml:"shutdown"`
+}
+
+type Limits struct {
+	// Default is used when a list request has no limit.
+	Default int `yaml:"default"`
+	// Max caps the limit a client may ask for.
+	Max int `yaml:"max"`
+}
+
+type Features struct {
+	// AutoMigrate applies pending migrations on server start.
+	AutoMigrate bool `yaml:"auto_migrate"`
+	// Metrics exposes Prometheus metrics on /metrics.
+	Metrics bool `yaml:"metrics"`
+}
+
+type Auth struct {
+	// Required, the default, makes every change an authenticated request.
+	// Turning it off lets anonymous requests act as any user, as before
+	// accounts existed; signed-in callers still cannot.
+	Required bool `yaml:"required"`
+	// SessionTTL is how long a login token stays valid.
+	SessionTTL time.Duration `yaml:"session_ttl"`
+}
+
+type Events struct {
+	// Retention is how long live events stay available to clients that
+	// resume a stream.
+	Retention time.Duration `yaml:"retention"`
+}
+
+type Webhooks struct {
+	// MaxAttempts is how many times a delivery is tried before it is
+	// dead-lettered.
+	MaxAttempts int `yaml:"max_attempts"`
+	// Backoff is the pause after the first failed attempt; it doubles with
+	// every further one.
+	Backoff time.Duration `yaml:"backoff"`
+	// Timeout bounds a single attempt.
+	Timeout time.Duration `yaml:"timeout"`
+	// Retention is how long finished deliveries are kept.
+	Retention time.Duration `yaml:"retention"`
+	// AllowPrivate lets deliveries reach loopback, private and link-local
+	// addresses, e.g. a receiver on the same host while developing.
+	AllowPrivate bool `yaml:"allow_private"`
+}
+
+type Config struct {
+	DSN      string   `yaml:"dsn"`
+	Listen   string   `yaml:"listen"`
+	Storage  string   `yaml:"storage"`
+	Unlogged bool     `yaml:"unlogged"`
+	Pool     Pool     `yaml:"pool"`
+	Timeouts Timeouts `yaml:"timeouts"`
+	Limits   Limits   `yaml:"limits"`
+	LogLevel string   `yaml:"log_level"`
+	Features Features `yaml:"features"`
+	Auth     Auth     `yaml:"auth"`
+	Events   Events   `yaml:"events"`
+	Webhooks Webhooks `yaml:"webhooks"`
+	// AdminToken authorizes administrative requests, passed in the
+	// X-Admin-Token header. Administrative endpoints are disabled while it
+	// is empty.
+	AdminToken string `yaml:"admin_token"`
+
+	// PrintConfig asks the caller to dump the effective configuration and
+	// exit.
+	PrintConfig bool `yaml:"-"`
+	// Args are the positional arguments left after the flags.
+	Args []string `yaml:"-"`
+}
+
+func Default() *Config {
+	return &Config{
+		DSN:     "user=admin dbname=subd password=admin host=localhost port=5432 sslmode=disable",
+		Listen:  ":5000",
+		Storage: StoragePostgres,
+		Pool: Pool{
+			MaxConns: 50,
+		},
+		Timeouts: Timeouts{
+			Request:  10 * time.Second,
+			Drain:    5 * time.Second,
+			Shutdown: 30 * time.Second,
+		},
+		Limits: Limits{
+			Default: 100,
+			Max:     10000,
+		},
+		LogLevel: "info",
+		Features: Features{
+			AutoMigrate: true,
+			Metrics:     true,
+		},
+		Auth: Auth{
+			Required:   true,
+			SessionTTL: 30 * 24 * time.Hour,
+		},
+		Events: Events{
+			Retention: time.Hour,
+		},
+		Webhooks: Webhooks{
+			MaxAttempts: 8,
+			Backoff:     10 * time.Second,
+			Timeout:     10 * time.Second,
+			Retention:   7 * 24 * time.Hour,
+		},
+	}
+}
+
+// setting binds one configuration field to a flag and an environment
+// variable.
+type setting struct {
+	flag  string
+	usage string
+	value flag.Value
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{"dsn", "PostgreSQL connection string", (*stringValue)(&c.DSN)},
+		{"listen", "HTTP listen address", (*stringValue)(&c.Listen)},
+		{"storage", "repository backend: " + StoragePostgres + " or " + StorageMemory, (*stringValue)(&c.Storage)},
+		{"unlogged", "create tables as UNLOGGED when applying migrations", (*boolValue)(&c.Unlogged)},
+		{"pool-max-conns", "maximum number of pooled connections", (*int32Value)(&c.Pool.MaxConns)},
+		{"pool-min-conns", "minimum number of pooled connections", (*int32Value)(&c.Pool.MinConns)},
+		{"statement-timeout", "PostgreSQL statement_timeout, 0 disables it", (*durationValue)(&c.Timeouts.Statement)},
+		{"request-timeout", "deadline of every HTTP request", (*durationValue)(&c.Timeouts.Request)},
+		{"drain-delay", "time the server keeps serving with a failing readiness probe before shutting down", (*durationValue)(&c.Timeouts.Drain)},
+		{"shutdown-timeout", "time allowed for in-flight requests on shutdown", (*durationValue)(&c.Timeouts.Shutdown)},
+		{"default-limit", "page size when a list request has no limit", (*intValue)(&c.Limits.Default)},
+		{"max-limit", "largest page size a client may ask for", (*intValue)(&c.Limits.Max)},
+		{"log-level", "debug, info, warn, error or off", (*stringValue)(&c.LogLevel)},
+		{"auto-migrate", "apply pending migrations on start", (*boolValue)(&c.Features.AutoMigrate)},
+		{"metrics", "expose Prometheus metrics on /metrics", (*boolValue)(&c.Features.Metrics)},
+		{"auth-required", "require authentication for every change", (*boolValue)(&c.Auth.Required)},
+		{"session-ttl", "lifetime of login tokens", (*durationValue)(&c.Auth.SessionTTL)},
+		{"event-retention", "how long live events stay available to resuming clients", (*durationValue)(&c.Events.Retention)},
+		{"webhook-max-attempts", "attempts of a webhook delivery before it is dead-lettered", (*intValue)(&c.Webhooks.MaxAttempts)},
+		{"webhook-backoff", "pause after the first failed webhook delivery, doubled with every further one", (*durationValue)(&c.Webhooks.Backoff)},
+		{"webhook-timeout", "deadline of a webhook delivery attempt", (*durationValue)(&c.Webhooks.Timeout)},
+		{"webhook-retention", "how long finished webhook deliveries are kept", (*durationValue)(&c.Webhooks.Retention)},
+		{"webhook-allow-private", "let webhooks reach loopback, private and link-local addresses", (*boolValue)(&c.Webhooks.AllowPrivate)},
+		{"admin-token", "token of administrative requests, empty disables them", (*stringValue)(&c.AdminToken)},
+	}
+}
+
+// envName maps a flag name to its environment variable, e.g. pool-max-conns
+// to SUBD_POOL_MAX_CONNS.
+func envName(flagName string) string {
+	return "SUBD_" + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+// Load builds the configuration from, in increasing priority, the defaults,
+// the YAML file given by -config or SUBD_CONFIG, SUBD_* environment
+// variables and command line flags.
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("subd", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("SUBD_CONFIG"), "YAML configuration file")
+	fs.BoolVar(&cfg.PrintConfig, "print-config", false, "print the effective configuration and exit")
+
+	// Flags are only recorded during parsing and applied last, so that they
+	// override the file and the environment.
+	settings := cfg.settings()
+	given := make(map[string]string)
+	for _, s := range settings {
+		fs.Var(&recorder{name: s.flag, value: s.value, given: given}, s.flag, s.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	cfg.Args = fs.Args()
+
+	if *path != "" {
+		data, err := ioutil.ReadFile(*path)
+		if err != nil {
+			return nil, err
+		}
+		if err = yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", *path, err)
+		}
+	}
+
+	for _, s := range settings {
+		if env, ok := os.LookupEnv(envName(s.flag)); ok {
+			if err := s.value.Set(env); err != nil {
+				return nil, fmt.Errorf("%s: %w", envName(s.flag), err)
+			}
+		}
+	}
+	for _, s := range settings {
+		if raw, ok := given[s.flag]; ok {
+			if err := s.value.Set(raw); err != nil {
+				return nil, fmt.Errorf("-%s: %w", s.flag, err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) Validate() error {
+	var problems []string
+	if c.Storage != StoragePostgres && c.Storage != StorageMemory {
+		problems = append(problems, fmt.Sprintf("storage must be %s or %s", StoragePostgres, StorageMemory))
+	}
+	if c.Storage == StoragePostgres {
+		if _, err := pgxpool.ParseConfig(c.DSN); err != nil {
+			problems = append(problems, "dsn: "+err.Error())
+		}
+	}
+	if c.Listen == "" {
+		problems = append(problems, "listen must not be empty")
+	}
+	if c.Pool.MaxConns < 1 {
+		problems = append(problems, "pool.max_conns must be positive")
+	}
+	if c.Pool.MinConns < 0 || c.Pool.MinConns > c.Pool.MaxConns {
+		problems = append(problems, "pool.min_conns must be between 0 and pool.max_conns")
+	}
+	if c.Timeouts.Statement < 0 {
+		problems = append(problems, "timeouts.statement must not be negative")
+	}
+	if c.Timeouts.Request <= 0 {
+		problems = append(problems, "timeouts.request must be positive")
+	}
+	if c.Timeouts.Shutdown <= 0 {
+		problems = append(problems, "timeouts.shutdown must be positive")
+	}
+	if c.Limits.Default < 1 || c.Limits.Max < c.Limits.Default {
+		problems = append(problems, "limits must satisfy 1 <= default <= max")
+	}
+	if c.Auth.SessionTTL <= 0 {
+		problems = append(problems, "auth.session_ttl must be positive")
+	}
+	if c.Events.Retention <= 0 {
+		problems = append(problems, "events.retention must be positive")
+	}
+	if c.Webhooks.MaxAttempts < 1 {
+		problems = append(problems, "webhooks.max_attempts must be positive")
+	}
+	if c.Webhooks.Backoff <= 0 || c.Webhooks.Timeout <= 0 || c.Webhooks.Retention <= 0 {
+		problems = append(problems, "webhooks.backoff, webhooks.timeout and webhooks.retention must be positive")
+	}
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error", "off":
+	default:
+		problems = append(problems, "log_level must be debug, info, warn, error or off")
+	}
+
+	if len(problems) != 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// PoolConfig turns the database settings into a pgxpool configuration.
+func (c *Config) PoolConfig() (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(c.DSN)
+	if err != nil {
+		return nil, err
+	}
+	poolConfig.MaxConns = c.Pool.MaxConns
+	poolConfig.MinConns = c.Pool.MinConns
+	if c.Timeouts.Statement > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] =
+			strconv.FormatInt(c.Timeouts.Statement.Milliseconds(), 10)
+	}
+
+	return poolConfig, nil
+}
+
+// Dump renders the configuration as YAML with the database password and
+// the admin token masked.
+func (c *Config) Dump() ([]byte, error) {
+	redacted := *c
+	redacted.DSN = redactDSN(c.DSN)
+	if redacted.AdminToken != "" {
+		redacted.AdminToken = "xxxxx"
+	}
+
+	return yaml.Marshal(&redacted)
+}
+
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		return u.Redacted()
+	}
+
+	fields := strings.Fields(dsn)
+	for i, field := range fields {
+		if strings.HasPrefix(field, "password=") {
+			fields[i] = "password=xxxxx"
+		}
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"flag"
+	"strconv"
+	"time"
+)
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error {
+	*v = stringValue(s)
+	return nil
+}
+
+func (v *stringValue) String() string { return string(*v) }
+
+type boolValue bool
+
+func (v *boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*v = boolValue(b)
+	return nil
+}
+
+func (v *boolValue) String() string { return strconv.FormatBool(bool(*v)) }
+
+func (v *boolValue) IsBoolFlag() bool { return true }
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*v = intValue(i)
+	return nil
+}
+
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type int32Value int32
+
+func (v *int32Value) Set(s string) error {
+	i, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return err
+	}
+	*v = int32Value(i)
+	return nil
+}
+
+func (v *int32Value) String() string { return strconv.FormatInt(int64(*v), 10) }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*v = durationValue(d)
+	return nil
+}
+
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+
+// recorder stands in for a setting on the flag set: it only remembers the
+// raw value, which is applied after the file and the environment.
+type recorder struct {
+	name  string
+	value flag.Value
+	given map[string]string
+}
+
+func (r *recorder) Set(s string) error {
+	r.given[r.name] = s
+	return nil
+}
+
+func (r *recorder) String() string {
+	if r.value == nil {
+		return ""
+	}
+	return r.value.String()
+}
+
+func (r *recorder) IsBoolFlag() bool {
+	b, ok := r.value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
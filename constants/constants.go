@@ -1,6 +0,0 @@
-package constants
-
-const (
-	NotFound = 404
-	DBConnect      = "user=admin dbname=subd password=admin host=localhost port=5432 sslmode=disable pool_max_conns=50"
-)
@@ -0,0 +1,26 @@
+// Package cursor turns the keys of keyset pagination into opaque strings
+// that clients hand back to get the next page.
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Encode returns the cursor of key, a JSON-marshalable value.
+func Encode(key interface{}) (string, error) {
+	b, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Decode reads a cursor made by Encode into key.
+func Decode(cursor string, key interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, key)
+}
@@ -0,0 +1,35 @@
+package cursor
+
+import "testing"
+
+func TestRoundTrip(t *testing.T) {
+	type key struct {
+		Id int64 `json:"i"`
+	}
+	c, err := Encode(key{Id: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got key
+	if err := Decode(c, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Id != 42 {
+		t.Errorf("Decode(Encode(42)) = %d", got.Id)
+	}
+}
+
+func TestEncodeFailure(t *testing.T) {
+	if _, err := Encode(make(chan int)); err == nil {
+		t.Error("Encode took a value JSON can't marshal")
+	}
+}
+
+func TestDecodeGarbage(t *testing.T) {
+	var key struct{}
+	for _, c := range []string{"not base64!", "bm90IGpzb24"} {
+		if err := Decode(c, &key); err == nil {
+			t.Errorf("Decode(%q) succeeded", c)
+		}
+	}
+}
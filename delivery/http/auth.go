@@ -0,0 +1,155 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"subd/auth"
+	"subd/domain"
+	"subd/models"
+
+	"github.com/labstack/echo"
+	"github.com/mailru/easyjson"
+)
+
+// sessionCookie carries the token for browsers; other clients send it as
+// a bearer token.
+const sessionCookie = "subd_session"
+
+// token returns the session token of a request, if any.
+func token(c echo.Context) string {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	if len(header) > len("Bearer ") && strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return header[len("Bearer "):]
+	}
+	if cookie, err := c.Cookie(sessionCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// authenticate puts the caller of a request carrying a session token into
+// its context; the admin token stands for an anonymous admin. Requests
+// without a token go on anonymously, requests with an unknown or expired
+// one are refused.
+func (sd SmthHandler) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if sd.adminToken(c) {
+			caller := models.Caller{Role: models.RoleAdmin}
+			c.SetRequest(c.Request().WithContext(auth.WithCaller(c.Request().Context(), caller)))
+			return next(c)
+		}
+
+		t := token(c)
+		if t == "" {
+			return next(c)
+		}
+
+		caller, err := sd.UseCase.Authenticate(c.Request().Context(), t)
+		if err != nil {
+			return err
+		}
+		c.SetRequest(c.Request().WithContext(auth.WithCaller(c.Request().Context(), caller)))
+
+		return next(c)
+	}
+}
+
+func (sd SmthHandler) Login(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	login := &models.Login{}
+
+	if err := easyjson.UnmarshalFromReader(c.Request().Body, login); err != nil {
+		return invalid("Malformed login", err)
+	}
+	if err := check(login, false); err != nil {
+		return err
+	}
+
+	session, err := sd.UseCase.Login(c.Request().Context(), *login)
+	if err != nil {
+		return err
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     sessionCookie,
+		Value:    session.Token,
+		Path:     "/",
+		Expires:  time.Time(session.Expires),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return c.JSON(http.StatusOK, session)
+}
+
+func (sd SmthHandler) Logout(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	t := token(c)
+	if t == "" {
+		return domain.ErrUnauthenticated.With("No session to log out of")
+	}
+	if err := sd.UseCase.Logout(c.Request().Context(), t); err != nil {
+		return err
+	}
+
+	c.SetCookie(&http.Cookie{Name: sessionCookie, Path: "/", MaxAge: -1, HttpOnly: true})
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sd SmthHandler) SetRole(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	change := &models.RoleChange{}
+
+	if err := easyjson.UnmarshalFromReader(c.Request().Body, change); err != nil {
+		return invalid("Malformed role", err)
+	}
+	if err := check(change, false); err != nil {
+		return err
+	}
+
+	err := sd.UseCase.SetRole(c.Request().Context(), c.Param("nickname"), change.Role)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sd SmthHandler) GetModerators(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	users, err := sd.UseCase.Moderators(c.Request().Context(), c.Param("slug"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
+func (sd SmthHandler) AppointModerator(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	err := sd.UseCase.AppointModerator(c.Request().Context(), c.Param("slug"), c.Param("nickname"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sd SmthHandler) RevokeModerator(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	err := sd.UseCase.RevokeModerator(c.Request().Context(), c.Param("slug"), c.Param("nickname"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
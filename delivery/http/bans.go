@@ -0,0 +1,78 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"subd/models"
+
+	"github.com/labstack/echo"
+	"github.com/mailru/easyjson"
+)
+
+func (sd SmthHandler) GetForumBans(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	bans, err := sd.UseCase.ForumBans(c.Request().Context(), c.Param("slug"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, bans)
+}
+
+func (sd SmthHandler) BanFromForum(c echo.Context) error {
+	return sd.ban(c, c.Param("slug"))
+}
+
+func (sd SmthHandler) UnbanFromForum(c echo.Context) error {
+	return sd.unban(c, c.Param("slug"))
+}
+
+func (sd SmthHandler) BanUser(c echo.Context) error {
+	return sd.ban(c, "")
+}
+
+func (sd SmthHandler) UnbanUser(c echo.Context) error {
+	return sd.unban(c, "")
+}
+
+// ban reads the optional reason and expiry of a ban; an empty body bans
+// for good without a reason.
+func (sd SmthHandler) ban(c echo.Context, forum string) error {
+	defer c.Request().Body.Close()
+
+	body, err := ioutil.ReadAll(c.Request().Body)
+	if err != nil {
+		return invalid("Malformed ban", err)
+	}
+	ban := &models.Ban{}
+	if len(body) != 0 {
+		if err := easyjson.Unmarshal(body, ban); err != nil {
+			return invalid("Malformed ban", err)
+		}
+	}
+	if err := check(ban, false); err != nil {
+		return err
+	}
+	ban.Nickname = c.Param("nickname")
+	ban.Forum = forum
+
+	result, err := sd.UseCase.Ban(c.Request().Context(), *ban)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (sd SmthHandler) unban(c echo.Context, forum string) error {
+	defer c.Request().Body.Close()
+
+	err := sd.UseCase.Unban(c.Request().Context(), forum, c.Param("nickname"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
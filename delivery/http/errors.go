@@ -0,0 +1,106 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"subd/domain"
+	"subd/models"
+
+	"github.com/labstack/echo"
+)
+
+// ErrorBody is the envelope every failed request is answered with.
+type ErrorBody struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+var kindStatus = map[domain.Kind]int{
+	domain.KindInternal:        http.StatusInternalServerError,
+	domain.KindInvalid:         http.StatusBadRequest,
+	domain.KindNotFound:        http.StatusNotFound,
+	domain.KindConflict:        http.StatusConflict,
+	domain.KindUnprocessable:   http.StatusUnprocessableEntity,
+	domain.KindForbidden:       http.StatusForbidden,
+	domain.KindUnauthenticated: http.StatusUnauthorized,
+}
+
+// ErrorHandler renders domain errors, and the errors echo raises itself
+// (unknown route, wrong method), as an ErrorBody. Internal errors are logged
+// and reported without their cause.
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status, body := render(err)
+	if status == http.StatusInternalServerError {
+		c.Logger().Error(err)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = c.JSON(status, body)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+func render(err error) (int, ErrorBody) {
+	var de *domain.Error
+	if errors.As(err, &de) {
+		status := kindStatus[de.Kind]
+		if de.Kind == domain.KindInternal {
+			return status, ErrorBody{Code: domain.ErrInternal.Code, Message: domain.ErrInternal.Message}
+		}
+		return status, ErrorBody{Code: de.Code, Message: de.Message, Details: de.Details}
+	}
+
+	var he *echo.HTTPError
+	if errors.As(err, &he) && he.Code < http.StatusInternalServerError {
+		return he.Code, ErrorBody{
+			Code:    httpCode(he.Code),
+			Message: strings.ToLower(http.StatusText(he.Code)),
+		}
+	}
+
+	return http.StatusInternalServerError, ErrorBody{Code: domain.ErrInternal.Code, Message: domain.ErrInternal.Message}
+}
+
+// httpCode derives an error code from a status, e.g. method_not_allowed.
+func httpCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+// invalid reports a request that could not be decoded.
+func invalid(message string, err error) error {
+	return domain.ErrInvalid.With(message, "reason", err.Error())
+}
+
+// check validates a decoded body against the rules of its model, before it
+// reaches the use case.
+func check(v interface{}, partial bool) error {
+	var err error
+	if partial {
+		err = models.ValidatePartial(v)
+	} else {
+		err = models.Validate(v)
+	}
+	return rejected(err)
+}
+
+func rejected(err error) error {
+	if err == nil {
+		return nil
+	}
+	var ve models.ValidationError
+	if errors.As(err, &ve) {
+		return domain.ErrValidation.With("Request does not pass validation", "fields", ve)
+	}
+	return domain.Internal(err)
+}
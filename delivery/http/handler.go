@@ -1,53 +1,156 @@
 package http
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/labstack/echo"
 	"github.com/mailru/easyjson"
 	"net/http"
+	"net/url"
 	"strconv"
 	smth "subd"
-	"subd/constants"
+	"subd/auth"
+	"subd/config"
+	"subd/domain"
+	"subd/live"
 	"subd/models"
+	"time"
 )
 
 type SmthHandler struct {
 	UseCase   smth.UseCase
+	Hub       *live.Hub
+	Limits    config.Limits
+	AdminToken string
 }
 
 //Можно добавить функции на автоинкремент!
-func CreateSmthHandler(e *echo.Echo, uc smth.UseCase) {
-	handler := SmthHandler{UseCase: uc}
+func CreateSmthHandler(e *echo.Echo, uc smth.UseCase, hub *live.Hub, cfg *config.Config) {
+	handler := SmthHandler{UseCase: uc, Hub: hub, Limits: cfg.Limits, AdminToken: cfg.AdminToken}
 
+	e.Use(handler.authenticate)
 
+	e.POST("/api/auth/login", handler.Login)
+	e.POST("/api/auth/logout", handler.Logout)
 	e.POST("/api/forum/create", handler.CreateForum)
 	e.GET("/api/forum/:slug/details", handler.ForumDetails)
+	e.GET("/api/forum/:slug/moderators", handler.GetModerators)
+	e.POST("/api/forum/:slug/moderators/:nickname", handler.AppointModerator)
+	e.DELETE("/api/forum/:slug/moderators/:nickname", handler.RevokeModerator)
+	e.GET("/api/forum/:slug/bans", handler.GetForumBans)
+	e.POST("/api/forum/:slug/bans/:nickname", handler.BanFromForum)
+	e.DELETE("/api/forum/:slug/bans/:nickname", handler.UnbanFromForum)
 	e.POST("/api/forum/:slug/create", handler.CreateThread)
 	e.GET("api/forum/:slug/users", handler.GetForumUsers)
 	e.GET("/api/forum/:slug/threads", handler.GetThreads)
+	e.GET("/api/forum/:slug/events", handler.ForumEvents)
+	e.GET("/api/forum/:slug/events/ws", handler.ForumEventsWS)
 	e.GET("/api/post/:id/details", handler.GetPostDetails)
 	e.POST("/api/post/:id/details", handler.EditMessage)
+	e.GET("/api/post/:id/revisions", handler.GetRevisions)
+	e.GET("/api/post/:id/revisions/diff", handler.DiffRevisions)
+	e.DELETE("/api/post/:id", handler.DeletePost)
+	e.POST("/api/post/:id/restore", handler.RestorePost, handler.admin)
 	e.POST("/api/service/clear", handler.Clear)
 	e.GET("/api/service/status", handler.Status)
+	e.GET("/api/search", handler.Search)
 	e.POST("/api/thread/:slug_or_id/create", handler.CreatePosts)
 	e.GET("/api/thread/:slug_or_id/details", handler.GetThreadDetails)
 	e.POST("/api/thread/:slug_or_id/details", handler.UpdateThread)
 	e.GET("/api/thread/:slug_or_id/posts", handler.GetThreadSort)
 	e.POST("/api/thread/:slug_or_id/vote", handler.Vote)
+	e.POST("/api/thread/:slug_or_id/read", handler.MarkThreadRead)
+	e.GET("/api/thread/:slug_or_id/events", handler.ThreadEvents)
+	e.GET("/api/thread/:slug_or_id/events/ws", handler.ThreadEventsWS)
+	e.DELETE("/api/thread/:slug_or_id", handler.DeleteThread)
+	e.POST("/api/thread/:slug_or_id/archive", handler.ArchiveThread)
+	e.DELETE("/api/thread/:slug_or_id/archive", handler.UnarchiveThread)
+	e.POST("/api/thread/:slug_or_id/moderate", handler.ModerateThread)
 	e.POST("/api/user/:nickname/create", handler.CreateUser)
 	e.GET("/api/user/:nickname/profile", handler.GetUser)
+	e.GET("/api/users", handler.FindUsers)
 	e.POST("/api/user/:nickname/profile", handler.UpdateUser)
+	e.GET("/api/user/:nickname/notifications", handler.GetNotifications)
+	e.GET("/api/user/:nickname/notifications/unread", handler.GetUnreadNotifications)
+	e.POST("/api/user/:nickname/notifications/read", handler.MarkNotificationsRead)
+	e.POST("/api/user/:nickname/notifications/:id/read", handler.MarkNotificationRead)
+	e.POST("/api/user/:nickname/subscriptions/forums/:slug", handler.SubscribeForum)
+	e.DELETE("/api/user/:nickname/subscriptions/forums/:slug", handler.UnsubscribeForum)
+	e.POST("/api/user/:nickname/subscriptions/threads/:slug_or_id", handler.SubscribeThread)
+	e.DELETE("/api/user/:nickname/subscriptions/threads/:slug_or_id", handler.UnsubscribeThread)
+	e.GET("/api/user/:nickname/subscriptions", handler.GetSubscriptions)
+	e.POST("/api/user/:nickname/conversations", handler.CreateConversation)
+	e.GET("/api/user/:nickname/conversations", handler.GetConversations)
+	e.GET("/api/user/:nickname/conversations/:id", handler.GetConversation)
+	e.POST("/api/user/:nickname/conversations/:id/messages", handler.SendMessage)
+	e.GET("/api/user/:nickname/conversations/:id/messages", handler.GetMessages)
+	e.POST("/api/user/:nickname/conversations/:id/messages/:message", handler.EditPrivateMessage)
+	e.POST("/api/user/:nickname/conversations/:id/read", handler.MarkConversationRead)
+	e.GET("/api/user/:nickname/blocks", handler.GetBlocks)
+	e.POST("/api/user/:nickname/blocks/:blocked", handler.Block)
+	e.DELETE("/api/user/:nickname/blocks/:blocked", handler.Unblock)
+	e.POST("/api/user/:nickname/role", handler.SetRole, handler.admin)
+	e.POST("/api/user/:nickname/ban", handler.BanUser, handler.admin)
+	e.DELETE("/api/user/:nickname/ban", handler.UnbanUser, handler.admin)
+	e.POST("/api/webhooks", handler.CreateWebhook)
+	e.GET("/api/webhooks", handler.GetWebhooks)
+	e.GET("/api/webhooks/:id", handler.GetWebhook)
+	e.DELETE("/api/webhooks/:id", handler.DeleteWebhook)
+	e.GET("/api/webhooks/:id/deliveries", handler.GetDeliveries)
+	e.POST("/api/webhooks/:id/deliveries/:delivery/redeliver", handler.Redeliver)
+}
+
+// limit reads the page size of a list request, falling back to the
+// configured default and capping it at the configured maximum.
+func (sd SmthHandler) limit(c echo.Context) int {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit <= 0 {
+		return sd.Limits.Default
+	}
+	if limit > sd.Limits.Max {
+		return sd.Limits.Max
+	}
+
+	return limit
+}
+
+// link points the client at the next page of a list: the same request
+// with the cursor in place of since.
+func link(c echo.Context, next string) {
+	if next == "" {
+		return
+	}
+	query := c.Request().URL.Query()
+	query.Del("since")
+	query.Set("cursor", next)
+	u := url.URL{Path: c.Request().URL.Path, RawQuery: query.Encode()}
+	c.Response().Header().Set("Link", "<"+u.String()+`>; rel="next"`)
+}
+
+// admin lets through requests of an admin account or carrying the
+// configured admin token, which authenticate turns into an admin caller.
+func (sd SmthHandler) admin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if caller, ok := auth.CallerFrom(c.Request().Context()); ok && caller.IsAdmin() {
+			return next(c)
+		}
+		return domain.ErrForbidden.With("Administrative request without a valid admin token")
+	}
+}
+
+// adminToken tells whether a request carries the configured admin token.
+func (sd SmthHandler) adminToken(c echo.Context) bool {
+	token := c.Request().Header.Get("X-Admin-Token")
+	return sd.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(sd.AdminToken)) == 1
 }
 
 func (sd SmthHandler) GetThreadSort(c echo.Context) error {
 	defer c.Request().Body.Close()
 
 	slugOrId := c.Param("slug_or_id")
-	limit, err := strconv.Atoi(c.QueryParam("limit"))
-	if err != nil || limit == 0 {
-		limit = 100
-	}
+	limit := sd.limit(c)
 	since, _ := strconv.Atoi(c.QueryParam("since"))
 	desc, err := strconv.ParseBool(c.QueryParam("desc"))
 	if err != nil {
@@ -55,39 +158,33 @@ func (sd SmthHandler) GetThreadSort(c echo.Context) error {
 	}
 	sort := c.QueryParam("sort")
 
-	var posts models.Posts
-	var status int
-	if sort == "tree" {
-		posts, status = sd.UseCase.GetThreadSortTree(slugOrId, limit, since, desc)
-		if status == constants.NotFound {
-			return echo.NewHTTPError(http.StatusNotFound, "Can't find post with id ")
+	after := c.QueryParam("cursor")
+	var unreadFor string
+	if unread, _ := strconv.ParseBool(c.QueryParam("unread_only")); unread {
+		if sort == "tree" || sort == "parent_tree" {
+			return invalid("unread_only works with the flat sort", errors.New("unread_only can't be used with sort="+sort))
 		}
-
-		return c.JSON(status, posts)
-	}
-	if sort == "parent_tree" {
-		posts, status = sd.UseCase.GetThreadSortParentTree(slugOrId, limit, since, desc)
-		if status == constants.NotFound {
-			return echo.NewHTTPError(http.StatusNotFound, "Can't find post with id ")
+		unreadFor = c.QueryParam("nickname")
+		if unreadFor == "" {
+			return invalid("unread_only needs a nickname", errors.New("no nickname to tell what is unread"))
 		}
-
-		return c.JSON(status, posts)
 	}
 
-	posts, status = sd.UseCase.GetThreadSortFlat(slugOrId, limit, since, desc)
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find post with id ")
+	var page models.PostsPage
+	switch sort {
+	case "tree":
+		page, err = sd.UseCase.GetThreadSortTree(c.Request().Context(), slugOrId, limit, since, desc, after)
+	case "parent_tree":
+		page, err = sd.UseCase.GetThreadSortParentTree(c.Request().Context(), slugOrId, limit, since, desc, after)
+	default:
+		page, err = sd.UseCase.GetThreadSortFlat(c.Request().Context(), slugOrId, limit, since, desc, after, unreadFor)
+	}
+	if err != nil {
+		return err
 	}
 
-	/*if len(posts) != 0 {
-		for i := range posts {
-			if posts[i].Id == 750014 || posts[i].Id == 749781 {
-				log.Info(posts[i].Id, i)
-				log.Info(slugOrId, since, limit, desc)
-			}
-		}
-	}*/
-	return c.JSON(status, posts)
+	link(c, page.Next)
+	return c.JSON(http.StatusOK, page.Posts)
 }
 
 func (sd SmthHandler) Vote(c echo.Context) error {
@@ -98,16 +195,68 @@ func (sd SmthHandler) Vote(c echo.Context) error {
 	vote := &models.Vote{}
 
 	if err := easyjson.UnmarshalFromReader(c.Request().Body, vote); err != nil {
-		return echo.NewHTTPError(http.StatusTeapot, err.Error())
+		return invalid("Malformed vote", err)
+	}
+	if err := check(vote, false); err != nil {
+		return err
+	}
+
+	thread, err := sd.UseCase.Vote(c.Request().Context(), slugOrId, *vote)
+	if err != nil {
+		return err
 	}
 
-	thread, status := sd.UseCase.Vote(slugOrId, *vote)
+	return c.JSON(http.StatusOK, thread)
+}
+
+func (sd SmthHandler) DeleteThread(c echo.Context) error {
+	defer c.Request().Body.Close()
 
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find thread with slug " + slugOrId)
+	err := sd.UseCase.DeleteThread(c.Request().Context(), c.Param("slug_or_id"))
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, thread)
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sd SmthHandler) ArchiveThread(c echo.Context) error {
+	return sd.setArchived(c, true)
+}
+
+func (sd SmthHandler) UnarchiveThread(c echo.Context) error {
+	return sd.setArchived(c, false)
+}
+
+func (sd SmthHandler) setArchived(c echo.Context, archived bool) error {
+	defer c.Request().Body.Close()
+
+	thread, err := sd.UseCase.ArchiveThread(c.Request().Context(), c.Param("slug_or_id"), archived)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, thread)
+}
+
+func (sd SmthHandler) ModerateThread(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	moderation := &models.ThreadModeration{}
+
+	if err := easyjson.UnmarshalFromReader(c.Request().Body, moderation); err != nil {
+		return invalid("Malformed moderation", err)
+	}
+	if err := check(moderation, false); err != nil {
+		return err
+	}
+
+	thread, err := sd.UseCase.ModerateThread(c.Request().Context(), c.Param("slug_or_id"), *moderation)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, thread)
 }
 
 func (sd SmthHandler) UpdateThread(c echo.Context) error {
@@ -118,19 +267,18 @@ func (sd SmthHandler) UpdateThread(c echo.Context) error {
 	newThread := &models.Thread{}
 
 	if err := easyjson.UnmarshalFromReader(c.Request().Body, newThread); err != nil {
-		return echo.NewHTTPError(http.StatusTeapot, err.Error())
+		return invalid("Malformed thread", err)
 	}
-
-	thread, status := sd.UseCase.UpdateThread(slugOrId, *newThread)
-
-	if status == http.StatusConflict {
-		return echo.NewHTTPError(http.StatusConflict, "Can't find thread with slug " + slugOrId)
+	if err := check(newThread, true); err != nil {
+		return err
 	}
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find thread with slug " + slugOrId)
+
+	thread, err := sd.UseCase.UpdateThread(c.Request().Context(), slugOrId, *newThread)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, thread)
+	return c.JSON(http.StatusOK, thread)
 }
 
 func (sd SmthHandler) UpdateUser(c echo.Context) error {
@@ -141,19 +289,19 @@ func (sd SmthHandler) UpdateUser(c echo.Context) error {
 	newUser := &models.User{}
 
 	if err := easyjson.UnmarshalFromReader(c.Request().Body, newUser); err != nil {
-		return echo.NewHTTPError(http.StatusTeapot, err.Error())
+		return invalid("Malformed user", err)
 	}
-
-	user, status := sd.UseCase.UpdateUser(nickname, *newUser)
-
-	if status == http.StatusConflict {
-		return echo.NewHTTPError(http.StatusConflict, "Can't find user with nickname " + nickname)
+	newUser.Nickname = nickname
+	if err := check(newUser, true); err != nil {
+		return err
 	}
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find user with nickname " + nickname)
+
+	user, err := sd.UseCase.UpdateUser(c.Request().Context(), nickname, *newUser)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, user)
+	return c.JSON(http.StatusOK, user)
 }
 
 func (sd SmthHandler) GetUser(c echo.Context) error {
@@ -161,13 +309,12 @@ func (sd SmthHandler) GetUser(c echo.Context) error {
 
 	nickname := c.Param("nickname")
 
-	user, status := sd.UseCase.GetUser(nickname)
-
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find user with nickname " + nickname)
+	user, err := sd.UseCase.GetUser(c.Request().Context(), nickname)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, user)
+	return c.JSON(http.StatusOK, user)
 }
 
 func (sd SmthHandler) CreateUser(c echo.Context) error {
@@ -175,27 +322,33 @@ func (sd SmthHandler) CreateUser(c echo.Context) error {
 
 	nickname := c.Param("nickname")
 
-	newUser := &models.User{}
+	signup := &models.Signup{}
 
-	if err := easyjson.UnmarshalFromReader(c.Request().Body, newUser); err != nil {
-		return echo.NewHTTPError(http.StatusTeapot, err.Error())
+	if err := easyjson.UnmarshalFromReader(c.Request().Body, signup); err != nil {
+		return invalid("Malformed user", err)
+	}
+	signup.Nickname = nickname
+	if err := check(signup, false); err != nil {
+		return err
 	}
 
-	users, status := sd.UseCase.CreateUser(nickname, *newUser)
-
-	if status == http.StatusConflict {
-		return c.JSON(status, users)
+	users, err := sd.UseCase.CreateUser(c.Request().Context(), nickname, signup.User, signup.Password)
+	if errors.Is(err, domain.ErrUserExists) {
+		return c.JSON(http.StatusConflict, users)
+	}
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, users[0])
+	return c.JSON(http.StatusCreated, users[0])
 }
 
 func (sd SmthHandler) Status(c echo.Context) error {
 	defer c.Request().Body.Close()
 
-	status, err := sd.UseCase.Status()
+	status, err := sd.UseCase.Status(c.Request().Context())
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, status)
@@ -204,9 +357,9 @@ func (sd SmthHandler) Status(c echo.Context) error {
 func (sd SmthHandler) Clear(c echo.Context) error {
 	defer c.Request().Body.Close()
 
-	err := sd.UseCase.Clear()
+	err := sd.UseCase.Clear(c.Request().Context())
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return nil
@@ -217,33 +370,119 @@ func (sd SmthHandler) EditMessage(c echo.Context) error {
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil{
-		return echo.NewHTTPError(http.StatusTeapot, err.Error())
+		return invalid("Post id must be a number", err)
 	}
 
 	newMessage := &models.NewMessage{}
 
 	if err := easyjson.UnmarshalFromReader(c.Request().Body, newMessage); err != nil {
-		return echo.NewHTTPError(http.StatusTeapot, err.Error())
+		return invalid("Malformed message", err)
+	}
+	if err := check(newMessage, false); err != nil {
+		return err
 	}
 
 	if newMessage.Message == "" {
-		post, status := sd.UseCase.EditMessageNull(id)
-		if status == constants.NotFound {
-			return echo.NewHTTPError(http.StatusNotFound, "Can't find post with id "+fmt.Sprint(id))
+		post, err := sd.UseCase.EditMessageNull(c.Request().Context(), id)
+		if err != nil {
+			return err
 		}
-		return c.JSON(status, post)
+		return c.JSON(http.StatusOK, post)
 	}
 
-	post, status := sd.UseCase.EditMessage(id, newMessage.Message)
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find post with id "+fmt.Sprint(id))
-	}
-	if status == http.StatusConflict {
+	post, err := sd.UseCase.EditMessage(c.Request().Context(), id, newMessage.Message, newMessage.Editor)
+	if errors.Is(err, domain.ErrPostUnchanged) {
 		postNull := models.ConvertPostToNullMessage(post)
 		return c.JSON(http.StatusOK, postNull)
 	}
+	if err != nil {
+		return err
+	}
 
-	return c.JSON(status, post)
+	return c.JSON(http.StatusOK, post)
+}
+
+func (sd SmthHandler) GetRevisions(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil{
+		return invalid("Post id must be a number", err)
+	}
+
+	revisions, err := sd.UseCase.Revisions(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, revisions)
+}
+
+// DiffRevisions compares version from (1 by default) with version to (the
+// current message by default).
+func (sd SmthHandler) DiffRevisions(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil{
+		return invalid("Post id must be a number", err)
+	}
+
+	from, to := 1, 0
+	if raw := c.QueryParam("from"); raw != "" {
+		if from, err = strconv.Atoi(raw); err != nil {
+			return invalid("Revision must be a number", err)
+		}
+	}
+	if raw := c.QueryParam("to"); raw != "" {
+		if to, err = strconv.Atoi(raw); err != nil {
+			return invalid("Revision must be a number", err)
+		}
+	}
+
+	diff, err := sd.UseCase.DiffRevisions(c.Request().Context(), id, from, to)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, diff)
+}
+
+func (sd SmthHandler) DeletePost(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil{
+		return invalid("Post id must be a number", err)
+	}
+
+	err = sd.UseCase.DeletePost(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sd SmthHandler) RestorePost(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil{
+		return invalid("Post id must be a number", err)
+	}
+
+	err = sd.UseCase.RestorePost(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	post, err := sd.UseCase.GetPost(c.Request().Context(), id, "")
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, post.Post)
 }
 
 func (sd SmthHandler) GetThreadDetails(c echo.Context) error {
@@ -251,12 +490,12 @@ func (sd SmthHandler) GetThreadDetails(c echo.Context) error {
 
 	slugOrId := c.Param("slug_or_id")
 
-	thread, status := sd.UseCase.GetThread(slugOrId)
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find thread with id " + slugOrId)
+	thread, err := sd.UseCase.GetThread(c.Request().Context(), slugOrId)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, thread)
+	return c.JSON(http.StatusOK, thread)
 }
 
 func (sd SmthHandler) GetPostDetails(c echo.Context) error {
@@ -265,59 +504,79 @@ func (sd SmthHandler) GetPostDetails(c echo.Context) error {
 	related := c.QueryParam("related")
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil{
-		return echo.NewHTTPError(http.StatusTeapot, err.Error())
+		return invalid("Post id must be a number", err)
 	}
 
-	post, status := sd.UseCase.GetPost(id, related)
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find post with id " + fmt.Sprint(id))
+	post, err := sd.UseCase.GetPost(c.Request().Context(), id, related)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, post)
+	return c.JSON(http.StatusOK, post)
 }
 
 func (sd SmthHandler) GetThreads(c echo.Context) error {
 	defer c.Request().Body.Close()
 
-	slug := c.Param("slug")
-	limit, err := strconv.Atoi(c.QueryParam("limit"))
-	if err != nil || limit == 0 {
-		limit = 100
-	}
-	since := c.QueryParam("since")
 	desc, err := strconv.ParseBool(c.QueryParam("desc"))
 	if err != nil {
 		desc = false
 	}
+	query := models.ThreadQuery{
+		Forum:  c.Param("slug"),
+		Sort:   c.QueryParam("sort"),
+		Author: c.QueryParam("author"),
+		Title:  c.QueryParam("title"),
+		Limit:  sd.limit(c),
+		Since:  c.QueryParam("since"),
+		Desc:   desc,
+	}
+	switch query.Sort {
+	case "", models.ThreadsByCreated, models.ThreadsByVotes, models.ThreadsByActivity, models.ThreadsByPosts:
+	default:
+		return invalid("Malformed thread query", errors.New("sort must be created, votes, activity or posts"))
+	}
+	for param, dst := range map[string]**time.Time{"from": &query.From, "to": &query.To} {
+		if value := c.QueryParam(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return invalid("Malformed thread query", err)
+			}
+			*dst = &t
+		}
+	}
+	if err := check(&query, false); err != nil {
+		return err
+	}
 
-	threads, status := sd.UseCase.GetThreads(slug, limit, since, desc)
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find forum with slug " + slug)
+	page, err := sd.UseCase.GetThreads(c.Request().Context(), query, c.QueryParam("cursor"))
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, threads)
+	link(c, page.Next)
+	return c.JSON(http.StatusOK, page.Threads)
 }
 
 func (sd SmthHandler) GetForumUsers(c echo.Context) error {
 	defer c.Request().Body.Close()
 
 	slug := c.Param("slug")
-	limit, err := strconv.Atoi(c.QueryParam("limit"))
-	if err != nil || limit == 0 {
-		limit = 100
-	}
+	limit := sd.limit(c)
 	since := c.QueryParam("since")
 	desc, err := strconv.ParseBool(c.QueryParam("desc"))
 	if err != nil {
 		desc = false
 	}
+	excludeBanned, _ := strconv.ParseBool(c.QueryParam("exclude_banned"))
 
-	users, status := sd.UseCase.GetForumUsers(slug, limit, since, desc)
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find forum with slug " + slug)
+	page, err := sd.UseCase.GetForumUsers(c.Request().Context(), slug, limit, since, desc, excludeBanned, c.QueryParam("cursor"))
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, users)
+	link(c, page.Next)
+	return c.JSON(http.StatusOK, page.Users)
 }
 
 func (sd SmthHandler) ForumDetails(c echo.Context) error {
@@ -325,12 +584,12 @@ func (sd SmthHandler) ForumDetails(c echo.Context) error {
 
 	slug := c.Param("slug")
 
-	forum, status := sd.UseCase.GetForum(slug)
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find forum with slug " + slug)
+	forum, err := sd.UseCase.GetForum(c.Request().Context(), slug)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, forum)
+	return c.JSON(http.StatusOK, forum)
 }
 
 func (sd SmthHandler) CreateForum(c echo.Context) error {
@@ -339,15 +598,21 @@ func (sd SmthHandler) CreateForum(c echo.Context) error {
 	newForum := &models.Forum{}
 
 	if err := easyjson.UnmarshalFromReader(c.Request().Body, newForum); err != nil {
-		return echo.NewHTTPError(http.StatusTeapot, err.Error())
+		return invalid("Malformed forum", err)
+	}
+	if err := check(newForum, false); err != nil {
+		return err
 	}
 
-	forum, status := sd.UseCase.CreateNewForum(newForum)
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find user with name " + newForum.Owner)
+	forum, err := sd.UseCase.CreateNewForum(c.Request().Context(), newForum)
+	if errors.Is(err, domain.ErrForumExists) {
+		return c.JSON(http.StatusConflict, forum)
+	}
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, forum)
+	return c.JSON(http.StatusCreated, forum)
 }
 
 func (sd SmthHandler) CreateThread(c echo.Context) error {
@@ -356,17 +621,23 @@ func (sd SmthHandler) CreateThread(c echo.Context) error {
 	newThread := &models.Thread{}
 
 	if err := easyjson.UnmarshalFromReader(c.Request().Body, newThread); err != nil {
-		return echo.NewHTTPError(http.StatusTeapot, err.Error())
+		return invalid("Malformed thread", err)
 	}
 
 	newThread.Forum = c.Param("slug")
+	if err := check(newThread, false); err != nil {
+		return err
+	}
 
-	thread, status := sd.UseCase.CreateNewThread(newThread)
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find user with name " + newThread.Author)
+	thread, err := sd.UseCase.CreateNewThread(c.Request().Context(), newThread)
+	if errors.Is(err, domain.ErrThreadExists) {
+		return c.JSON(http.StatusConflict, thread)
+	}
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, thread)
+	return c.JSON(http.StatusCreated, thread)
 }
 
 func (sd SmthHandler) CreatePosts(c echo.Context) error {
@@ -375,24 +646,28 @@ func (sd SmthHandler) CreatePosts(c echo.Context) error {
 	var posts []*models.Post
 	err := json.NewDecoder(c.Request().Body).Decode(&posts)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusTeapot, err.Error())
+		return invalid("Malformed posts", err)
+	}
+	var broken models.ValidationError
+	for i, post := range posts {
+		err := models.Validate(post)
+		var ve models.ValidationError
+		if errors.As(err, &ve) {
+			broken = append(broken, ve.Prefix(fmt.Sprintf("posts[%d]", i))...)
+		} else if err != nil {
+			return domain.Internal(err)
+		}
+	}
+	if len(broken) != 0 {
+		return rejected(broken)
 	}
-
-	/*newPosts := &models.Posts{}
-
-	if err := easyjson.UnmarshalFromReader(c.Request().Body, newPosts); err != nil {
-		return echo.NewHTTPError(http.StatusTeapot, err.Error())
-	}*/
 
 	slugOrId := c.Param("slug_or_id")
 
-	status := sd.UseCase.CreateNewPosts(posts, slugOrId)
-	if status == constants.NotFound {
-		return echo.NewHTTPError(http.StatusNotFound, "Can't find user with name ")
-	}
-	if status == http.StatusConflict {
-		return echo.NewHTTPError(http.StatusConflict, "Can't find user with name ")
+	err = sd.UseCase.CreateNewPosts(c.Request().Context(), posts, slugOrId)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(status, posts)
-}
\ No newline at end of file
+	return c.JSON(http.StatusCreated, posts)
+}
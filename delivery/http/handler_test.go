@@ -0,0 +1,547 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"subd/config"
+	"subd/live"
+	"subd/repository/memory"
+	"subd/usecase"
+
+	"github.com/labstack/echo"
+)
+
+// adminToken is the admin token of the test servers.
+const adminToken = "admin-token"
+
+// testServer serves the API over the in-memory storage.
+func testServer(t *testing.T, cfg *config.Config) *httptest.Server {
+	t.Helper()
+	repo := memory.NewMemoryDatabase()
+	cfg.AdminToken = adminToken
+
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler
+	uc := usecase.NewSmth(repo, usecase.Options{
+		RequireAuth: cfg.Auth.Required,
+		SessionTTL:  cfg.Auth.SessionTTL,
+	})
+	CreateSmthHandler(e, uc, live.NewHub(repo, time.Hour), cfg)
+
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return server
+}
+
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// decode unmarshals the body into v, failing the test if it can't.
+func (r response) decode(t *testing.T, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(r.body, v); err != nil {
+		t.Fatalf("decoding %s: %v", r.body, err)
+	}
+}
+
+// do sends a request, with body marshalled to JSON unless it is a string
+// and token as the bearer token unless it is empty or adminToken.
+func do(t *testing.T, server *httptest.Server, method string, path string, token string, body interface{}) response {
+	t.Helper()
+
+	var payload []byte
+	switch body := body.(type) {
+	case nil:
+	case string:
+		payload = []byte(body)
+	default:
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, server.URL+path, bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch token {
+	case "":
+	case adminToken:
+		req.Header.Set("X-Admin-Token", token)
+	default:
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return response{status: resp.StatusCode, header: resp.Header, body: data}
+}
+
+// expect sends a request and fails the test unless it is answered with
+// status.
+func expect(t *testing.T, server *httptest.Server, status int, method string, path string, token string, body interface{}) response {
+	t.Helper()
+	resp := do(t, server, method, path, token, body)
+	if resp.status != status {
+		t.Fatalf("%s %s = %d %s, want %d", method, path, resp.status, resp.body, status)
+	}
+	return resp
+}
+
+// signUp creates a user with a password and returns a token of theirs.
+func signUp(t *testing.T, server *httptest.Server, nickname string) string {
+	t.Helper()
+	expect(t, server, http.StatusCreated, http.MethodPost, "/api/user/"+nickname+"/create", "", map[string]string{
+		"fullname": nickname,
+		"email":    nickname + "@example.com",
+		"password": "password-of-" + nickname,
+	})
+	resp := expect(t, server, http.StatusOK, http.MethodPost, "/api/auth/login", "", map[string]string{
+		"nickname": nickname,
+		"password": "password-of-" + nickname,
+	})
+	var session struct {
+		Token string `json:"token"`
+	}
+	resp.decode(t, &session)
+	return session.Token
+}
+
+// forumFixture signs up alice and bob and makes a forum of alice's holding
+// a thread.
+func forumFixture(t *testing.T, server *httptest.Server) (alice string, bob string) {
+	t.Helper()
+	alice = signUp(t, server, "alice")
+	bob = signUp(t, server, "bob")
+	expect(t, server, http.StatusCreated, http.MethodPost, "/api/forum/create", alice, map[string]string{
+		"title": "Forum", "user": "alice", "slug": "forum",
+	})
+	expect(t, server, http.StatusCreated, http.MethodPost, "/api/forum/forum/create", alice, map[string]string{
+		"author": "alice", "title": "Thread", "message": "first", "slug": "thread",
+	})
+	return alice, bob
+}
+
+func TestErrorBody(t *testing.T) {
+	server := testServer(t, config.Default())
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+		code   string
+	}{
+		{"unknown forum", http.MethodGet, "/api/forum/nowhere/details", nil, http.StatusNotFound, "forum_not_found"},
+		{"unknown user", http.MethodGet, "/api/user/nobody/profile", nil, http.StatusNotFound, "user_not_found"},
+		{"malformed user", http.MethodPost, "/api/user/carol/create", "{", http.StatusBadRequest, "invalid_request"},
+		{"invalid user", http.MethodPost, "/api/user/carol/create", map[string]string{"fullname": "Carol"},
+			http.StatusUnprocessableEntity, "validation_failed"},
+		{"user without a password", http.MethodPost, "/api/user/carol/create",
+			map[string]string{"fullname": "Carol", "email": "carol@example.com"},
+			http.StatusUnprocessableEntity, "validation_failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := expect(t, server, tt.status, tt.method, tt.path, "", tt.body)
+			var body ErrorBody
+			resp.decode(t, &body)
+			if body.Code != tt.code {
+				t.Errorf("code = %q, want %q", body.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestCreatePosts(t *testing.T) {
+	server := testServer(t, config.Default())
+	alice, bob := forumFixture(t, server)
+
+	resp := expect(t, server, http.StatusCreated, http.MethodPost, "/api/thread/thread/create", alice,
+		[]map[string]interface{}{{"author": "alice", "message": "root"}})
+	var posts []struct {
+		Id     int    `json:"id"`
+		Thread int    `json:"thread"`
+		Forum  string `json:"forum"`
+	}
+	resp.decode(t, &posts)
+	if len(posts) != 1 || posts[0].Forum != "forum" || posts[0].Thread == 0 {
+		t.Fatalf("created %s", resp.body)
+	}
+	root := posts[0].Id
+
+	tests := []struct {
+		name   string
+		token  string
+		body   interface{}
+		status int
+	}{
+		{"reply", bob, []map[string]interface{}{{"author": "bob", "message": "reply", "parent": root}}, http.StatusCreated},
+		{"missing parent", bob, []map[string]interface{}{{"author": "bob", "message": "orphan", "parent": root + 1000}},
+			http.StatusConflict},
+		{"missing author", adminToken, []map[string]interface{}{{"author": "nobody", "message": "ghost"}}, http.StatusNotFound},
+		{"malformed", alice, "[{", http.StatusBadRequest},
+		{"empty message", alice, []map[string]interface{}{{"author": "alice", "message": ""}},
+			http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expect(t, server, tt.status, http.MethodPost, "/api/thread/thread/create", tt.token, tt.body)
+		})
+	}
+
+	var thread struct {
+		Posts int `json:"posts"`
+	}
+	expect(t, server, http.StatusOK, http.MethodGet, "/api/thread/thread/details", "", nil).decode(t, &thread)
+	if thread.Posts != 2 {
+		t.Errorf("thread counts %d posts, want 2", thread.Posts)
+	}
+}
+
+func TestVote(t *testing.T) {
+	server := testServer(t, config.Default())
+	alice, bob := forumFixture(t, server)
+
+	tests := []struct {
+		token string
+		voter string
+		voice int
+		votes int
+	}{
+		{alice, "alice", 1, 1},
+		{bob, "bob", 1, 2},
+		{alice, "alice", 1, 2},
+		{alice, "alice", -1, 0},
+	}
+	for _, tt := range tests {
+		resp := expect(t, server, http.StatusOK, http.MethodPost, "/api/thread/thread/vote", tt.token,
+			map[string]interface{}{"nickname": tt.voter, "voice": tt.voice})
+		var thread struct {
+			Votes int `json:"votes"`
+		}
+		resp.decode(t, &thread)
+		if thread.Votes != tt.votes {
+			t.Errorf("%s voting %d: votes = %d, want %d", tt.voter, tt.voice, thread.Votes, tt.votes)
+		}
+	}
+
+	expect(t, server, http.StatusUnprocessableEntity, http.MethodPost, "/api/thread/thread/vote", alice,
+		map[string]interface{}{"nickname": "alice", "voice": 2})
+	expect(t, server, http.StatusNotFound, http.MethodPost, "/api/thread/nowhere/vote", alice,
+		map[string]interface{}{"nickname": "alice", "voice": 1})
+}
+
+func TestThreadPostPages(t *testing.T) {
+	server := testServer(t, config.Default())
+	alice, _ := forumFixture(t, server)
+
+	for i := 0; i < 5; i++ {
+		expect(t, server, http.StatusCreated, http.MethodPost, "/api/thread/thread/create", alice,
+			[]map[string]interface{}{{"author": "alice", "message": "post"}})
+	}
+
+	var messages []int
+	path := "/api/thread/thread/posts?limit=2"
+	for pages := 0; path != ""; pages++ {
+		if pages == 5 {
+			t.Fatal("the pages never end")
+		}
+		resp := expect(t, server, http.StatusOK, http.MethodGet, path, "", nil)
+		var posts []struct {
+			Id int `json:"id"`
+		}
+		resp.decode(t, &posts)
+		for _, post := range posts {
+			messages = append(messages, post.Id)
+		}
+
+		path = ""
+		if next := resp.header.Get("Link"); next != "" {
+			path = strings.TrimSuffix(strings.TrimPrefix(next, "<"), `>; rel="next"`)
+		}
+	}
+	if len(messages) != 5 {
+		t.Fatalf("paged through %v, want 5 posts", messages)
+	}
+	for i := 1; i < len(messages); i++ {
+		if messages[i] <= messages[i-1] {
+			t.Errorf("pages out of order: %v", messages)
+		}
+	}
+}
+
+func TestCallerIdentity(t *testing.T) {
+	server := testServer(t, config.Default())
+	alice, _ := forumFixture(t, server)
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{"anonymous post", "", http.MethodPost, "/api/thread/thread/create",
+			[]map[string]interface{}{{"author": "alice", "message": "m"}}, http.StatusUnauthorized},
+		{"anonymous vote", "", http.MethodPost, "/api/thread/thread/vote",
+			map[string]interface{}{"nickname": "alice", "voice": 1}, http.StatusUnauthorized},
+		{"anonymous profile", "", http.MethodPost, "/api/user/alice/profile",
+			map[string]string{"fullname": "Mallory"}, http.StatusUnauthorized},
+		{"anonymous thread", "", http.MethodPost, "/api/forum/forum/create",
+			map[string]string{"author": "alice", "title": "t", "message": "m"}, http.StatusUnauthorized},
+		{"post as someone else", alice, http.MethodPost, "/api/thread/thread/create",
+			[]map[string]interface{}{{"author": "bob", "message": "m"}}, http.StatusForbidden},
+		{"vote as someone else", alice, http.MethodPost, "/api/thread/thread/vote",
+			map[string]interface{}{"nickname": "bob", "voice": 1}, http.StatusForbidden},
+		{"profile of someone else", alice, http.MethodPost, "/api/user/bob/profile",
+			map[string]string{"fullname": "Mallory"}, http.StatusForbidden},
+		{"anonymous read", "", http.MethodGet, "/api/thread/thread/posts", nil, http.StatusOK},
+		{"own profile", alice, http.MethodPost, "/api/user/alice/profile",
+			map[string]string{"fullname": "Alice"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expect(t, server, tt.status, tt.method, tt.path, tt.token, tt.body)
+		})
+	}
+
+	var bob struct {
+		Fullname string `json:"fullname"`
+	}
+	expect(t, server, http.StatusOK, http.MethodGet, "/api/user/bob/profile", "", nil).decode(t, &bob)
+	if bob.Fullname != "bob" {
+		t.Errorf("bob's profile was rewritten to %q", bob.Fullname)
+	}
+}
+
+func TestAnonymousChangesWhenAuthIsOff(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.Required = false
+	server := testServer(t, cfg)
+
+	expect(t, server, http.StatusCreated, http.MethodPost, "/api/user/alice/create", "",
+		map[string]string{"fullname": "Alice", "email": "alice@example.com"})
+	expect(t, server, http.StatusCreated, http.MethodPost, "/api/forum/create", "",
+		map[string]string{"title": "Forum", "user": "alice", "slug": "forum"})
+	expect(t, server, http.StatusCreated, http.MethodPost, "/api/forum/forum/create", "",
+		map[string]string{"author": "alice", "title": "Thread", "message": "first", "slug": "thread"})
+	expect(t, server, http.StatusCreated, http.MethodPost, "/api/thread/thread/create", "",
+		[]map[string]interface{}{{"author": "alice", "message": "m"}})
+}
+
+func TestDestructiveChangesNeedACaller(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.Required = false
+	server := testServer(t, cfg)
+	alice, _ := forumFixture(t, server)
+
+	resp := expect(t, server, http.StatusCreated, http.MethodPost, "/api/thread/thread/create", alice,
+		[]map[string]interface{}{{"author": "alice", "message": "root"}})
+	var posts []struct {
+		Id int `json:"id"`
+	}
+	resp.decode(t, &posts)
+	post := "/api/post/" + strconv.Itoa(posts[0].Id)
+
+	// A banned account can't get round its ban by leaving the token out.
+	expect(t, server, http.StatusNoContent, http.MethodPost, "/api/user/alice/role", adminToken,
+		map[string]string{"role": "banned"})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+	}{
+		{"edit a post", http.MethodPost, post + "/details", map[string]string{"message": "edited"}},
+		{"delete a post", http.MethodDelete, post, nil},
+		{"update a thread", http.MethodPost, "/api/thread/thread/details", map[string]string{"title": "edited"}},
+		{"archive a thread", http.MethodPost, "/api/thread/thread/archive", nil},
+		{"delete a thread", http.MethodDelete, "/api/thread/thread", nil},
+		{"clear the service", http.MethodPost, "/api/service/clear", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expect(t, server, http.StatusUnauthorized, tt.method, tt.path, "", tt.body)
+			expect(t, server, http.StatusForbidden, tt.method, tt.path, alice, tt.body)
+		})
+	}
+
+	expect(t, server, http.StatusOK, http.MethodGet, post+"/details", "", nil)
+	expect(t, server, http.StatusOK, http.MethodGet, "/api/thread/thread/details", "", nil)
+}
+
+func TestCursorOrder(t *testing.T) {
+	server := testServer(t, config.Default())
+	alice, _ := forumFixture(t, server)
+	expect(t, server, http.StatusCreated, http.MethodPost, "/api/forum/forum/create", alice, map[string]string{
+		"author": "alice", "title": "Other", "message": "second", "slug": "other",
+	})
+	for i := 0; i < 3; i++ {
+		expect(t, server, http.StatusCreated, http.MethodPost, "/api/thread/thread/create", alice,
+			[]map[string]interface{}{{"author": "alice", "message": "post"}})
+	}
+
+	// nextCursor returns the cursor of the page following path.
+	nextCursor := func(path string) string {
+		t.Helper()
+		resp := expect(t, server, http.StatusOK, http.MethodGet, path, "", nil)
+		next := strings.TrimSuffix(strings.TrimPrefix(resp.header.Get("Link"), "<"), `>; rel="next"`)
+		u, err := url.Parse(next)
+		if err != nil || u.Query().Get("cursor") == "" {
+			t.Fatalf("%s has no next page: %q", path, next)
+		}
+		return url.QueryEscape(u.Query().Get("cursor"))
+	}
+
+	posts := nextCursor("/api/thread/thread/posts?limit=1")
+	threads := nextCursor("/api/forum/forum/threads?limit=1")
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"posts in the same order", "/api/thread/thread/posts?limit=1&cursor=" + posts, http.StatusOK},
+		{"posts in the other direction", "/api/thread/thread/posts?limit=1&desc=true&cursor=" + posts, http.StatusBadRequest},
+		{"posts in another sort", "/api/thread/thread/posts?limit=1&sort=tree&cursor=" + posts, http.StatusBadRequest},
+		{"threads in the same order", "/api/forum/forum/threads?limit=1&cursor=" + threads, http.StatusOK},
+		{"threads in the other direction", "/api/forum/forum/threads?limit=1&desc=true&cursor=" + threads, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expect(t, server, tt.status, http.MethodGet, tt.path, "", nil)
+		})
+	}
+}
+
+func TestDeletedPostRevisions(t *testing.T) {
+	server := testServer(t, config.Default())
+	alice, bob := forumFixture(t, server)
+
+	resp := expect(t, server, http.StatusCreated, http.MethodPost, "/api/thread/thread/create", alice,
+		[]map[string]interface{}{{"author": "alice", "message": "root"}})
+	var posts []struct {
+		Id int `json:"id"`
+	}
+	resp.decode(t, &posts)
+	post := "/api/post/" + strconv.Itoa(posts[0].Id)
+	expect(t, server, http.StatusOK, http.MethodPost, post+"/details", alice, map[string]string{"message": "edited"})
+	expect(t, server, http.StatusNoContent, http.MethodDelete, post, alice, nil)
+
+	// The history of a deleted post is left to those who may change it.
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"anonymous", "", http.StatusConflict},
+		{"someone else", bob, http.StatusConflict},
+		{"the author", alice, http.StatusOK},
+		{"an admin", adminToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expect(t, server, tt.status, http.MethodGet, post+"/revisions", tt.token, nil)
+			expect(t, server, tt.status, http.MethodGet, post+"/revisions/diff?from=1", tt.token, nil)
+		})
+	}
+}
+
+func TestUnreadPosts(t *testing.T) {
+	server := testServer(t, config.Default())
+	alice, bob := forumFixture(t, server)
+	var ids []int
+	for i := 0; i < 5; i++ {
+		resp := expect(t, server, http.StatusCreated, http.MethodPost, "/api/thread/thread/create", bob,
+			[]map[string]interface{}{{"author": "bob", "message": "post " + strconv.Itoa(i)}})
+		var posts []struct {
+			Id int `json:"id"`
+		}
+		resp.decode(t, &posts)
+		ids = append(ids, posts[0].Id)
+	}
+	expect(t, server, http.StatusOK, http.MethodPost, "/api/thread/thread/read", alice,
+		map[string]interface{}{"nickname": "alice", "post": ids[1]})
+
+	// unread follows the pages of path and returns the posts on each.
+	unread := func(token string, path string) [][]int {
+		t.Helper()
+		var pages [][]int
+		for path != "" {
+			if len(pages) == 5 {
+				t.Fatalf("the pages of %s never end", path)
+			}
+			resp := expect(t, server, http.StatusOK, http.MethodGet, path, token, nil)
+			var posts []struct {
+				Id int `json:"id"`
+			}
+			resp.decode(t, &posts)
+			var page []int
+			for _, post := range posts {
+				page = append(page, post.Id)
+			}
+			pages = append(pages, page)
+			path = nextPage(resp)
+		}
+		return pages
+	}
+
+	const alicePath = "/api/thread/thread/posts?unread_only=true&nickname=alice"
+	tests := []struct {
+		name  string
+		token string
+		path  string
+		want  [][]int
+	}{
+		{"oldest first", alice, alicePath + "&limit=10", [][]int{{ids[2], ids[3], ids[4]}}},
+		{"oldest first in pages", alice, alicePath + "&limit=2", [][]int{{ids[2], ids[3]}, {ids[4]}}},
+		{"newest first", alice, alicePath + "&desc=true&limit=10", [][]int{{ids[4], ids[3], ids[2]}}},
+		// The read post ends the listing as soon as a page reaches it.
+		{"newest first in pages", alice, alicePath + "&desc=true&limit=2", [][]int{{ids[4], ids[3]}, {ids[2]}}},
+		{"newest first up to the read post", alice, alicePath + "&desc=true&limit=3",
+			[][]int{{ids[4], ids[3], ids[2]}}},
+		{"nothing read", bob, "/api/thread/thread/posts?unread_only=true&nickname=bob&desc=true&limit=10",
+			[][]int{{ids[4], ids[3], ids[2], ids[1], ids[0]}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unread(tt.token, tt.path)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("pages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	expect(t, server, http.StatusOK, http.MethodPost, "/api/thread/thread/read", alice,
+		map[string]interface{}{"nickname": "alice"})
+	if got := unread(alice, alicePath+"&desc=true&limit=2"); fmt.Sprint(got) != "[[]]" {
+		t.Errorf("pages after reading everything = %v, want one empty page", got)
+	}
+	expect(t, server, http.StatusForbidden, http.MethodGet, alicePath, bob, nil)
+	expect(t, server, http.StatusBadRequest, http.MethodGet, alicePath+"&sort=tree", alice, nil)
+}
@@ -0,0 +1,213 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"subd/models"
+
+	"github.com/labstack/echo"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// heartbeat is how often an idle stream sends something, so that
+	// proxies keep the connection open.
+	heartbeat = 30 * time.Second
+	// replayBatch is how many missed events a stream reads at once.
+	replayBatch = 100
+)
+
+// streams are the routes that hold the connection open; the request
+// timeout does not apply to them.
+var streams = map[string]bool{
+	"/api/forum/:slug/events":           true,
+	"/api/forum/:slug/events/ws":        true,
+	"/api/thread/:slug_or_id/events":    true,
+	"/api/thread/:slug_or_id/events/ws": true,
+}
+
+// Streaming reports whether c is on a streaming route.
+func Streaming(c echo.Context) bool {
+	return streams[c.Path()]
+}
+
+// lastEventId reads where a client resumes: the Last-Event-ID header an
+// EventSource sends when it reconnects, or the last_event_id parameter.
+func lastEventId(c echo.Context) (int64, bool, error) {
+	value := c.Request().Header.Get("Last-Event-ID")
+	if value == "" {
+		value = c.QueryParam("last_event_id")
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, invalid("Malformed last event id", fmt.Errorf("%q is not an event id", value))
+	}
+
+	return id, true, nil
+}
+
+// follow subscribes to the events of query, replays those after
+// query.After when resuming and then sends the new ones as they come. It
+// returns once ctx is done, sending fails or the hub drops the
+// subscription; the client then resumes from the last event it got.
+func (sd SmthHandler) follow(ctx context.Context, query models.EventQuery, resume bool,
+	send func(models.Event) error, idle func() error) error {
+	sub := sd.Hub.Subscribe(query)
+	defer sd.Hub.Unsubscribe(sub)
+
+	last := query.After
+	for resume {
+		replay := query
+		replay.After, replay.Limit = last, replayBatch
+		events, err := sd.UseCase.Events(ctx, replay)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := send(event); err != nil {
+				return nil
+			}
+			last = event.Id
+		}
+		resume = len(events) == replayBatch
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.Events:
+			if !ok {
+				return nil
+			}
+			// Already replayed.
+			if event.Id <= last {
+				continue
+			}
+			if err := send(event); err != nil {
+				return nil
+			}
+			last = event.Id
+		case <-ticker.C:
+			if err := idle(); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// sse streams the events of query as Server-Sent Events.
+func (sd SmthHandler) sse(c echo.Context, query models.EventQuery) error {
+	after, resume, err := lastEventId(c)
+	if err != nil {
+		return err
+	}
+	query.After = after
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	send := func(event models.Event) error {
+		data, err := event.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+		res.Flush()
+		return err
+	}
+	idle := func() error {
+		_, err := io.WriteString(res, ": keep-alive\n\n")
+		res.Flush()
+		return err
+	}
+
+	if err := sd.follow(c.Request().Context(), query, resume, send, idle); err != nil {
+		c.Logger().Error(err)
+	}
+	return nil
+}
+
+// ws streams the events of query over a WebSocket, one JSON text message
+// per event. The events are public, so any origin may connect.
+func (sd SmthHandler) ws(c echo.Context, query models.EventQuery) error {
+	after, resume, err := lastEventId(c)
+	if err != nil {
+		return err
+	}
+	query.After = after
+
+	websocket.Server{Handler: func(conn *websocket.Conn) {
+		// The request context outlives a hijacked connection; reading is
+		// how the stream notices the client going away.
+		ctx, cancel := context.WithCancel(conn.Request().Context())
+		defer cancel()
+		go func() {
+			io.Copy(ioutil.Discard, conn)
+			cancel()
+		}()
+
+		send := func(event models.Event) error {
+			return websocket.JSON.Send(conn, event)
+		}
+		idle := func() error {
+			conn.PayloadType = websocket.PingFrame
+			defer func() { conn.PayloadType = websocket.TextFrame }()
+			_, err := conn.Write(nil)
+			return err
+		}
+
+		if err := sd.follow(ctx, query, resume, send, idle); err != nil {
+			c.Logger().Error(err)
+		}
+	}}.ServeHTTP(c.Response(), c.Request())
+
+	return nil
+}
+
+func (sd SmthHandler) ThreadEvents(c echo.Context) error {
+	query, err := sd.UseCase.ThreadEvents(c.Request().Context(), c.Param("slug_or_id"))
+	if err != nil {
+		return err
+	}
+	return sd.sse(c, query)
+}
+
+func (sd SmthHandler) ThreadEventsWS(c echo.Context) error {
+	query, err := sd.UseCase.ThreadEvents(c.Request().Context(), c.Param("slug_or_id"))
+	if err != nil {
+		return err
+	}
+	return sd.ws(c, query)
+}
+
+func (sd SmthHandler) ForumEvents(c echo.Context) error {
+	query, err := sd.UseCase.ForumEvents(c.Request().Context(), c.Param("slug"))
+	if err != nil {
+		return err
+	}
+	return sd.sse(c, query)
+}
+
+func (sd SmthHandler) ForumEventsWS(c echo.Context) error {
+	query, err := sd.UseCase.ForumEvents(c.Request().Context(), c.Param("slug"))
+	if err != nil {
+		return err
+	}
+	return sd.ws(c, query)
+}
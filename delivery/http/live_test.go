@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	smth "subd"
+	"subd/live"
+	"subd/models"
+	"subd/repository/memory"
+	"subd/usecase"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/labstack/echo"
+)
+
+func TestLastEventId(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		query  string
+		id     int64
+		resume bool
+		ok     bool
+	}{
+		{"fresh", "", "", 0, false, true},
+		{"header", "42", "", 42, true, true},
+		{"parameter", "", "?last_event_id=7", 7, true, true},
+		{"header first", "42", "?last_event_id=7", 42, true, true},
+		{"from the start", "0", "", 0, true, true},
+		{"negative", "-1", "", 0, false, false},
+		{"malformed", "", "?last_event_id=last", 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/thread/1/events"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set("Last-Event-ID", tt.header)
+			}
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			id, resume, err := lastEventId(c)
+			if (err == nil) != tt.ok {
+				t.Fatalf("lastEventId = %v, want ok %v", err, tt.ok)
+			}
+			if id != tt.id || resume != tt.resume {
+				t.Errorf("lastEventId = %d, %v, want %d, %v", id, resume, tt.id, tt.resume)
+			}
+		})
+	}
+}
+
+// racingUseCase adds a post to the thread before the first replay reads
+// the log, as if it were made while the stream was starting.
+type racingUseCase struct {
+	smth.UseCase
+	race func()
+}
+
+func (uc *racingUseCase) Events(ctx context.Context, query models.EventQuery) (models.Events, error) {
+	if uc.race != nil {
+		uc.race()
+		uc.race = nil
+	}
+	return uc.UseCase.Events(ctx, query)
+}
+
+func TestFollowReplaysOnce(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	repo := memory.NewMemoryDatabase()
+	user := models.User{Nickname: "alice", Fullname: "Alice", Email: strfmt.Email("alice@example.com")}
+	if err := repo.CreateUser(ctx, "alice", user); err != nil {
+		t.Fatal(err)
+	}
+	if err, _ := repo.AddNewForum(ctx, &models.Forum{Title: "Forum", Owner: "alice", Slug: "forum"}); err != nil {
+		t.Fatal(err)
+	}
+	id, err := repo.AddNewThread(ctx, models.Thread{Author: "alice", Forum: "forum", Title: "Thread", Message: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	thread, _ := repo.GetThreadById(ctx, int(id))
+	post := func() {
+		if status := repo.AddPost(ctx, []*models.Post{{Author: "alice", Message: "post"}}, thread, time.Now()); status != http.StatusCreated {
+			t.Errorf("AddPost = %d", status)
+		}
+	}
+
+	hub := live.NewHub(repo, time.Hour)
+	go hub.Run(ctx)
+	query := models.EventQuery{Thread: int(id)}
+	// Events added before the hub reads the log are not passed on.
+	probe := hub.Subscribe(query)
+	for passed := false; !passed; {
+		post()
+		select {
+		case <-probe.Events:
+			passed = true
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatal("the hub passes no event")
+		}
+	}
+	hub.Unsubscribe(probe)
+	base, err := repo.LastEventId(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The client saw 10 events of the next 120 and resumes from there.
+	// Replaying the rest takes two batches; an event added meanwhile is
+	// both replayed and passed by the hub, and sent once.
+	for i := 0; i < 120; i++ {
+		post()
+	}
+	uc := &racingUseCase{UseCase: usecase.NewSmth(repo, usecase.Options{}), race: post}
+	sd := SmthHandler{UseCase: uc, Hub: hub}
+
+	var sent []int64
+	send := func(event models.Event) error {
+		sent = append(sent, event.Id)
+		switch event.Id - base {
+		case 121:
+			post()
+		case 122:
+			cancel()
+		}
+		return nil
+	}
+	query.After = base + 10
+	if err := sd.follow(ctx, query, true, send, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sent) != 112 {
+		t.Fatalf("sent %d events, want 112", len(sent))
+	}
+	for i, id := range sent {
+		if want := base + int64(i+11); id != want {
+			t.Fatalf("event %d sent is %d, want %d", i, id, want)
+		}
+	}
+}
+
+func TestFollowEndsWithTheHub(t *testing.T) {
+	repo := memory.NewMemoryDatabase()
+	hub := live.NewHub(repo, time.Hour)
+	sd := SmthHandler{UseCase: usecase.NewSmth(repo, usecase.Options{}), Hub: hub}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	hub.Run(ctx)
+
+	sent := 0
+	err := sd.follow(context.Background(), models.EventQuery{}, false,
+		func(models.Event) error { sent++; return nil }, func() error { return nil })
+	if err != nil || sent != 0 {
+		t.Errorf("follow on a stopped hub = %v after %d events", err, sent)
+	}
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"subd/models"
+
+	"github.com/labstack/echo"
+	"github.com/mailru/easyjson"
+)
+
+// conversationId reads the id of the conversation a request is about.
+func conversationId(c echo.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, invalid("Conversation id must be a number", err)
+	}
+	return id, nil
+}
+
+// privateMessage reads a message body, which follows the rules of a post.
+func privateMessage(c echo.Context) (models.PrivateMessage, error) {
+	message := &models.PrivateMessage{}
+	if err := easyjson.UnmarshalFromReader(c.Request().Body, message); err != nil {
+		return models.PrivateMessage{}, invalid("Malformed message", err)
+	}
+	if err := check(message, false); err != nil {
+		return models.PrivateMessage{}, err
+	}
+	return *message, nil
+}
+
+func (sd SmthHandler) CreateConversation(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	nc := &models.NewConversation{}
+	if err := easyjson.UnmarshalFromReader(c.Request().Body, nc); err != nil {
+		return invalid("Malformed conversation", err)
+	}
+	if err := check(nc, false); err != nil {
+		return err
+	}
+
+	conversation, err := sd.UseCase.CreateConversation(c.Request().Context(), c.Param("nickname"), *nc)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, conversation)
+}
+
+func (sd SmthHandler) GetConversations(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	page, err := sd.UseCase.Conversations(c.Request().Context(), c.Param("nickname"), sd.limit(c), c.QueryParam("cursor"))
+	if err != nil {
+		return err
+	}
+
+	link(c, page.Next)
+	return c.JSON(http.StatusOK, page.Conversations)
+}
+
+func (sd SmthHandler) GetConversation(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := conversationId(c)
+	if err != nil {
+		return err
+	}
+
+	conversation, err := sd.UseCase.Conversation(c.Request().Context(), c.Param("nickname"), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, conversation)
+}
+
+func (sd SmthHandler) SendMessage(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := conversationId(c)
+	if err != nil {
+		return err
+	}
+	message, err := privateMessage(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := sd.UseCase.SendMessage(c.Request().Context(), c.Param("nickname"), id, message)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+// GetMessages takes the limit, since, desc and cursor parameters of the
+// thread post listing.
+func (sd SmthHandler) GetMessages(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := conversationId(c)
+	if err != nil {
+		return err
+	}
+	since, _ := strconv.ParseInt(c.QueryParam("since"), 10, 64)
+	desc, _ := strconv.ParseBool(c.QueryParam("desc"))
+
+	page, err := sd.UseCase.Messages(c.Request().Context(), c.Param("nickname"), id, sd.limit(c), since, desc,
+		c.QueryParam("cursor"))
+	if err != nil {
+		return err
+	}
+
+	link(c, page.Next)
+	return c.JSON(http.StatusOK, page.Messages)
+}
+
+func (sd SmthHandler) EditPrivateMessage(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := conversationId(c)
+	if err != nil {
+		return err
+	}
+	messageId, err := strconv.ParseInt(c.Param("message"), 10, 64)
+	if err != nil {
+		return invalid("Message id must be a number", err)
+	}
+	message, err := privateMessage(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := sd.UseCase.EditPrivateMessage(c.Request().Context(), c.Param("nickname"), id, messageId, message.Message)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// MarkConversationRead moves the read marker of a participant to the
+// up_to message, or to the latest one.
+func (sd SmthHandler) MarkConversationRead(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := conversationId(c)
+	if err != nil {
+		return err
+	}
+	var upTo int64
+	if value := c.QueryParam("up_to"); value != "" {
+		upTo, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || upTo < 1 {
+			return invalid("Malformed up_to", fmt.Errorf("%q is not a message id", value))
+		}
+	}
+
+	read, err := sd.UseCase.MarkConversationRead(c.Request().Context(), c.Param("nickname"), id, upTo)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, read)
+}
+
+func (sd SmthHandler) GetBlocks(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	blocks, err := sd.UseCase.Blocks(c.Request().Context(), c.Param("nickname"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, blocks)
+}
+
+func (sd SmthHandler) Block(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	err := sd.UseCase.Block(c.Request().Context(), c.Param("nickname"), c.Param("blocked"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sd SmthHandler) Unblock(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	err := sd.UseCase.Unblock(c.Request().Context(), c.Param("nickname"), c.Param("blocked"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"subd/config"
+	"subd/models"
+)
+
+// startConversation starts a conversation of creator with the others and
+// returns its path under the creator, without the nickname.
+func startConversation(t *testing.T, server *httptest.Server, token string, creator string, others ...string) string {
+	t.Helper()
+	resp := expect(t, server, http.StatusCreated, http.MethodPost, "/api/user/"+creator+"/conversations", token,
+		map[string]interface{}{"participants": others, "message": "hello from " + creator})
+	var conversation models.Conversation
+	resp.decode(t, &conversation)
+	return "/conversations/" + strconv.Itoa(conversation.Id)
+}
+
+// send posts a message of nickname to a conversation and returns its id.
+func send(t *testing.T, server *httptest.Server, status int, token string, nickname string, conversation string) int64 {
+	t.Helper()
+	resp := expect(t, server, status, http.MethodPost, "/api/user/"+nickname+conversation+"/messages", token,
+		map[string]string{"message": "from " + nickname})
+	var message models.PrivateMessage
+	if status == http.StatusCreated {
+		resp.decode(t, &message)
+	}
+	return message.Id
+}
+
+func TestConversationReadMarkers(t *testing.T) {
+	server := testServer(t, config.Default())
+	alice := signUp(t, server, "alice")
+	bob := signUp(t, server, "bob")
+	carol := signUp(t, server, "carol")
+	conversation := startConversation(t, server, alice, "alice", "bob")
+	second := send(t, server, http.StatusCreated, alice, "alice", conversation)
+	send(t, server, http.StatusCreated, alice, "alice", conversation)
+
+	unread := func(nickname string, token string) int {
+		t.Helper()
+		resp := expect(t, server, http.StatusOK, http.MethodGet, "/api/user/"+nickname+conversation, token, nil)
+		var c models.Conversation
+		resp.decode(t, &c)
+		return c.Unread
+	}
+	if got := unread("alice", alice); got != 0 {
+		t.Errorf("alice has %d unread messages of her own, want 0", got)
+	}
+	if got := unread("bob", bob); got != 3 {
+		t.Errorf("bob has %d unread messages, want 3", got)
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		unread int
+	}{
+		{"up to a message", "?up_to=" + strconv.FormatInt(second, 10), 1},
+		{"up to the latest", "", 0},
+		{"never back", "?up_to=" + strconv.FormatInt(second, 10), 0},
+	}
+	var last int64
+	for _, tt := range tests {
+		resp := expect(t, server, http.StatusOK, http.MethodPost, "/api/user/bob"+conversation+"/read"+tt.query, bob, nil)
+		var read models.ConversationRead
+		resp.decode(t, &read)
+		if read.Unread != tt.unread {
+			t.Errorf("%s: %d unread, want %d", tt.name, read.Unread, tt.unread)
+		}
+		if read.LastRead < last {
+			t.Errorf("%s: the marker moved back from %d to %d", tt.name, last, read.LastRead)
+		}
+		last = read.LastRead
+	}
+	if got := unread("bob", bob); got != 0 {
+		t.Errorf("bob has %d unread messages after reading them all, want 0", got)
+	}
+
+	expect(t, server, http.StatusNotFound, http.MethodPost, "/api/user/bob"+conversation+"/read?up_to=1000", bob, nil)
+	expect(t, server, http.StatusBadRequest, http.MethodPost, "/api/user/bob"+conversation+"/read?up_to=last", bob, nil)
+	expect(t, server, http.StatusNotFound, http.MethodPost, "/api/user/carol"+conversation+"/read", carol, nil)
+	expect(t, server, http.StatusForbidden, http.MethodPost, "/api/user/bob"+conversation+"/read", alice, nil)
+}
+
+func TestConversationBlocks(t *testing.T) {
+	server := testServer(t, config.Default())
+	alice := signUp(t, server, "alice")
+	bob := signUp(t, server, "bob")
+	carol := signUp(t, server, "carol")
+	direct := startConversation(t, server, alice, "alice", "bob")
+	group := startConversation(t, server, alice, "alice", "bob", "carol")
+
+	expect(t, server, http.StatusNoContent, http.MethodPost, "/api/user/bob/blocks/alice", bob, nil)
+
+	// The block holds both ways, in every conversation the two share.
+	tests := []struct {
+		name         string
+		token        string
+		nickname     string
+		conversation string
+		status       int
+	}{
+		{"blocked one-to-one", alice, "alice", direct, http.StatusForbidden},
+		{"blocked in a group", alice, "alice", group, http.StatusForbidden},
+		{"blocker one-to-one", bob, "bob", direct, http.StatusForbidden},
+		{"blocker in a group", bob, "bob", group, http.StatusForbidden},
+		{"someone else in the group", carol, "carol", group, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			send(t, server, tt.status, tt.token, tt.nickname, tt.conversation)
+		})
+	}
+	resp := expect(t, server, http.StatusForbidden, http.MethodPost, "/api/user/alice/conversations", alice,
+		map[string]interface{}{"participants": []string{"carol", "bob"}, "message": "again"})
+	var body ErrorBody
+	resp.decode(t, &body)
+	if body.Code != "user_blocked" {
+		t.Errorf("code = %q, want user_blocked", body.Code)
+	}
+
+	// What alice wrote before the block is hidden from bob.
+	resp = expect(t, server, http.StatusOK, http.MethodGet, "/api/user/bob"+group+"/messages", bob, nil)
+	var messages models.PrivateMessages
+	resp.decode(t, &messages)
+	if len(messages) != 1 || messages[0].Author != "carol" {
+		t.Errorf("bob sees %d messages, want only carol's", len(messages))
+	}
+	resp = expect(t, server, http.StatusOK, http.MethodGet, "/api/user/carol"+group+"/messages", carol, nil)
+	resp.decode(t, &messages)
+	if len(messages) != 2 {
+		t.Errorf("carol sees %d messages, want 2", len(messages))
+	}
+
+	expect(t, server, http.StatusNoContent, http.MethodDelete, "/api/user/bob/blocks/alice", bob, nil)
+	send(t, server, http.StatusCreated, alice, "alice", group)
+	send(t, server, http.StatusCreated, alice, "alice", direct)
+	resp = expect(t, server, http.StatusOK, http.MethodGet, "/api/user/bob/blocks", bob, nil)
+	if strings.Contains(string(resp.body), "alice") {
+		t.Errorf("bob still blocks alice: %s", resp.body)
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"subd/models"
+
+	"github.com/labstack/echo"
+)
+
+func (sd SmthHandler) GetNotifications(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	unread, _ := strconv.ParseBool(c.QueryParam("unread"))
+	query := models.NotificationQuery{
+		Nickname: c.Param("nickname"),
+		Unread:   unread,
+		Limit:    sd.limit(c),
+	}
+
+	page, err := sd.UseCase.Notifications(c.Request().Context(), query, c.QueryParam("cursor"))
+	if err != nil {
+		return err
+	}
+	link(c, page.Next)
+
+	return c.JSON(http.StatusOK, page)
+}
+
+func (sd SmthHandler) GetUnreadNotifications(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	unread, err := sd.UseCase.UnreadNotifications(c.Request().Context(), c.Param("nickname"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, unread)
+}
+
+func (sd SmthHandler) MarkNotificationRead(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return invalid("Notification id must be a number", err)
+	}
+
+	err = sd.UseCase.MarkNotificationRead(c.Request().Context(), c.Param("nickname"), id)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// MarkNotificationsRead marks every notification as read, or those up to
+// the up_to id, so that the ones that came after the client looked stay
+// unread.
+func (sd SmthHandler) MarkNotificationsRead(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	var upTo int64
+	if value := c.QueryParam("up_to"); value != "" {
+		var err error
+		upTo, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || upTo < 1 {
+			return invalid("Malformed up_to", fmt.Errorf("%q is not a notification id", value))
+		}
+	}
+
+	marked, err := sd.UseCase.MarkNotificationsRead(c.Request().Context(), c.Param("nickname"), upTo)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, marked)
+}
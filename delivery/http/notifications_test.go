@@ -0,0 +1,62 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"subd/config"
+	"subd/models"
+)
+
+func TestMentionNotifications(t *testing.T) {
+	server := testServer(t, config.Default())
+	alice, bob := forumFixture(t, server)
+	carol := signUp(t, server, "carol")
+	for _, post := range []struct {
+		token   string
+		author  string
+		message string
+	}{
+		{bob, "bob", "@alice and @Carol, have a look"},
+		{alice, "alice", "note to @alice: write to carol@example.com"},
+	} {
+		expect(t, server, http.StatusCreated, http.MethodPost, "/api/thread/thread/create", post.token,
+			[]map[string]interface{}{{"author": post.author, "message": post.message}})
+	}
+
+	tests := []struct {
+		nickname string
+		token    string
+		unread   int
+	}{
+		{"alice", alice, 1},
+		{"bob", bob, 0},
+		{"carol", carol, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nickname, func(t *testing.T) {
+			resp := expect(t, server, http.StatusOK, http.MethodGet, "/api/user/"+tt.nickname+"/notifications", tt.token, nil)
+			var page models.NotificationsPage
+			resp.decode(t, &page)
+			if page.Unread != tt.unread || len(page.Notifications) != tt.unread {
+				t.Fatalf("%s has %d notifications, %d unread, want %d", tt.nickname,
+					len(page.Notifications), page.Unread, tt.unread)
+			}
+			for _, n := range page.Notifications {
+				if n.Type != models.NotifyMention || n.Actor != "bob" {
+					t.Errorf("%s is notified of a %s from %s, want a mention from bob", tt.nickname, n.Type, n.Actor)
+				}
+			}
+
+			resp = expect(t, server, http.StatusOK, http.MethodGet, "/api/user/"+tt.nickname+"/notifications/unread", tt.token, nil)
+			var counts models.UnreadNotifications
+			resp.decode(t, &counts)
+			if counts.ByType[models.NotifyMention] != tt.unread {
+				t.Errorf("%s has %d unread mentions, want %d", tt.nickname, counts.ByType[models.NotifyMention], tt.unread)
+			}
+		})
+	}
+
+	expect(t, server, http.StatusForbidden, http.MethodGet, "/api/user/carol/notifications", bob, nil)
+	expect(t, server, http.StatusUnauthorized, http.MethodGet, "/api/user/carol/notifications", "", nil)
+}
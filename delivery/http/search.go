@@ -0,0 +1,93 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"subd/models"
+
+	"github.com/labstack/echo"
+)
+
+// searchQuery reads the query and filters of a search request.
+func (sd SmthHandler) searchQuery(c echo.Context) (models.SearchQuery, error) {
+	query := models.SearchQuery{
+		Text:   c.QueryParam("q"),
+		Kind:   c.QueryParam("type"),
+		Forum:  c.QueryParam("forum"),
+		Author: c.QueryParam("author"),
+		Limit:  sd.limit(c),
+	}
+
+	switch query.Kind {
+	case "", models.HitPost, models.HitThread:
+	default:
+		return query, invalid("Malformed search", errors.New("type must be post or thread"))
+	}
+	if thread := c.QueryParam("thread"); thread != "" {
+		id, err := strconv.Atoi(thread)
+		if err != nil {
+			return query, invalid("Malformed search", err)
+		}
+		query.Thread = id
+	}
+	for param, dst := range map[string]**time.Time{"from": &query.From, "to": &query.To} {
+		if value := c.QueryParam(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return query, invalid("Malformed search", err)
+			}
+			*dst = &t
+		}
+	}
+
+	return query, check(&query, false)
+}
+
+func (sd SmthHandler) Search(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	query, err := sd.searchQuery(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := sd.UseCase.Search(c.Request().Context(), query, c.QueryParam("cursor"))
+	if err != nil {
+		return err
+	}
+	link(c, result.Next)
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (sd SmthHandler) FindUsers(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	desc, _ := strconv.ParseBool(c.QueryParam("desc"))
+	query := models.UserQuery{
+		Text:  c.QueryParam("query"),
+		Forum: c.QueryParam("forum"),
+		Sort:  c.QueryParam("sort"),
+		Desc:  desc,
+		Limit: sd.limit(c),
+	}
+	switch query.Sort {
+	case "", models.UsersByRelevance, models.UsersByNickname:
+	default:
+		return invalid("Malformed user query", errors.New("sort must be relevance or nickname"))
+	}
+	if err := check(&query, false); err != nil {
+		return err
+	}
+
+	page, err := sd.UseCase.FindUsers(c.Request().Context(), query, c.QueryParam("cursor"))
+	if err != nil {
+		return err
+	}
+	link(c, page.Next)
+
+	return c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"subd/config"
+	"subd/models"
+)
+
+// nextPage returns the path of the page following resp, empty on the last.
+func nextPage(resp response) string {
+	return strings.TrimSuffix(strings.TrimPrefix(resp.header.Get("Link"), "<"), `>; rel="next"`)
+}
+
+func TestSearchPages(t *testing.T) {
+	server := testServer(t, config.Default())
+	alice, bob := forumFixture(t, server)
+	for _, post := range []struct {
+		token   string
+		author  string
+		message string
+	}{
+		{alice, "alice", "apple pie"},
+		{bob, "bob", "apple tart"},
+		{alice, "alice", "banana"},
+		{bob, "bob", "apple"},
+	} {
+		expect(t, server, http.StatusCreated, http.MethodPost, "/api/thread/thread/create", post.token,
+			[]map[string]interface{}{{"author": post.author, "message": post.message}})
+	}
+
+	// search follows the pages of path and returns the hits of them all.
+	search := func(path string) []string {
+		t.Helper()
+		var found []string
+		for path != "" {
+			resp := expect(t, server, http.StatusOK, http.MethodGet, path, "", nil)
+			var result models.SearchResult
+			resp.decode(t, &result)
+			if len(result.Hits) > 1 {
+				t.Fatalf("%s has %d hits, want 1 a page", path, len(result.Hits))
+			}
+			for _, hit := range result.Hits {
+				found = append(found, hit.Author+": "+hit.Snippet)
+			}
+			path = nextPage(resp)
+			if len(found) > 10 {
+				t.Fatalf("%s keeps going", path)
+			}
+		}
+		return found
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"posts", "/api/search?q=apple&type=post&limit=1", 3},
+		{"by author", "/api/search?q=apple&author=bob&limit=1", 2},
+		{"threads", "/api/search?q=apple&type=thread&limit=1", 0},
+		{"in a thread", "/api/search?q=apple&thread=1&limit=1", 3},
+		{"before the posts", "/api/search?q=apple&to=2000-01-01T00:00:00Z&limit=1", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := search(tt.path)
+			if len(found) != tt.want {
+				t.Errorf("found %q, want %d hits", found, tt.want)
+			}
+			seen := make(map[string]bool)
+			for _, hit := range found {
+				if seen[hit] {
+					t.Errorf("%q is found twice", hit)
+				}
+				seen[hit] = true
+			}
+		})
+	}
+
+	for _, path := range []string{
+		"/api/search?q=apple&cursor=nonsense",
+		"/api/search?q=apple&type=user",
+		"/api/search?q=apple&thread=first",
+		"/api/search?q=apple&from=yesterday",
+	} {
+		expect(t, server, http.StatusBadRequest, http.MethodGet, path, "", nil)
+	}
+	expect(t, server, http.StatusUnprocessableEntity, http.MethodGet, "/api/search?q=", "", nil)
+}
+
+func TestUserDirectoryPages(t *testing.T) {
+	server := testServer(t, config.Default())
+	for _, nickname := range []string{"alice", "alicia", "bob", "carol"} {
+		signUp(t, server, nickname)
+	}
+
+	// find follows the pages of path and returns the nicknames found.
+	find := func(path string) string {
+		t.Helper()
+		var found []string
+		for path != "" {
+			resp := expect(t, server, http.StatusOK, http.MethodGet, path, "", nil)
+			var page models.UsersPage
+			resp.decode(t, &page)
+			for _, user := range page.Users {
+				found = append(found, user.Nickname)
+			}
+			path = nextPage(resp)
+			if len(found) > 10 {
+				t.Fatalf("%s keeps going", path)
+			}
+		}
+		return strings.Join(found, " ")
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"everyone", "/api/users?limit=1", "alice alicia bob carol"},
+		{"descending", "/api/users?desc=true&limit=1", "carol bob alicia alice"},
+		{"relevance", "/api/users?query=alice&limit=1", "alice alicia"},
+		{"prefix by nickname", "/api/users?query=ali&sort=nickname&desc=true&limit=1", "alicia alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := find(tt.path); got != tt.want {
+				t.Errorf("found %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	resp := expect(t, server, http.StatusOK, http.MethodGet, "/api/users?limit=1", "", nil)
+	var page models.UsersPage
+	resp.decode(t, &page)
+	resp = expect(t, server, http.StatusBadRequest, http.MethodGet, "/api/users?desc=true&cursor="+page.Next, "", nil)
+	var body ErrorBody
+	resp.decode(t, &body)
+	if !strings.Contains(body.Message, "other direction") {
+		t.Errorf("a cursor of the other direction is refused with %q", body.Message)
+	}
+	expect(t, server, http.StatusBadRequest, http.MethodGet, "/api/users?cursor=nonsense", "", nil)
+	expect(t, server, http.StatusBadRequest, http.MethodGet, "/api/users?sort=email", "", nil)
+	expect(t, server, http.StatusNotFound, http.MethodGet, "/api/users?forum=nowhere", "", nil)
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+
+	"subd/models"
+
+	"github.com/labstack/echo"
+	"github.com/mailru/easyjson"
+)
+
+func (sd SmthHandler) SubscribeForum(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	err := sd.UseCase.SubscribeForum(c.Request().Context(), c.Param("nickname"), c.Param("slug"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sd SmthHandler) UnsubscribeForum(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	err := sd.UseCase.UnsubscribeForum(c.Request().Context(), c.Param("nickname"), c.Param("slug"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sd SmthHandler) SubscribeThread(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	err := sd.UseCase.SubscribeThread(c.Request().Context(), c.Param("nickname"), c.Param("slug_or_id"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sd SmthHandler) UnsubscribeThread(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	err := sd.UseCase.UnsubscribeThread(c.Request().Context(), c.Param("nickname"), c.Param("slug_or_id"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sd SmthHandler) GetSubscriptions(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	subscriptions, err := sd.UseCase.Subscriptions(c.Request().Context(), c.Param("nickname"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, subscriptions)
+}
+
+// MarkThreadRead moves the read marker of a user in a thread to the given
+// post, or to the latest one when the body leaves it out.
+func (sd SmthHandler) MarkThreadRead(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	marker := &models.ReadMarker{}
+	if err := easyjson.UnmarshalFromReader(c.Request().Body, marker); err != nil {
+		return invalid("Malformed read marker", err)
+	}
+	if err := check(marker, false); err != nil {
+		return err
+	}
+
+	read, err := sd.UseCase.MarkThreadRead(c.Request().Context(), c.Param("slug_or_id"), *marker)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, read)
+}
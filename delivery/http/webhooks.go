@@ -0,0 +1,130 @@
+package http
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"subd/models"
+
+	"github.com/labstack/echo"
+	"github.com/mailru/easyjson"
+)
+
+// webhookId reads the id of the webhook a request is about.
+func webhookId(c echo.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, invalid("Webhook id must be a number", err)
+	}
+	return id, nil
+}
+
+func (sd SmthHandler) CreateWebhook(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	body, err := ioutil.ReadAll(c.Request().Body)
+	if err != nil {
+		return invalid("Malformed webhook", err)
+	}
+	hook := &models.Webhook{}
+	if err := easyjson.Unmarshal(body, hook); err != nil {
+		return invalid("Malformed webhook", err)
+	}
+	if err := check(hook, false); err != nil {
+		return err
+	}
+
+	result, err := sd.UseCase.CreateWebhook(c.Request().Context(), *hook)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+func (sd SmthHandler) GetWebhooks(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	hooks, err := sd.UseCase.Webhooks(c.Request().Context(), c.QueryParam("forum"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, hooks)
+}
+
+func (sd SmthHandler) GetWebhook(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := webhookId(c)
+	if err != nil {
+		return err
+	}
+
+	hook, err := sd.UseCase.Webhook(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, hook)
+}
+
+func (sd SmthHandler) DeleteWebhook(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := webhookId(c)
+	if err != nil {
+		return err
+	}
+
+	if err := sd.UseCase.DeleteWebhook(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sd SmthHandler) GetDeliveries(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := webhookId(c)
+	if err != nil {
+		return err
+	}
+	state := c.QueryParam("state")
+	switch state {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+	default:
+		return invalid("Unknown delivery state", fmt.Errorf("state must be %s, %s or %s",
+			models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead))
+	}
+
+	deliveries, err := sd.UseCase.Deliveries(c.Request().Context(), id, state, sd.limit(c))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+func (sd SmthHandler) Redeliver(c echo.Context) error {
+	defer c.Request().Body.Close()
+
+	id, err := webhookId(c)
+	if err != nil {
+		return err
+	}
+	delivery, err := strconv.ParseInt(c.Param("delivery"), 10, 64)
+	if err != nil {
+		return invalid("Delivery id must be a number", err)
+	}
+
+	result, err := sd.UseCase.Redeliver(c.Request().Context(), id, delivery)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, result)
+}
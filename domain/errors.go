@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies an Error; the delivery layer maps it to an HTTP status.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindNotFound
+	KindConflict
+	KindUnprocessable
+	KindForbidden
+	KindUnauthenticated
+)
+
+// Error is returned by the use cases. Code is a stable machine-readable
+// identifier clients can branch on, Message is meant for humans and Details
+// carries the values the error is about.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details map[string]interface{}
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Message + ": " + e.Err.Error()
+	}
+	return e.Code + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors by code, so errors.Is(err, ErrUserNotFound) holds for
+// any user_not_found error whatever its message or details.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// With returns a copy of e with a formatted message and the given details,
+// passed as alternating keys and values.
+func (e *Error) With(message string, details ...interface{}) *Error {
+	out := *e
+	out.Message = message
+	if len(details) != 0 {
+		out.Details = make(map[string]interface{}, len(details)/2)
+		for i := 0; i+1 < len(details); i += 2 {
+			out.Details[fmt.Sprint(details[i])] = details[i+1]
+		}
+	}
+	return &out
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *Error) Wrap(err error) *Error {
+	out := *e
+	out.Err = err
+	return &out
+}
+
+var (
+	ErrInternal = &Error{Kind: KindInternal, Code: "internal_error", Message: "internal server error"}
+	ErrInvalid  = &Error{Kind: KindInvalid, Code: "invalid_request", Message: "invalid request"}
+	// ErrValidation reports a well-formed request whose fields break the
+	// rules of the models; details list the offending fields.
+	ErrValidation = &Error{Kind: KindUnprocessable, Code: "validation_failed", Message: "request does not pass validation"}
+	ErrForbidden  = &Error{Kind: KindForbidden, Code: "forbidden", Message: "not allowed"}
+	ErrUserBanned = &Error{Kind: KindForbidden, Code: "user_banned", Message: "user is banned"}
+	// ErrUserBlocked refuses private messages between users when one of
+	// them blocked the other.
+	ErrUserBlocked = &Error{Kind: KindForbidden, Code: "user_blocked", Message: "user is blocked"}
+	// ErrUnauthenticated asks for a session; ErrBadCredentials refuses a
+	// login without telling whether the nickname or the password was wrong.
+	ErrUnauthenticated = &Error{Kind: KindUnauthenticated, Code: "unauthenticated", Message: "authentication required"}
+	ErrBadCredentials  = &Error{Kind: KindUnauthenticated, Code: "invalid_credentials", Message: "wrong nickname or password"}
+
+	ErrUserNotFound         = &Error{Kind: KindNotFound, Code: "user_not_found", Message: "user not found"}
+	ErrForumNotFound        = &Error{Kind: KindNotFound, Code: "forum_not_found", Message: "forum not found"}
+	ErrThreadNotFound       = &Error{Kind: KindNotFound, Code: "thread_not_found", Message: "thread not found"}
+	ErrPostNotFound         = &Error{Kind: KindNotFound, Code: "post_not_found", Message: "post not found"}
+	ErrRevisionNotFound     = &Error{Kind: KindNotFound, Code: "revision_not_found", Message: "revision not found"}
+	ErrModeratorNotFound    = &Error{Kind: KindNotFound, Code: "moderator_not_found", Message: "moderator not found"}
+	ErrBanNotFound          = &Error{Kind: KindNotFound, Code: "ban_not_found", Message: "ban not found"}
+	ErrWebhookNotFound      = &Error{Kind: KindNotFound, Code: "webhook_not_found", Message: "webhook not found"}
+	ErrDeliveryNotFound     = &Error{Kind: KindNotFound, Code: "delivery_not_found", Message: "delivery not found"}
+	ErrNotificationNotFound = &Error{Kind: KindNotFound, Code: "notification_not_found", Message: "notification not found"}
+	ErrSubscriptionNotFound = &Error{Kind: KindNotFound, Code: "subscription_not_found", Message: "subscription not found"}
+	ErrConversationNotFound = &Error{Kind: KindNotFound, Code: "conversation_not_found", Message: "conversation not found"}
+	ErrMessageNotFound      = &Error{Kind: KindNotFound, Code: "message_not_found", Message: "message not found"}
+	ErrBlockNotFound        = &Error{Kind: KindNotFound, Code: "block_not_found", Message: "block not found"}
+
+	ErrUserExists   = &Error{Kind: KindConflict, Code: "user_exists", Message: "user already exists"}
+	ErrEmailTaken   = &Error{Kind: KindConflict, Code: "email_taken", Message: "email is used by another user"}
+	ErrForumExists  = &Error{Kind: KindConflict, Code: "forum_exists", Message: "forum already exists"}
+	ErrThreadExists = &Error{Kind: KindConflict, Code: "thread_exists", Message: "thread already exists"}
+	ErrParentPost   = &Error{Kind: KindConflict, Code: "parent_not_in_thread", Message: "parent post is not in this thread"}
+	// ErrPostUnchanged reports an edit that keeps the message as it was; the
+	// post is returned alongside it.
+	ErrPostUnchanged = &Error{Kind: KindConflict, Code: "post_unchanged", Message: "message is unchanged"}
+	ErrPostDeleted   = &Error{Kind: KindConflict, Code: "post_deleted", Message: "post is deleted"}
+	// ErrThreadArchived refuses changes to a read-only thread.
+	ErrThreadArchived = &Error{Kind: KindConflict, Code: "thread_archived", Message: "thread is archived"}
+	// ErrThreadLocked and ErrThreadClosed refuse new posts and votes.
+	ErrThreadLocked = &Error{Kind: KindConflict, Code: "thread_locked", Message: "thread is locked"}
+	ErrThreadClosed = &Error{Kind: KindConflict, Code: "thread_closed", Message: "thread is closed"}
+)
+
+// Internal wraps an unexpected failure.
+func Internal(err error) *Error {
+	return ErrInternal.Wrap(err)
+}
+
+// KindOf returns the kind of err, KindInternal for errors that are not
+// domain errors.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
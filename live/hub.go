@@ -0,0 +1,192 @@
+// Package live passes the events of the repository's event log to the
+// clients streaming them.
+package live
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	smth "subd"
+	"subd/models"
+)
+
+const (
+	// buffer is how many events a subscriber may fall behind before it is
+	// dropped.
+	buffer = 256
+	// batch is how many events the hub reads from the log at once.
+	batch = 100
+	// retry is the pause after the storage failed.
+	retry = time.Second
+	// pruneEvery is how often the hub drops events past their retention.
+	pruneEvery = time.Minute
+)
+
+// Subscription receives the events matching its query. Events is closed
+// once the subscriber falls behind or the hub stops, and the client is
+// expected to resume from the last event it got.
+type Subscription struct {
+	Events <-chan models.Event
+
+	query  models.EventQuery
+	events chan models.Event
+}
+
+// Hub follows the event log and hands every new event to the subscriptions
+// it matches. Each server sharing a database runs its own hub, so that an
+// event added through any of them reaches the clients of all of them.
+type Hub struct {
+	repo      smth.Repository
+	retention time.Duration
+
+	mu     sync.Mutex
+	subs   map[*Subscription]bool
+	closed bool
+}
+
+// NewHub returns a hub keeping events in the log for retention.
+func NewHub(repo smth.Repository, retention time.Duration) *Hub {
+	return &Hub{repo: repo, retention: retention, subs: make(map[*Subscription]bool)}
+}
+
+// Run follows the log until ctx is done, then ends every subscription.
+func (h *Hub) Run(ctx context.Context) {
+	defer h.close()
+
+	last, err := h.repo.LastEventId(ctx)
+	for err != nil {
+		log.Printf("live: reading the event log: %v", err)
+		if !sleep(ctx, retry) {
+			return
+		}
+		last, err = h.repo.LastEventId(ctx)
+	}
+
+	wake := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+	go func() {
+		for ctx.Err() == nil {
+			if err := h.repo.ListenEvents(ctx, notify); err != nil && ctx.Err() == nil {
+				log.Printf("live: listening for events: %v", err)
+				sleep(ctx, retry)
+			}
+		}
+	}()
+
+	prune := time.NewTicker(pruneEvery)
+	defer prune.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+			last = h.follow(ctx, last)
+		case <-prune.C:
+			if err := h.repo.PruneEvents(ctx, time.Now().Add(-h.retention)); err != nil && ctx.Err() == nil {
+				log.Printf("live: pruning events: %v", err)
+			}
+			// Also catches up after a failed read.
+			last = h.follow(ctx, last)
+		}
+	}
+}
+
+// follow publishes the events after the given id and returns the id of the
+// last one.
+func (h *Hub) follow(ctx context.Context, after int64) int64 {
+	for {
+		events, err := h.repo.GetEvents(ctx, models.EventQuery{After: after, Limit: batch})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("live: reading the event log: %v", err)
+			}
+			return after
+		}
+		for _, event := range events {
+			h.publish(event)
+			after = event.Id
+		}
+		if len(events) < batch {
+			return after
+		}
+	}
+}
+
+func (h *Hub) publish(event models.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		if !sub.query.Matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			h.drop(sub)
+		}
+	}
+}
+
+// Subscribe starts passing the new events matching query, whatever its
+// After and Limit, to the returned subscription.
+func (h *Hub) Subscribe(query models.EventQuery) *Subscription {
+	events := make(chan models.Event, buffer)
+	sub := &Subscription{Events: events, query: query, events: events}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(events)
+	} else {
+		h.subs[sub] = true
+	}
+
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.drop(sub)
+}
+
+// close ends every subscription and refuses new ones, so that the streams
+// end before the server shuts down.
+func (h *Hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subs {
+		h.drop(sub)
+	}
+}
+
+func (h *Hub) drop(sub *Subscription) {
+	if h.subs[sub] {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
+
+// sleep waits for d and reports whether ctx is still alive.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,203 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	event "subd"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/echo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "subd"
+
+	// statusTimeout bounds the Status query run on every scrape.
+	statusTimeout = 5 * time.Second
+)
+
+// Metrics owns a dedicated registry, so that several servers in one process
+// (or tests) never clash on registration.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	queryErrors     *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route, method and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "query_duration_seconds",
+			Help:      "Repository call latency by method.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"method"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "query_errors_total",
+			Help:      "Repository calls that failed, by method.",
+		}, []string{"method"}),
+	}
+
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.queryDuration,
+		m.queryErrors,
+	)
+
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware records latency and status of every request under the route
+// pattern it matched, e.g. /api/thread/:slug_or_id/posts.
+func (m *Metrics) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			err := next(c)
+			if err != nil {
+				// Render the error now so that the recorded status is the
+				// one the client gets.
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			method := c.Request().Method
+			m.requestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+			m.requests.WithLabelValues(method, route, strconv.Itoa(c.Response().Status)).Inc()
+
+			return nil
+		}
+	}
+}
+
+func (m *Metrics) observeQuery(method string, start time.Time, failed bool) {
+	m.queryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if failed {
+		m.queryErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// failedStatus tells whether a status returned by a Repository method
+// reports a failure rather than a lookup result.
+func failedStatus(status int) bool {
+	return status >= http.StatusInternalServerError
+}
+
+// RegisterPool exports pgxpool statistics.
+func (m *Metrics) RegisterPool(pool *pgxpool.Pool) {
+	m.registry.MustRegister(&poolCollector{pool: pool})
+}
+
+// RegisterStatus exports the counters of Repository.Status as gauges.
+func (m *Metrics) RegisterStatus(repo event.Repository) {
+	m.registry.MustRegister(&statusCollector{repo: repo})
+}
+
+var (
+	poolAcquiredDesc = prometheus.NewDesc(namespace+"_pool_acquired_conns",
+		"Connections currently acquired from the pool.", nil, nil)
+	poolIdleDesc = prometheus.NewDesc(namespace+"_pool_idle_conns",
+		"Idle connections in the pool.", nil, nil)
+	poolTotalDesc = prometheus.NewDesc(namespace+"_pool_total_conns",
+		"Connections currently in the pool.", nil, nil)
+	poolMaxDesc = prometheus.NewDesc(namespace+"_pool_max_conns",
+		"Maximum size of the pool.", nil, nil)
+	poolAcquiresDesc = prometheus.NewDesc(namespace+"_pool_acquires_total",
+		"Successful acquires from the pool.", nil, nil)
+	poolEmptyAcquiresDesc = prometheus.NewDesc(namespace+"_pool_empty_acquires_total",
+		"Acquires that had to wait for a connection.", nil, nil)
+	poolCanceledAcquiresDesc = prometheus.NewDesc(namespace+"_pool_canceled_acquires_total",
+		"Acquires cancelled by their context.", nil, nil)
+	poolAcquireWaitDesc = prometheus.NewDesc(namespace+"_pool_acquire_wait_seconds_total",
+		"Total time spent acquiring connections.", nil, nil)
+)
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+func (pc *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolAcquiredDesc
+	ch <- poolIdleDesc
+	ch <- poolTotalDesc
+	ch <- poolMaxDesc
+	ch <- poolAcquiresDesc
+	ch <- poolEmptyAcquiresDesc
+	ch <- poolCanceledAcquiresDesc
+	ch <- poolAcquireWaitDesc
+}
+
+func (pc *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := pc.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolAcquiredDesc, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdleDesc, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotalDesc, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMaxDesc, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquiresDesc, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquiresDesc, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolCanceledAcquiresDesc, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireWaitDesc, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
+
+var statusDesc = prometheus.NewDesc(namespace+"_entities",
+	"Number of stored entities, as reported by /api/service/status.", []string{"kind"}, nil)
+
+type statusCollector struct {
+	repo event.Repository
+}
+
+func (sc *statusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- statusDesc
+}
+
+func (sc *statusCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+	defer cancel()
+
+	status, err := sc.repo.Status(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(statusDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(statusDesc, prometheus.GaugeValue, float64(status.Forum), "forums")
+	ch <- prometheus.MustNewConstMetric(statusDesc, prometheus.GaugeValue, float64(status.Thread), "threads")
+	ch <- prometheus.MustNewConstMetric(statusDesc, prometheus.GaugeValue, float64(status.Post), "posts")
+	ch <- prometheus.MustNewConstMetric(statusDesc, prometheus.GaugeValue, float64(status.User), "users")
+}
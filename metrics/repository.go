@@ -0,0 +1,742 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	event "subd"
+	"subd/models"
+)
+
+// repository times every call of the wrapped Repository and counts its
+// failures.
+type repository struct {
+	event.Repository
+	m *Metrics
+}
+
+// InstrumentRepository wraps repo so that each method reports to m.
+func (m *Metrics) InstrumentRepository(repo event.Repository) event.Repository {
+	return &repository{Repository: repo, m: m}
+}
+
+func (rr *repository) CheckUser(ctx context.Context, user string) (bool, error) {
+	start := time.Now()
+	result, err := rr.Repository.CheckUser(ctx, user)
+	rr.m.observeQuery("CheckUser", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) CheckUserByEmail(ctx context.Context, email string) (bool, error) {
+	start := time.Now()
+	result, err := rr.Repository.CheckUserByEmail(ctx, email)
+	rr.m.observeQuery("CheckUserByEmail", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) CheckUserByNicknameOrEmail(ctx context.Context, nickname string, email string) (bool, error) {
+	start := time.Now()
+	result, err := rr.Repository.CheckUserByNicknameOrEmail(ctx, nickname, email)
+	rr.m.observeQuery("CheckUserByNicknameOrEmail", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) AddNewForum(ctx context.Context, newForum *models.Forum) (error, bool) {
+	start := time.Now()
+	err, exists := rr.Repository.AddNewForum(ctx, newForum)
+	rr.m.observeQuery("AddNewForum", start, err != nil)
+	return err, exists
+}
+
+func (rr *repository) GetForumCounts(ctx context.Context, slug string) (uint64, uint64, error) {
+	start := time.Now()
+	first, second, err := rr.Repository.GetForumCounts(ctx, slug)
+	rr.m.observeQuery("GetForumCounts", start, err != nil)
+	return first, second, err
+}
+
+func (rr *repository) GetForum(ctx context.Context, slug string) (models.Forum, int) {
+	start := time.Now()
+	result, status := rr.Repository.GetForum(ctx, slug)
+	rr.m.observeQuery("GetForum", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) CheckForum(ctx context.Context, slug string) (bool, error) {
+	start := time.Now()
+	result, err := rr.Repository.CheckForum(ctx, slug)
+	rr.m.observeQuery("CheckForum", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) CheckThread(ctx context.Context, slug string) (bool, error) {
+	start := time.Now()
+	result, err := rr.Repository.CheckThread(ctx, slug)
+	rr.m.observeQuery("CheckThread", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) CheckThreadById(ctx context.Context, id int) (bool, error) {
+	start := time.Now()
+	result, err := rr.Repository.CheckThreadById(ctx, id)
+	rr.m.observeQuery("CheckThreadById", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) CheckPost(ctx context.Context, id int) (bool, error) {
+	start := time.Now()
+	result, err := rr.Repository.CheckPost(ctx, id)
+	rr.m.observeQuery("CheckPost", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetThread(ctx context.Context, slug string) (models.Thread, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetThread(ctx, slug)
+	rr.m.observeQuery("GetThread", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetThreadStatus(ctx context.Context, slug string) (models.Thread, int) {
+	start := time.Now()
+	result, status := rr.Repository.GetThreadStatus(ctx, slug)
+	rr.m.observeQuery("GetThreadStatus", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) GetThreadById(ctx context.Context, id int) (models.Thread, int) {
+	start := time.Now()
+	result, status := rr.Repository.GetThreadById(ctx, id)
+	rr.m.observeQuery("GetThreadById", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) GetPost(ctx context.Context, id int) (models.Post, int) {
+	start := time.Now()
+	result, status := rr.Repository.GetPost(ctx, id)
+	rr.m.observeQuery("GetPost", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) GetUser(ctx context.Context, name string) (models.User, int) {
+	start := time.Now()
+	result, status := rr.Repository.GetUser(ctx, name)
+	rr.m.observeQuery("GetUser", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) AddNewThread(ctx context.Context, newThread models.Thread) (uint64, error) {
+	start := time.Now()
+	result, err := rr.Repository.AddNewThread(ctx, newThread)
+	rr.m.observeQuery("AddNewThread", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetForumUsers(ctx context.Context, slug string, limit int, since string, desc bool, excludeBanned bool) (models.Users, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetForumUsers(ctx, slug, limit, since, desc, excludeBanned)
+	rr.m.observeQuery("GetForumUsers", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) AddForumUsers(ctx context.Context, slug string, author string) error {
+	start := time.Now()
+	err := rr.Repository.AddForumUsers(ctx, slug, author)
+	rr.m.observeQuery("AddForumUsers", start, err != nil)
+	return err
+}
+
+func (rr *repository) GetForumThreads(ctx context.Context, query models.ThreadQuery) (models.Threads, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetForumThreads(ctx, query)
+	rr.m.observeQuery("GetForumThreads", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) EditMessage(ctx context.Context, id int, message string, editor string) error {
+	start := time.Now()
+	err := rr.Repository.EditMessage(ctx, id, message, editor)
+	rr.m.observeQuery("EditMessage", start, err != nil)
+	return err
+}
+
+func (rr *repository) GetPostRevisions(ctx context.Context, id int) (models.Revisions, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetPostRevisions(ctx, id)
+	rr.m.observeQuery("GetPostRevisions", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) DeletePost(ctx context.Context, id int) int {
+	start := time.Now()
+	status := rr.Repository.DeletePost(ctx, id)
+	rr.m.observeQuery("DeletePost", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) RestorePost(ctx context.Context, id int) int {
+	start := time.Now()
+	status := rr.Repository.RestorePost(ctx, id)
+	rr.m.observeQuery("RestorePost", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) Clear(ctx context.Context) error {
+	start := time.Now()
+	err := rr.Repository.Clear(ctx)
+	rr.m.observeQuery("Clear", start, err != nil)
+	return err
+}
+
+func (rr *repository) Status(ctx context.Context) (models.Status, error) {
+	start := time.Now()
+	result, err := rr.Repository.Status(ctx)
+	rr.m.observeQuery("Status", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) CreateUser(ctx context.Context, nickname string, user models.User) error {
+	start := time.Now()
+	err := rr.Repository.CreateUser(ctx, nickname, user)
+	rr.m.observeQuery("CreateUser", start, err != nil)
+	return err
+}
+
+func (rr *repository) GetUserByNicknameOrEmail(ctx context.Context, nickname string, email string) (models.Users, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetUserByNicknameOrEmail(ctx, nickname, email)
+	rr.m.observeQuery("GetUserByNicknameOrEmail", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) UpdateUser(ctx context.Context, nickname string, user models.User) error {
+	start := time.Now()
+	err := rr.Repository.UpdateUser(ctx, nickname, user)
+	rr.m.observeQuery("UpdateUser", start, err != nil)
+	return err
+}
+
+func (rr *repository) IncrementThreads(ctx context.Context, forum string) error {
+	start := time.Now()
+	err := rr.Repository.IncrementThreads(ctx, forum)
+	rr.m.observeQuery("IncrementThreads", start, err != nil)
+	return err
+}
+
+func (rr *repository) IncrementPosts(ctx context.Context, forum string) error {
+	start := time.Now()
+	err := rr.Repository.IncrementPosts(ctx, forum)
+	rr.m.observeQuery("IncrementPosts", start, err != nil)
+	return err
+}
+
+func (rr *repository) AddPost(ctx context.Context, newPosts []*models.Post, thread models.Thread, now time.Time) int {
+	start := time.Now()
+	status := rr.Repository.AddPost(ctx, newPosts, thread, now)
+	rr.m.observeQuery("AddPost", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) UpdateThread(ctx context.Context, slugOrId string, thread models.Thread) (models.Thread, error) {
+	start := time.Now()
+	result, err := rr.Repository.UpdateThread(ctx, slugOrId, thread)
+	rr.m.observeQuery("UpdateThread", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) UpdateThreadById(ctx context.Context, id int, thread models.Thread) (models.Thread, error) {
+	start := time.Now()
+	result, err := rr.Repository.UpdateThreadById(ctx, id, thread)
+	rr.m.observeQuery("UpdateThreadById", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) CheckVote(ctx context.Context, id int, nickname string) (bool, error) {
+	start := time.Now()
+	result, err := rr.Repository.CheckVote(ctx, id, nickname)
+	rr.m.observeQuery("CheckVote", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) AddVote(ctx context.Context, id int, vote models.Vote) error {
+	start := time.Now()
+	err := rr.Repository.AddVote(ctx, id, vote)
+	rr.m.observeQuery("AddVote", start, err != nil)
+	return err
+}
+
+func (rr *repository) UpdateVote(ctx context.Context, id int, vote models.Vote) error {
+	start := time.Now()
+	err := rr.Repository.UpdateVote(ctx, id, vote)
+	rr.m.observeQuery("UpdateVote", start, err != nil)
+	return err
+}
+
+func (rr *repository) GetValueVote(ctx context.Context, id int, nickname string) (int, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetValueVote(ctx, id, nickname)
+	rr.m.observeQuery("GetValueVote", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetPostsFlat(ctx context.Context, id int, limit int, after *models.PostKey) (models.Posts, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetPostsFlat(ctx, id, limit, after)
+	rr.m.observeQuery("GetPostsFlat", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetPostsFlatDesc(ctx context.Context, id int, limit int, after *models.PostKey) (models.Posts, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetPostsFlatDesc(ctx, id, limit, after)
+	rr.m.observeQuery("GetPostsFlatDesc", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetPostsTree(ctx context.Context, id int, limit int) (models.Posts, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetPostsTree(ctx, id, limit)
+	rr.m.observeQuery("GetPostsTree", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetPostsTreeDesc(ctx context.Context, id int, limit int) (models.Posts, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetPostsTreeDesc(ctx, id, limit)
+	rr.m.observeQuery("GetPostsTreeDesc", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetPostsTreeSince(ctx context.Context, id int, limit int, since int) (models.Posts, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetPostsTreeSince(ctx, id, limit, since)
+	rr.m.observeQuery("GetPostsTreeSince", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetPostsTreeSinceDesc(ctx context.Context, id int, limit int, since int) (models.Posts, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetPostsTreeSinceDesc(ctx, id, limit, since)
+	rr.m.observeQuery("GetPostsTreeSinceDesc", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetPostsParentTree(ctx context.Context, id int, limit int) (models.Posts, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetPostsParentTree(ctx, id, limit)
+	rr.m.observeQuery("GetPostsParentTree", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetPostsParentTreeDesc(ctx context.Context, id int, limit int) (models.Posts, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetPostsParentTreeDesc(ctx, id, limit)
+	rr.m.observeQuery("GetPostsParentTreeDesc", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetPostsParentTreeSince(ctx context.Context, id int, limit int, since int) (models.Posts, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetPostsParentTreeSince(ctx, id, limit, since)
+	rr.m.observeQuery("GetPostsParentTreeSince", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetPostsParentTreeSinceDesc(ctx context.Context, id int, limit int, since int) (models.Posts, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetPostsParentTreeSinceDesc(ctx, id, limit, since)
+	rr.m.observeQuery("GetPostsParentTreeSinceDesc", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetPostNull(ctx context.Context, id int) (models.PostNullMessage, int) {
+	start := time.Now()
+	result, status := rr.Repository.GetPostNull(ctx, id)
+	rr.m.observeQuery("GetPostNull", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) DeleteThread(ctx context.Context, id int) int {
+	start := time.Now()
+	status := rr.Repository.DeleteThread(ctx, id)
+	rr.m.observeQuery("DeleteThread", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) SetThreadArchived(ctx context.Context, id int, archived bool) int {
+	start := time.Now()
+	status := rr.Repository.SetThreadArchived(ctx, id, archived)
+	rr.m.observeQuery("SetThreadArchived", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) ModerateThread(ctx context.Context, id int, m models.ThreadModeration) int {
+	start := time.Now()
+	status := rr.Repository.ModerateThread(ctx, id, m)
+	rr.m.observeQuery("ModerateThread", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) SetCredentials(ctx context.Context, nickname string, passwordHash []byte) error {
+	start := time.Now()
+	err := rr.Repository.SetCredentials(ctx, nickname, passwordHash)
+	rr.m.observeQuery("SetCredentials", start, err != nil)
+	return err
+}
+
+func (rr *repository) GetCredentials(ctx context.Context, nickname string) (models.Credentials, int) {
+	start := time.Now()
+	result, status := rr.Repository.GetCredentials(ctx, nickname)
+	rr.m.observeQuery("GetCredentials", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) AddSession(ctx context.Context, tokenHash []byte, nickname string, expires time.Time) error {
+	start := time.Now()
+	err := rr.Repository.AddSession(ctx, tokenHash, nickname, expires)
+	rr.m.observeQuery("AddSession", start, err != nil)
+	return err
+}
+
+func (rr *repository) GetSession(ctx context.Context, tokenHash []byte) (models.Caller, int) {
+	start := time.Now()
+	result, status := rr.Repository.GetSession(ctx, tokenHash)
+	rr.m.observeQuery("GetSession", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) DeleteSession(ctx context.Context, tokenHash []byte) error {
+	start := time.Now()
+	err := rr.Repository.DeleteSession(ctx, tokenHash)
+	rr.m.observeQuery("DeleteSession", start, err != nil)
+	return err
+}
+
+func (rr *repository) SetRole(ctx context.Context, nickname string, role string) int {
+	start := time.Now()
+	status := rr.Repository.SetRole(ctx, nickname, role)
+	rr.m.observeQuery("SetRole", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) AddModerator(ctx context.Context, forum string, nickname string) error {
+	start := time.Now()
+	err := rr.Repository.AddModerator(ctx, forum, nickname)
+	rr.m.observeQuery("AddModerator", start, err != nil)
+	return err
+}
+
+func (rr *repository) RemoveModerator(ctx context.Context, forum string, nickname string) int {
+	start := time.Now()
+	status := rr.Repository.RemoveModerator(ctx, forum, nickname)
+	rr.m.observeQuery("RemoveModerator", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) GetModerators(ctx context.Context, forum string) (models.Users, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetModerators(ctx, forum)
+	rr.m.observeQuery("GetModerators", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) IsModerator(ctx context.Context, forum string, nickname string) (bool, error) {
+	start := time.Now()
+	result, err := rr.Repository.IsModerator(ctx, forum, nickname)
+	rr.m.observeQuery("IsModerator", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) AddBan(ctx context.Context, ban models.Ban) error {
+	start := time.Now()
+	err := rr.Repository.AddBan(ctx, ban)
+	rr.m.observeQuery("AddBan", start, err != nil)
+	return err
+}
+
+func (rr *repository) RemoveBan(ctx context.Context, forum string, nickname string) int {
+	start := time.Now()
+	status := rr.Repository.RemoveBan(ctx, forum, nickname)
+	rr.m.observeQuery("RemoveBan", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) GetForumBans(ctx context.Context, forum string) (models.Bans, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetForumBans(ctx, forum)
+	rr.m.observeQuery("GetForumBans", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) FindBans(ctx context.Context, forum string, nicknames []string) (models.Bans, error) {
+	start := time.Now()
+	result, err := rr.Repository.FindBans(ctx, forum, nicknames)
+	rr.m.observeQuery("FindBans", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) Search(ctx context.Context, query models.SearchQuery) (models.SearchHits, error) {
+	start := time.Now()
+	result, err := rr.Repository.Search(ctx, query)
+	rr.m.observeQuery("Search", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) FindUsers(ctx context.Context, query models.UserQuery) ([]models.UserMatch, error) {
+	start := time.Now()
+	result, err := rr.Repository.FindUsers(ctx, query)
+	rr.m.observeQuery("FindUsers", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetEvents(ctx context.Context, query models.EventQuery) (models.Events, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetEvents(ctx, query)
+	rr.m.observeQuery("GetEvents", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) LastEventId(ctx context.Context) (int64, error) {
+	start := time.Now()
+	result, err := rr.Repository.LastEventId(ctx)
+	rr.m.observeQuery("LastEventId", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) PruneEvents(ctx context.Context, before time.Time) error {
+	start := time.Now()
+	err := rr.Repository.PruneEvents(ctx, before)
+	rr.m.observeQuery("PruneEvents", start, err != nil)
+	return err
+}
+
+func (rr *repository) AddWebhook(ctx context.Context, hook *models.Webhook) error {
+	start := time.Now()
+	err := rr.Repository.AddWebhook(ctx, hook)
+	rr.m.observeQuery("AddWebhook", start, err != nil)
+	return err
+}
+
+func (rr *repository) GetWebhooks(ctx context.Context, forum string) (models.Webhooks, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetWebhooks(ctx, forum)
+	rr.m.observeQuery("GetWebhooks", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetWebhook(ctx context.Context, id int) (models.Webhook, int) {
+	start := time.Now()
+	result, status := rr.Repository.GetWebhook(ctx, id)
+	rr.m.observeQuery("GetWebhook", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) DeleteWebhook(ctx context.Context, id int) int {
+	start := time.Now()
+	status := rr.Repository.DeleteWebhook(ctx, id)
+	rr.m.observeQuery("DeleteWebhook", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) DispatchOutbox(ctx context.Context, limit int) (int, error) {
+	start := time.Now()
+	result, err := rr.Repository.DispatchOutbox(ctx, limit)
+	rr.m.observeQuery("DispatchOutbox", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) ClaimDeliveries(ctx context.Context, now time.Time, until time.Time, limit int) ([]models.DueDelivery, error) {
+	start := time.Now()
+	result, err := rr.Repository.ClaimDeliveries(ctx, now, until, limit)
+	rr.m.observeQuery("ClaimDeliveries", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) RecordDelivery(ctx context.Context, id int64, result models.DeliveryResult) error {
+	start := time.Now()
+	err := rr.Repository.RecordDelivery(ctx, id, result)
+	rr.m.observeQuery("RecordDelivery", start, err != nil)
+	return err
+}
+
+func (rr *repository) GetDeliveries(ctx context.Context, webhook int, state string, limit int) (models.Deliveries, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetDeliveries(ctx, webhook, state, limit)
+	rr.m.observeQuery("GetDeliveries", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) Redeliver(ctx context.Context, webhook int, id int64) (models.Delivery, int) {
+	start := time.Now()
+	result, status := rr.Repository.Redeliver(ctx, webhook, id)
+	rr.m.observeQuery("Redeliver", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) PruneDeliveries(ctx context.Context, before time.Time) error {
+	start := time.Now()
+	err := rr.Repository.PruneDeliveries(ctx, before)
+	rr.m.observeQuery("PruneDeliveries", start, err != nil)
+	return err
+}
+
+func (rr *repository) SubscribeForum(ctx context.Context, forum string, nickname string) error {
+	start := time.Now()
+	err := rr.Repository.SubscribeForum(ctx, forum, nickname)
+	rr.m.observeQuery("SubscribeForum", start, err != nil)
+	return err
+}
+
+func (rr *repository) UnsubscribeForum(ctx context.Context, forum string, nickname string) int {
+	start := time.Now()
+	status := rr.Repository.UnsubscribeForum(ctx, forum, nickname)
+	rr.m.observeQuery("UnsubscribeForum", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) GetNotifications(ctx context.Context, query models.NotificationQuery) (models.Notifications, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetNotifications(ctx, query)
+	rr.m.observeQuery("GetNotifications", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) CountUnreadNotifications(ctx context.Context, nickname string) (map[string]int, error) {
+	start := time.Now()
+	result, err := rr.Repository.CountUnreadNotifications(ctx, nickname)
+	rr.m.observeQuery("CountUnreadNotifications", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) MarkNotificationRead(ctx context.Context, nickname string, id int64) int {
+	start := time.Now()
+	status := rr.Repository.MarkNotificationRead(ctx, nickname, id)
+	rr.m.observeQuery("MarkNotificationRead", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) MarkNotificationsRead(ctx context.Context, nickname string, upTo int64) (int, error) {
+	start := time.Now()
+	result, err := rr.Repository.MarkNotificationsRead(ctx, nickname, upTo)
+	rr.m.observeQuery("MarkNotificationsRead", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) SubscribeThread(ctx context.Context, thread int, nickname string) error {
+	start := time.Now()
+	err := rr.Repository.SubscribeThread(ctx, thread, nickname)
+	rr.m.observeQuery("SubscribeThread", start, err != nil)
+	return err
+}
+
+func (rr *repository) UnsubscribeThread(ctx context.Context, thread int, nickname string) int {
+	start := time.Now()
+	status := rr.Repository.UnsubscribeThread(ctx, thread, nickname)
+	rr.m.observeQuery("UnsubscribeThread", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) GetSubscriptions(ctx context.Context, nickname string) (models.Subscriptions, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetSubscriptions(ctx, nickname)
+	rr.m.observeQuery("GetSubscriptions", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) GetLastRead(ctx context.Context, thread int, nickname string) (int, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetLastRead(ctx, thread, nickname)
+	rr.m.observeQuery("GetLastRead", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) MarkThreadRead(ctx context.Context, thread int, nickname string, post int) (models.ThreadRead, error) {
+	start := time.Now()
+	result, err := rr.Repository.MarkThreadRead(ctx, thread, nickname, post)
+	rr.m.observeQuery("MarkThreadRead", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) AddConversation(ctx context.Context, conversation *models.Conversation, message *models.PrivateMessage) error {
+	start := time.Now()
+	err := rr.Repository.AddConversation(ctx, conversation, message)
+	rr.m.observeQuery("AddConversation", start, err != nil)
+	return err
+}
+
+func (rr *repository) GetConversation(ctx context.Context, id int, nickname string) (models.Conversation, int) {
+	start := time.Now()
+	result, status := rr.Repository.GetConversation(ctx, id, nickname)
+	rr.m.observeQuery("GetConversation", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) GetConversations(ctx context.Context, query models.ConversationQuery) (models.Conversations, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetConversations(ctx, query)
+	rr.m.observeQuery("GetConversations", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) AddPrivateMessage(ctx context.Context, message *models.PrivateMessage) error {
+	start := time.Now()
+	err := rr.Repository.AddPrivateMessage(ctx, message)
+	rr.m.observeQuery("AddPrivateMessage", start, err != nil)
+	return err
+}
+
+func (rr *repository) GetPrivateMessage(ctx context.Context, conversation int, id int64) (models.PrivateMessage, int) {
+	start := time.Now()
+	result, status := rr.Repository.GetPrivateMessage(ctx, conversation, id)
+	rr.m.observeQuery("GetPrivateMessage", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) GetPrivateMessages(ctx context.Context, query models.MessageQuery) (models.PrivateMessages, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetPrivateMessages(ctx, query)
+	rr.m.observeQuery("GetPrivateMessages", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) EditPrivateMessage(ctx context.Context, conversation int, id int64, message string) (models.PrivateMessage, int) {
+	start := time.Now()
+	result, status := rr.Repository.EditPrivateMessage(ctx, conversation, id, message)
+	rr.m.observeQuery("EditPrivateMessage", start, failedStatus(status))
+	return result, status
+}
+
+func (rr *repository) MarkConversationRead(ctx context.Context, conversation int, nickname string, upTo int64) (models.ConversationRead, error) {
+	start := time.Now()
+	result, err := rr.Repository.MarkConversationRead(ctx, conversation, nickname, upTo)
+	rr.m.observeQuery("MarkConversationRead", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) Block(ctx context.Context, nickname string, blocked string) error {
+	start := time.Now()
+	err := rr.Repository.Block(ctx, nickname, blocked)
+	rr.m.observeQuery("Block", start, err != nil)
+	return err
+}
+
+func (rr *repository) Unblock(ctx context.Context, nickname string, blocked string) int {
+	start := time.Now()
+	status := rr.Repository.Unblock(ctx, nickname, blocked)
+	rr.m.observeQuery("Unblock", start, failedStatus(status))
+	return status
+}
+
+func (rr *repository) GetBlocks(ctx context.Context, nickname string) (models.Blocks, error) {
+	start := time.Now()
+	result, err := rr.Repository.GetBlocks(ctx, nickname)
+	rr.m.observeQuery("GetBlocks", start, err != nil)
+	return result, err
+}
+
+func (rr *repository) Blocking(ctx context.Context, nickname string, others []string) ([]string, error) {
+	start := time.Now()
+	result, err := rr.Repository.Blocking(ctx, nickname, others)
+	rr.m.observeQuery("Blocking", start, err != nil)
+	return result, err
+}
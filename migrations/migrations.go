@@ -0,0 +1,361 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockKey is the pg_advisory_lock key that serializes concurrent migrators,
+// e.g. several replicas starting at once.
+const lockKey = 7283463001
+
+type Options struct {
+	// Unlogged creates tables as UNLOGGED: faster writes, but the data does
+	// not survive a crash and is not replicated.
+	Unlogged bool
+}
+
+type Migration struct {
+	Version  int
+	Name     string
+	Checksum string
+	up       string
+	down     string
+}
+
+type State struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type appliedMigration struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	pool       *pgxpool.Pool
+	opts       Options
+	migrations []Migration
+}
+
+// Load reads the embedded migrations, named <version>_<name>.up.sql and
+// <version>_<name>.down.sql, ordered by version.
+func Load() ([]Migration, error) {
+	return load(files)
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		sep := strings.IndexByte(base, '_')
+		if sep < 0 {
+			return nil, fmt.Errorf("migration %s: expected <version>_<name>", name)
+		}
+		version, err := strconv.Atoi(base[:sep])
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", name, err)
+		}
+		body, err := fs.ReadFile(fsys, path.Join("sql", name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: base[sep+1:]}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.up = string(body)
+			sum := sha256.Sum256(body)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Latest is the version the embedded migrations bring the schema to.
+func Latest() int {
+	migrations, err := Load()
+	if err != nil || len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+func NewMigrator(pool *pgxpool.Pool, opts Options) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{pool: pool, opts: opts, migrations: migrations}, nil
+}
+
+func (m *Migrator) render(script string) (string, error) {
+	tmpl, err := template.New("migration").Parse(script)
+	if err != nil {
+		return "", err
+	}
+
+	unlogged := ""
+	if m.opts.Unlogged {
+		unlogged = "UNLOGGED "
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, struct{ Unlogged string }{unlogged})
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// withLock runs fn on a single connection holding the migration advisory
+// lock, with schema_version guaranteed to exist.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, int64(lockKey))
+	if err != nil {
+		return err
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, int64(lockKey))
+
+	_, err = conn.Exec(ctx,
+		`CREATE TABLE IF NOT EXISTS schema_version
+		(
+			version    INT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			checksum   TEXT NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+		)`)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) applied(ctx context.Context, q pgxscan.Querier) ([]appliedMigration, error) {
+	var applied []appliedMigration
+	err := pgxscan.Select(ctx, q, &applied,
+		`SELECT version, name, checksum, applied_at FROM schema_version ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// verify makes sure every applied migration is still embedded unchanged.
+func (m *Migrator) verify(applied []appliedMigration) error {
+	known := make(map[int]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	for _, a := range applied {
+		migration, ok := known[a.Version]
+		if !ok {
+			return fmt.Errorf("schema version %d (%s) is applied but unknown to this build", a.Version, a.Name)
+		}
+		if migration.Checksum != a.Checksum {
+			return fmt.Errorf("migration %04d_%s was modified after it had been applied", a.Version, a.Name)
+		}
+	}
+
+	return nil
+}
+
+// Up applies all pending migrations in order, each in its own transaction.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err = m.verify(applied); err != nil {
+			return err
+		}
+
+		done := make(map[int]bool, len(applied))
+		for _, a := range applied {
+			done[a.Version] = true
+		}
+
+		for _, migration := range m.migrations {
+			if done[migration.Version] {
+				continue
+			}
+			script, err := m.render(migration.up)
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			err = m.inTx(ctx, conn, script, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx,
+					`INSERT INTO schema_version (version, name, checksum) VALUES ($1, $2, $3)`,
+					migration.Version, migration.Name, migration.Checksum)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down reverts the last steps applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err = m.verify(applied); err != nil {
+			return err
+		}
+
+		scripts := make(map[int]Migration, len(m.migrations))
+		for _, migration := range m.migrations {
+			scripts[migration.Version] = migration
+		}
+
+		for i := len(applied) - 1; i >= 0 && steps > 0; i, steps = i-1, steps-1 {
+			migration := scripts[applied[i].Version]
+			if migration.down == "" {
+				return fmt.Errorf("migration %04d_%s has no down script", migration.Version, migration.Name)
+			}
+			script, err := m.render(migration.down)
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			err = m.inTx(ctx, conn, script, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, `DELETE FROM schema_version WHERE version = $1`, migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (m *Migrator) inTx(ctx context.Context, conn *pgxpool.Conn, script string, record func(tx pgx.Tx) error) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, script); err != nil {
+		return err
+	}
+	if err = record(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Status lists every embedded migration and whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]State, error) {
+	var states []State
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err = m.verify(applied); err != nil {
+			return err
+		}
+
+		at := make(map[int]time.Time, len(applied))
+		for _, a := range applied {
+			at[a.Version] = a.AppliedAt
+		}
+		for _, migration := range m.migrations {
+			appliedAt, ok := at[migration.Version]
+			states = append(states, State{Migration: migration, Applied: ok, AppliedAt: appliedAt})
+		}
+
+		return nil
+	})
+
+	return states, err
+}
+
+// Version returns the highest applied schema version, 0 for an empty
+// database. It does not take the migration lock.
+func Version(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	var version int
+	err := pool.QueryRow(ctx,
+		`SELECT COALESCE(max(version), 0) FROM schema_version`).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
@@ -0,0 +1,184 @@
+package migrations
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+func script(body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(body)}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0010_tenth.up.sql":   script("CREATE TABLE tenth ()"),
+		"sql/0010_tenth.down.sql": script("DROP TABLE tenth"),
+		"sql/0002_second.up.sql":  script("CREATE TABLE second ()"),
+		"sql/0001_first.up.sql":   script("CREATE TABLE first ()"),
+		"sql/README.md":           script("not a migration"),
+	}
+
+	migrations, err := load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range migrations {
+		got = append(got, m.Name)
+	}
+	if strings.Join(got, " ") != "first second tenth" {
+		t.Fatalf("migrations are %v, want first second tenth by version", got)
+	}
+	if tenth := migrations[2]; tenth.Version != 10 || tenth.down != "DROP TABLE tenth" {
+		t.Errorf("tenth is version %d with down %q", tenth.Version, tenth.down)
+	}
+	if migrations[0].Checksum == migrations[1].Checksum {
+		t.Error("different scripts have the same checksum")
+	}
+}
+
+func TestLoadNaming(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		err   string
+	}{
+		{"no separator", fstest.MapFS{"sql/0001.up.sql": script("SELECT 1")}, "expected <version>_<name>"},
+		{"no version", fstest.MapFS{"sql/first_table.up.sql": script("SELECT 1")}, "first_table.up.sql"},
+		{"no up script", fstest.MapFS{"sql/0001_first.down.sql": script("SELECT 1")}, "0001_first has no up script"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(tt.files)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("load = %v, want an error about %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %s is version %d, want %d", m.Name, m.Version, i+1)
+		}
+		if m.down == "" {
+			t.Errorf("migration %04d_%s has no down script", m.Version, m.Name)
+		}
+	}
+	if Latest() != len(migrations) {
+		t.Errorf("Latest = %d, want %d", Latest(), len(migrations))
+	}
+}
+
+func TestVerify(t *testing.T) {
+	migrations, err := load(fstest.MapFS{
+		"sql/0001_first.up.sql":  script("CREATE TABLE first ()"),
+		"sql/0002_second.up.sql": script("CREATE TABLE second ()"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Migrator{migrations: migrations}
+	first := appliedMigration{Version: 1, Name: "first", Checksum: migrations[0].Checksum}
+
+	tests := []struct {
+		name    string
+		applied []appliedMigration
+		err     string
+	}{
+		{"nothing applied", nil, ""},
+		{"unchanged", []appliedMigration{first}, ""},
+		{"modified", []appliedMigration{first, {Version: 2, Name: "second", Checksum: "0"}},
+			"0002_second was modified"},
+		{"unknown", []appliedMigration{first, {Version: 3, Name: "third", Checksum: "0"}},
+			"version 3 (third) is applied but unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.verify(tt.applied)
+			if tt.err == "" && err != nil {
+				t.Errorf("verify = %v", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("verify = %v, want an error about %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// TestRoundTrip migrates the database SUBD_TEST_DSN names all the way down
+// and up again; it drops every table there, so never point it at one that
+// matters, and run it with -p 1 next to the repository tests, which use
+// the same database.
+func TestRoundTrip(t *testing.T) {
+	dsn := os.Getenv("SUBD_TEST_DSN")
+	if dsn == "" {
+		t.Skip("SUBD_TEST_DSN is not set")
+	}
+	ctx := context.Background()
+
+	pool, err := pgxpool.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connecting to %s: %v", dsn, err)
+	}
+	defer pool.Close()
+	migrator, err := NewMigrator(pool, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version := func() int {
+		t.Helper()
+		v, err := Version(ctx, pool)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if v := version(); v != Latest() {
+		t.Fatalf("schema is at version %d after Up, want %d", v, Latest())
+	}
+	if err := migrator.Down(ctx, Latest()); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if v := version(); v != 0 {
+		t.Fatalf("schema is at version %d after Down, want 0", v)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up again: %v", err)
+	}
+
+	states, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, state := range states {
+		if !state.Applied {
+			t.Errorf("migration %04d_%s is not applied", state.Version, state.Name)
+		}
+	}
+
+	// A script changed after it was applied stops the migrator.
+	_, err = pool.Exec(ctx, `UPDATE schema_version SET checksum = 'changed' WHERE version = 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Exec(context.Background(),
+		`UPDATE schema_version SET checksum = $1 WHERE version = 1`, migrator.migrations[0].Checksum)
+	if err := migrator.Up(ctx); err == nil || !strings.Contains(err.Error(), "modified") {
+		t.Errorf("Up over a modified migration = %v", err)
+	}
+}
@@ -1,21 +1,6 @@
 CREATE EXTENSION IF NOT EXISTS CITEXT;
 
-DROP TABLE IF EXISTS forum_users CASCADE;
-DROP TABLE IF EXISTS votes CASCADE;
-DROP TABLE IF EXISTS posts CASCADE;
-DROP TABLE IF EXISTS threads CASCADE;
-DROP TABLE IF EXISTS forums CASCADE;
-DROP TABLE IF EXISTS users CASCADE;
-
-DROP FUNCTION IF EXISTS insert_votes();
-DROP FUNCTION IF EXISTS update_votes();
-DROP FUNCTION IF EXISTS post_path();
-
-DROP TRIGGER IF EXISTS insert_votes ON votes;
-DROP TRIGGER IF EXISTS update_votes ON votes;
-DROP TRIGGER IF EXISTS post_path ON posts;
-
-CREATE UNLOGGED TABLE users
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS users
 (
     id       SERIAL PRIMARY KEY,
     nickname CITEXT COLLATE "C" UNIQUE NOT NULL,
@@ -24,10 +9,10 @@ CREATE UNLOGGED TABLE users
     email    CITEXT UNIQUE             NOT NULL
 );
 
-CREATE INDEX users_nickname ON users using hash (nickname);
-CREATE INDEX users_email ON users using hash (email);
+CREATE INDEX IF NOT EXISTS users_nickname ON users using hash (nickname);
+CREATE INDEX IF NOT EXISTS users_email ON users using hash (email);
 
-CREATE UNLOGGED TABLE forums
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS forums
 (
     id      SERIAL PRIMARY KEY,
     title   TEXT                      NOT NULL,
@@ -37,9 +22,9 @@ CREATE UNLOGGED TABLE forums
     slug    CITEXT UNIQUE NOT NULL
 );
 
-CREATE INDEX forums_slug ON forums USING hash (slug);
+CREATE INDEX IF NOT EXISTS forums_slug ON forums USING hash (slug);
 
-CREATE UNLOGGED TABLE threads
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS threads
 (
     id      SERIAL PRIMARY KEY,
     author  CITEXT REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
@@ -51,10 +36,10 @@ CREATE UNLOGGED TABLE threads
     votes   INT                      DEFAULT 0
 );
 
-create index threads_slug on threads using hash (slug);
-create index threads_forum_created on threads (forum, created);
+create index if not exists threads_slug on threads using hash (slug);
+create index if not exists threads_forum_created on threads (forum, created);
 
-CREATE UNLOGGED TABLE posts
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS posts
 (
     id        BIGSERIAL PRIMARY KEY,
     author    CITEXT REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
@@ -67,12 +52,12 @@ CREATE UNLOGGED TABLE posts
     path      BIGINT[]
 );
 
-create index posts_thread_created_id on posts (thread, created, id);
-create index posts_thread_id on posts (thread, id);
-create index posts_thread_path on posts (thread, path);
-create index posts_path_1_path on posts ((path[1]));
+create index if not exists posts_thread_created_id on posts (thread, created, id);
+create index if not exists posts_thread_id on posts (thread, id);
+create index if not exists posts_thread_path on posts (thread, path);
+create index if not exists posts_path_1_path on posts ((path[1]));
 
-CREATE UNLOGGED TABLE votes
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS votes
 (
     thread   INT REFERENCES threads (id) NOT NULL,
     voice    INT                NOT NULL,
@@ -80,17 +65,17 @@ CREATE UNLOGGED TABLE votes
     UNIQUE (thread, nickname)
 );
 
-create index votes_user_thread on votes (thread, nickname);
+create index if not exists votes_user_thread on votes (thread, nickname);
 
-CREATE UNLOGGED TABLE forum_users
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS forum_users
 (
     forum    CITEXT REFERENCES forums (slug) ON DELETE CASCADE NOT NULL,
     nickname CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
     UNIQUE (forum, nickname)
 );
 
-create index forum_users_nickname on forum_users using hash (nickname);
-create index forum_users_forum on forum_users using hash (forum);
+create index if not exists forum_users_nickname on forum_users using hash (nickname);
+create index if not exists forum_users_forum on forum_users using hash (forum);
 
 CREATE OR REPLACE FUNCTION insert_votes()
     RETURNS TRIGGER AS
@@ -107,6 +92,7 @@ BEGIN
 END;
 $insert_votes$ language plpgsql;
 
+DROP TRIGGER IF EXISTS insert_votes ON votes;
 CREATE TRIGGER insert_votes
     BEFORE INSERT ON votes FOR EACH ROW
 EXECUTE PROCEDURE insert_votes();
@@ -128,6 +114,7 @@ BEGIN
 END;
 $update_votes$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS update_votes ON votes;
 CREATE TRIGGER update_votes
     BEFORE UPDATE ON votes FOR EACH ROW
 EXECUTE PROCEDURE update_votes();
@@ -156,6 +143,7 @@ BEGIN
 END;
 $post_path$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS post_path ON posts;
 CREATE TRIGGER post_path
     BEFORE INSERT ON posts FOR EACH ROW
-EXECUTE PROCEDURE post_path();
\ No newline at end of file
+EXECUTE PROCEDURE post_path();
@@ -0,0 +1,3 @@
+-- A deleted post stays in place as a tombstone, so that the materialized
+-- paths of its replies remain valid.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS post_revisions;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS last_edited_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS edit_count;
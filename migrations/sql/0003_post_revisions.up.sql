@@ -0,0 +1,14 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS edit_count INT NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS last_edited_at TIMESTAMP WITH TIME ZONE;
+
+-- Every edit keeps the message it replaced. Revision n of a post is its
+-- message before the n-th edit.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS post_revisions
+(
+    post     BIGINT REFERENCES posts (id) ON DELETE CASCADE NOT NULL,
+    revision INT                      NOT NULL,
+    message  TEXT                     NOT NULL,
+    editor   CITEXT REFERENCES users (nickname) ON DELETE SET NULL,
+    created  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (post, revision)
+);
@@ -0,0 +1 @@
+ALTER TABLE threads DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,2 @@
+-- An archived thread is read-only: no new posts, votes or edits.
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,4 @@
+ALTER TABLE threads DROP COLUMN IF EXISTS close_reason;
+ALTER TABLE threads DROP COLUMN IF EXISTS closed_at;
+ALTER TABLE threads DROP COLUMN IF EXISTS pinned;
+ALTER TABLE threads DROP COLUMN IF EXISTS locked;
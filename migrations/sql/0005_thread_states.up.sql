@@ -0,0 +1,6 @@
+-- Locked and closed threads take no new posts or votes; a closed thread
+-- says why. Pinned threads are listed first in their forum.
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS locked BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS close_reason TEXT;
@@ -0,0 +1,20 @@
+-- Password accounts. A user without a row here cannot sign in.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS credentials
+(
+    nickname      CITEXT COLLATE "C" PRIMARY KEY REFERENCES users (nickname) ON DELETE CASCADE,
+    password_hash BYTEA                    NOT NULL,
+    role          TEXT                     NOT NULL DEFAULT 'member',
+    updated       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- Sessions are looked up by the SHA-256 of their bearer token, so that a
+-- leaked table does not leak usable tokens.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS sessions
+(
+    token_hash BYTEA PRIMARY KEY,
+    nickname   CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
+    created    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    expires    TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sessions_nickname ON sessions (nickname);
@@ -0,0 +1,8 @@
+-- Moderators act on the threads and posts of a forum as its owner does.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS forum_moderators
+(
+    forum     CITEXT REFERENCES forums (slug) ON DELETE CASCADE NOT NULL,
+    nickname  CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
+    appointed TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (forum, nickname)
+);
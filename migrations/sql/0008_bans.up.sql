@@ -0,0 +1,14 @@
+-- A ban keeps a user from creating threads, posts and votes in a forum or,
+-- without one, everywhere. It ends at expires, or never.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS bans
+(
+    nickname  CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
+    forum     CITEXT REFERENCES forums (slug) ON DELETE CASCADE,
+    reason    TEXT                     NOT NULL DEFAULT '',
+    banned_by CITEXT REFERENCES users (nickname) ON DELETE SET NULL,
+    created   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    expires   TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS bans_nickname_forum ON bans (nickname, COALESCE(forum, ''));
+CREATE INDEX IF NOT EXISTS bans_forum ON bans (forum);
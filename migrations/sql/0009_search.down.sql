@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS threads_search;
+DROP INDEX IF EXISTS posts_search;
@@ -0,0 +1,8 @@
+-- Full-text search. The queries in repository/postgre.go repeat these
+-- expressions verbatim so that the planner picks the indexes.
+CREATE INDEX IF NOT EXISTS posts_search ON posts
+    USING gin (to_tsvector('english', message));
+
+CREATE INDEX IF NOT EXISTS threads_search ON threads
+    USING gin ((setweight(to_tsvector('english', title::text), 'A') ||
+                setweight(to_tsvector('english', message), 'B')));
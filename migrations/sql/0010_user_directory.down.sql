@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_fullname_trgm;
+DROP INDEX IF EXISTS users_nickname_trgm;
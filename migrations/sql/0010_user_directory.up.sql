@@ -0,0 +1,6 @@
+-- Prefix and fuzzy lookup of users by nickname and full name. Trigram GIN
+-- indexes serve both LIKE 'prefix%' and the % similarity operator.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS users_nickname_trgm ON users USING gin (lower(nickname::text) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_fullname_trgm ON users USING gin (lower(fullname::text) gin_trgm_ops);
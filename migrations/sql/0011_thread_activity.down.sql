@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS threads_forum_activity;
+DROP INDEX IF EXISTS threads_forum_post_count;
+DROP INDEX IF EXISTS threads_forum_votes;
+ALTER TABLE threads DROP COLUMN IF EXISTS last_post_at;
+ALTER TABLE threads DROP COLUMN IF EXISTS post_count;
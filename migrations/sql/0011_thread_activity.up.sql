@@ -0,0 +1,13 @@
+-- Threads keep their live post count and the time of their last post, so
+-- that forum listings can sort by activity without scanning posts.
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS post_count INT NOT NULL DEFAULT 0;
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS last_post_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE threads SET post_count = p.count, last_post_at = p.last
+FROM (SELECT thread, count(*) FILTER (WHERE deleted_at IS NULL) AS count, max(created) AS last
+      FROM posts GROUP BY thread) p
+WHERE threads.id = p.thread;
+
+CREATE INDEX IF NOT EXISTS threads_forum_votes ON threads (forum, pinned, votes, id);
+CREATE INDEX IF NOT EXISTS threads_forum_post_count ON threads (forum, pinned, post_count, id);
+CREATE INDEX IF NOT EXISTS threads_forum_activity ON threads (forum, pinned, (COALESCE(last_post_at, created)), id);
@@ -0,0 +1,16 @@
+-- The log of live events. Servers follow it through LISTEN/NOTIFY on the
+-- subd_events channel, and clients that reconnect resume from it. Events
+-- outlive their threads until they are pruned.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS events
+(
+    id      BIGSERIAL PRIMARY KEY,
+    type    TEXT                     NOT NULL,
+    forum   CITEXT                   NOT NULL,
+    thread  INT                      NOT NULL,
+    data    JSON                     NOT NULL,
+    created TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS events_thread_id ON events (thread, id);
+CREATE INDEX IF NOT EXISTS events_forum_id ON events (forum, id);
+CREATE INDEX IF NOT EXISTS events_created ON events (created);
@@ -0,0 +1,16 @@
+DROP TRIGGER IF EXISTS outbox_votes ON votes;
+DROP TRIGGER IF EXISTS outbox_posts_update ON posts;
+DROP TRIGGER IF EXISTS outbox_posts ON posts;
+DROP TRIGGER IF EXISTS outbox_threads_update ON threads;
+DROP TRIGGER IF EXISTS outbox_threads ON threads;
+DROP TRIGGER IF EXISTS outbox_forums ON forums;
+DROP TRIGGER IF EXISTS outbox_users ON users;
+DROP FUNCTION IF EXISTS outbox_votes();
+DROP FUNCTION IF EXISTS outbox_posts();
+DROP FUNCTION IF EXISTS outbox_threads();
+DROP FUNCTION IF EXISTS outbox_forums();
+DROP FUNCTION IF EXISTS outbox_users();
+DROP FUNCTION IF EXISTS outbox_add(TEXT, CITEXT, JSON);
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS webhooks;
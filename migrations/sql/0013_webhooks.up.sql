@@ -0,0 +1,168 @@
+-- Webhooks post the changes of a forum, or of the whole site when forum is
+-- null, to a URL. An empty events array takes every event type.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS webhooks
+(
+    id      SERIAL PRIMARY KEY,
+    url     TEXT                     NOT NULL,
+    forum   CITEXT REFERENCES forums (slug) ON DELETE CASCADE,
+    events  TEXT[]                   NOT NULL DEFAULT '{}',
+    secret  TEXT                     NOT NULL,
+    created TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- The outbox holds the changes webhooks wait for. Triggers write it in the
+-- transaction of the change, so that no committed change is missed and no
+-- rolled back one is sent; the dispatcher turns it into deliveries.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS outbox
+(
+    id         BIGSERIAL PRIMARY KEY,
+    type       TEXT                     NOT NULL,
+    forum      CITEXT,
+    data       JSON                     NOT NULL,
+    created    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    dispatched BOOLEAN                  NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending ON outbox (id) WHERE NOT dispatched;
+
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS webhook_deliveries
+(
+    id           BIGSERIAL PRIMARY KEY,
+    webhook      INT REFERENCES webhooks (id) ON DELETE CASCADE NOT NULL,
+    event        BIGINT REFERENCES outbox (id) ON DELETE CASCADE NOT NULL,
+    state        TEXT                     NOT NULL DEFAULT 'pending',
+    attempts     INT                      NOT NULL DEFAULT 0,
+    next_attempt TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_status  INT,
+    last_error   TEXT,
+    created      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON webhook_deliveries (next_attempt) WHERE state = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook ON webhook_deliveries (webhook, id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_event ON webhook_deliveries (event);
+
+-- outbox_add writes an event only when a webhook takes it, so that the
+-- triggers cost a lookup while there are none.
+CREATE OR REPLACE FUNCTION outbox_add(event_type TEXT, event_forum CITEXT, event_data JSON)
+    RETURNS VOID AS
+$outbox_add$
+BEGIN
+    IF EXISTS (SELECT 1 FROM webhooks w
+               WHERE (w.forum IS NULL OR w.forum = event_forum)
+                 AND (cardinality(w.events) = 0 OR event_type = ANY (w.events))) THEN
+        INSERT INTO outbox (type, forum, data) VALUES (event_type, event_forum, event_data);
+    END IF;
+END;
+$outbox_add$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION outbox_users()
+    RETURNS TRIGGER AS
+$outbox_users$
+BEGIN
+    PERFORM outbox_add(CASE tg_op WHEN 'INSERT' THEN 'user.created' ELSE 'user.updated' END, NULL,
+        json_build_object('nickname', new.nickname, 'fullname', new.fullname, 'about', new.about,
+            'email', new.email));
+    RETURN NULL;
+END;
+$outbox_users$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS outbox_users ON users;
+CREATE TRIGGER outbox_users
+    AFTER INSERT OR UPDATE ON users FOR EACH ROW
+EXECUTE PROCEDURE outbox_users();
+
+CREATE OR REPLACE FUNCTION outbox_forums()
+    RETURNS TRIGGER AS
+$outbox_forums$
+BEGIN
+    PERFORM outbox_add('forum.created', new.slug,
+        json_build_object('title', new.title, 'user', new.owner, 'posts', new.posts,
+            'threads', new.threads, 'slug', new.slug));
+    RETURN NULL;
+END;
+$outbox_forums$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS outbox_forums ON forums;
+CREATE TRIGGER outbox_forums
+    AFTER INSERT ON forums FOR EACH ROW
+EXECUTE PROCEDURE outbox_forums();
+
+CREATE OR REPLACE FUNCTION outbox_threads()
+    RETURNS TRIGGER AS
+$outbox_threads$
+DECLARE
+    thread threads;
+BEGIN
+    IF tg_op = 'DELETE' THEN
+        thread := old;
+    ELSE
+        thread := new;
+    END IF;
+    PERFORM outbox_add(CASE tg_op WHEN 'INSERT' THEN 'thread.created'
+                                  WHEN 'UPDATE' THEN 'thread.updated'
+                                  ELSE 'thread.deleted' END, thread.forum,
+        json_build_object('id', thread.id, 'author', thread.author, 'created', thread.created,
+            'forum', thread.forum, 'message', thread.message, 'slug', COALESCE(thread.slug, ''),
+            'title', thread.title, 'votes', thread.votes, 'isArchived', thread.archived_at IS NOT NULL,
+            'isLocked', thread.locked, 'isPinned', thread.pinned, 'isClosed', thread.closed_at IS NOT NULL,
+            'closeReason', COALESCE(thread.close_reason, ''), 'posts', thread.post_count));
+    RETURN NULL;
+END;
+$outbox_threads$ LANGUAGE plpgsql;
+
+-- Votes and post counters change threads too; only the changes a client
+-- makes to a thread are events.
+DROP TRIGGER IF EXISTS outbox_threads ON threads;
+CREATE TRIGGER outbox_threads
+    AFTER INSERT OR DELETE ON threads FOR EACH ROW
+EXECUTE PROCEDURE outbox_threads();
+
+DROP TRIGGER IF EXISTS outbox_threads_update ON threads;
+CREATE TRIGGER outbox_threads_update
+    AFTER UPDATE ON threads FOR EACH ROW
+    WHEN ((old.title, old.message, old.slug, old.archived_at, old.locked, old.pinned, old.closed_at, old.close_reason)
+        IS DISTINCT FROM
+          (new.title, new.message, new.slug, new.archived_at, new.locked, new.pinned, new.closed_at, new.close_reason))
+EXECUTE PROCEDURE outbox_threads();
+
+CREATE OR REPLACE FUNCTION outbox_posts()
+    RETURNS TRIGGER AS
+$outbox_posts$
+BEGIN
+    PERFORM outbox_add(CASE tg_op WHEN 'INSERT' THEN 'post.created' ELSE 'post.updated' END, new.forum,
+        json_build_object('author', new.author, 'created', new.created, 'forum', new.forum, 'id', new.id,
+            'isEdited', new.is_edited,
+            'message', CASE WHEN new.deleted_at IS NULL THEN new.message ELSE '' END,
+            'parent', new.parent, 'thread', new.thread, 'isDeleted', new.deleted_at IS NOT NULL,
+            'editCount', new.edit_count, 'lastEditedAt', new.last_edited_at));
+    RETURN NULL;
+END;
+$outbox_posts$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS outbox_posts ON posts;
+CREATE TRIGGER outbox_posts
+    AFTER INSERT ON posts FOR EACH ROW
+EXECUTE PROCEDURE outbox_posts();
+
+DROP TRIGGER IF EXISTS outbox_posts_update ON posts;
+CREATE TRIGGER outbox_posts_update
+    AFTER UPDATE ON posts FOR EACH ROW
+    WHEN ((old.message, old.deleted_at) IS DISTINCT FROM (new.message, new.deleted_at))
+EXECUTE PROCEDURE outbox_posts();
+
+CREATE OR REPLACE FUNCTION outbox_votes()
+    RETURNS TRIGGER AS
+$outbox_votes$
+BEGIN
+    PERFORM outbox_add('vote.cast', (SELECT forum FROM threads WHERE id = new.thread),
+        json_build_object('thread', new.thread, 'nickname', new.nickname, 'voice', new.voice));
+    RETURN NULL;
+END;
+$outbox_votes$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS outbox_votes ON votes;
+CREATE TRIGGER outbox_votes
+    AFTER INSERT OR UPDATE ON votes FOR EACH ROW
+EXECUTE PROCEDURE outbox_votes();
@@ -0,0 +1,28 @@
+-- Users subscribed to a forum are notified of its new threads.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS forum_subscriptions
+(
+    forum    CITEXT REFERENCES forums (slug) ON DELETE CASCADE NOT NULL,
+    nickname CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
+    created  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (forum, nickname)
+);
+
+-- Notifications are written along with the posts and threads they are
+-- about: replies, mentions and new threads in subscribed forums.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS notifications
+(
+    id       BIGSERIAL PRIMARY KEY,
+    nickname CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
+    type     TEXT                     NOT NULL,
+    actor    CITEXT                   NOT NULL,
+    forum    CITEXT                   NOT NULL,
+    thread   INT REFERENCES threads (id) ON DELETE CASCADE NOT NULL,
+    post     BIGINT REFERENCES posts (id) ON DELETE CASCADE,
+    created  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    read_at  TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS notifications_nickname ON notifications (nickname, id);
+CREATE INDEX IF NOT EXISTS notifications_unread ON notifications (nickname, id) WHERE read_at IS NULL;
+CREATE INDEX IF NOT EXISTS notifications_thread ON notifications (thread);
+CREATE INDEX IF NOT EXISTS notifications_post ON notifications (post);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS thread_reads;
+DROP INDEX IF EXISTS forum_subscriptions_nickname;
+DROP TABLE IF EXISTS thread_subscriptions;
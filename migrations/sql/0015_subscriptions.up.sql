@@ -0,0 +1,23 @@
+-- Users follow threads as they follow forums, and keep the last post they
+-- read in each thread whether they follow it or not.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS thread_subscriptions
+(
+    thread   INT REFERENCES threads (id) ON DELETE CASCADE NOT NULL,
+    nickname CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
+    created  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (thread, nickname)
+);
+
+CREATE INDEX IF NOT EXISTS thread_subscriptions_nickname ON thread_subscriptions (nickname);
+CREATE INDEX IF NOT EXISTS forum_subscriptions_nickname ON forum_subscriptions (nickname);
+
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS thread_reads
+(
+    nickname  CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
+    thread    INT REFERENCES threads (id) ON DELETE CASCADE NOT NULL,
+    last_read BIGINT                   NOT NULL,
+    updated   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (nickname, thread)
+);
+
+CREATE INDEX IF NOT EXISTS thread_reads_thread ON thread_reads (thread);
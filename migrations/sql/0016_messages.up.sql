@@ -0,0 +1,44 @@
+-- Private conversations between a few users. last_message is the id of
+-- the latest message, by which a user's conversations are listed.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS conversations
+(
+    id           SERIAL PRIMARY KEY,
+    title        TEXT                     NOT NULL DEFAULT '',
+    creator      CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
+    last_message BIGINT                   NOT NULL DEFAULT 0,
+    created      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- Every participant keeps the last message they read.
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS conversation_participants
+(
+    conversation INT REFERENCES conversations (id) ON DELETE CASCADE NOT NULL,
+    nickname     CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
+    last_read    BIGINT                   NOT NULL DEFAULT 0,
+    joined       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (conversation, nickname)
+);
+
+CREATE INDEX IF NOT EXISTS conversation_participants_nickname ON conversation_participants (nickname);
+
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS messages
+(
+    id           BIGSERIAL PRIMARY KEY,
+    conversation INT REFERENCES conversations (id) ON DELETE CASCADE NOT NULL,
+    author       CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
+    message      TEXT                     NOT NULL,
+    created      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    edited_at    TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS messages_conversation ON messages (conversation, id);
+
+CREATE {{.Unlogged}}TABLE IF NOT EXISTS blocks
+(
+    nickname CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
+    blocked  CITEXT COLLATE "C" REFERENCES users (nickname) ON DELETE CASCADE NOT NULL,
+    created  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (nickname, blocked)
+);
+
+CREATE INDEX IF NOT EXISTS blocks_blocked ON blocks (blocked);
@@ -0,0 +1,2 @@
+DROP FUNCTION IF EXISTS events_add(TEXT, CITEXT, INT, JSON);
+ALTER TABLE events DROP COLUMN IF EXISTS horizon;
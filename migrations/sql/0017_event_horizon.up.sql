@@ -0,0 +1,27 @@
+-- Events are written in the transaction of the change they describe, so
+-- they may commit out of id order. horizon tells a reader following the log
+-- by id when a gap before an event can no longer be filled: once every
+-- transaction below it has ended.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS horizon BIGINT NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION events_add(event_type TEXT, event_forum CITEXT, event_thread INT, event_data JSON)
+    RETURNS events AS
+$events_add$
+DECLARE
+    event events;
+BEGIN
+    -- The transaction gets its id before the event does, and the horizon is
+    -- read after, from a fresh snapshot: whoever drew a smaller event id and
+    -- has yet to commit runs a transaction below the horizon.
+    PERFORM txid_current();
+    event.id := nextval(pg_get_serial_sequence('events', 'id'));
+    event.horizon := txid_snapshot_xmax(txid_current_snapshot());
+
+    INSERT INTO events (id, type, forum, thread, data, horizon)
+    VALUES (event.id, event_type, event_forum, event_thread, event_data, event.horizon)
+    RETURNING * INTO event;
+    PERFORM pg_notify('subd_events', event.id::text);
+
+    RETURN event;
+END;
+$events_add$ LANGUAGE plpgsql;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS threads_forum_pinned_created_desc;
+DROP INDEX IF EXISTS threads_forum_pinned_created;
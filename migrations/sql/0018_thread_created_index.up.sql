@@ -0,0 +1,5 @@
+-- The default forum listing orders by pinned descending, then by creation
+-- and id either way; the index of 0001 on (forum, created) serves neither.
+-- A backward scan of the second index gives the descending order.
+CREATE INDEX IF NOT EXISTS threads_forum_pinned_created ON threads (forum, pinned DESC, created, id);
+CREATE INDEX IF NOT EXISTS threads_forum_pinned_created_desc ON threads (forum, pinned, created, id);
@@ -4,6 +4,7 @@ package models
 
 import (
 	json "encoding/json"
+	strfmt "github.com/go-openapi/strfmt"
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
@@ -17,7 +18,7 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjsonD2b7633eDecodeSubdModels(in *jlexer.Lexer, out *Vote) {
+func easyjsonD2b7633eDecodeSubdModels(in *jlexer.Lexer, out *Webhook) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -36,10 +37,41 @@ func easyjsonD2b7633eDecodeSubdModels(in *jlexer.Lexer, out *Vote) {
 			continue
 		}
 		switch key {
-		case "nickname":
-			out.Nickname = string(in.String())
-		case "voice":
-			out.Voice = int(in.Int())
+		case "id":
+			out.Id = int(in.Int())
+		case "url":
+			out.Url = string(in.String())
+		case "forum":
+			out.Forum = string(in.String())
+		case "events":
+			if in.IsNull() {
+				in.Skip()
+				out.Events = nil
+			} else {
+				in.Delim('[')
+				if out.Events == nil {
+					if !in.IsDelim(']') {
+						out.Events = make([]string, 0, 4)
+					} else {
+						out.Events = []string{}
+					}
+				} else {
+					out.Events = (out.Events)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.Events = append(out.Events, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "secret":
+			out.Secret = string(in.String())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -50,113 +82,151 @@ func easyjsonD2b7633eDecodeSubdModels(in *jlexer.Lexer, out *Vote) {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeSubdModels(out *jwriter.Writer, in Vote) {
+func easyjsonD2b7633eEncodeSubdModels(out *jwriter.Writer, in Webhook) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"nickname\":"
+		const prefix string = ",\"id\":"
 		out.RawString(prefix[1:])
-		out.String(string(in.Nickname))
+		out.Int(int(in.Id))
 	}
 	{
-		const prefix string = ",\"voice\":"
+		const prefix string = ",\"url\":"
 		out.RawString(prefix)
-		out.Int(int(in.Voice))
+		out.String(string(in.Url))
+	}
+	if in.Forum != "" {
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		out.String(string(in.Forum))
+	}
+	{
+		const prefix string = ",\"events\":"
+		out.RawString(prefix)
+		if in.Events == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Events {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Secret != "" {
+		const prefix string = ",\"secret\":"
+		out.RawString(prefix)
+		out.String(string(in.Secret))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Vote) MarshalJSON() ([]byte, error) {
+func (v Webhook) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonD2b7633eEncodeSubdModels(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Vote) MarshalEasyJSON(w *jwriter.Writer) {
+func (v Webhook) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonD2b7633eEncodeSubdModels(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Vote) UnmarshalJSON(data []byte) error {
+func (v *Webhook) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonD2b7633eDecodeSubdModels(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Vote) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *Webhook) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeSubdModels(l, v)
 }
-func easyjsonD2b7633eDecodeSubdModels1(in *jlexer.Lexer, out *Users) {
+func easyjsonD2b7633eDecodeSubdModels1(in *jlexer.Lexer, out *Vote) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
-		in.Skip()
-		*out = nil
-	} else {
-		in.Delim('[')
-		if *out == nil {
-			if !in.IsDelim(']') {
-				*out = make(Users, 0, 1)
-			} else {
-				*out = Users{}
-			}
-		} else {
-			*out = (*out)[:0]
+		if isTopLevel {
+			in.Consumed()
 		}
-		for !in.IsDelim(']') {
-			var v1 User
-			(v1).UnmarshalEasyJSON(in)
-			*out = append(*out, v1)
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
 			in.WantComma()
+			continue
 		}
-		in.Delim(']')
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "voice":
+			out.Voice = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
 	}
+	in.Delim('}')
 	if isTopLevel {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeSubdModels1(out *jwriter.Writer, in Users) {
-	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
-		out.RawString("null")
-	} else {
-		out.RawByte('[')
-		for v2, v3 := range in {
-			if v2 > 0 {
-				out.RawByte(',')
-			}
-			(v3).MarshalEasyJSON(out)
-		}
-		out.RawByte(']')
+func easyjsonD2b7633eEncodeSubdModels1(out *jwriter.Writer, in Vote) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"voice\":"
+		out.RawString(prefix)
+		out.Int(int(in.Voice))
 	}
+	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Users) MarshalJSON() ([]byte, error) {
+func (v Vote) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonD2b7633eEncodeSubdModels1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Users) MarshalEasyJSON(w *jwriter.Writer) {
+func (v Vote) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonD2b7633eEncodeSubdModels1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Users) UnmarshalJSON(data []byte) error {
+func (v *Vote) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonD2b7633eDecodeSubdModels1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Users) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *Vote) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeSubdModels1(l, v)
 }
-func easyjsonD2b7633eDecodeSubdModels2(in *jlexer.Lexer, out *User) {
+func easyjsonD2b7633eDecodeSubdModels2(in *jlexer.Lexer, out *UsersPage) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -175,16 +245,10 @@ func easyjsonD2b7633eDecodeSubdModels2(in *jlexer.Lexer, out *User) {
 			continue
 		}
 		switch key {
-		case "nickname":
-			out.Nickname = string(in.String())
-		case "fullname":
-			out.Fullname = string(in.String())
-		case "about":
-			out.About = string(in.String())
-		case "email":
-			if data := in.Raw(); in.Ok() {
-				in.AddError((out.Email).UnmarshalJSON(data))
-			}
+		case "users":
+			(out.Users).UnmarshalEasyJSON(in)
+		case "next":
+			out.Next = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -195,57 +259,47 @@ func easyjsonD2b7633eDecodeSubdModels2(in *jlexer.Lexer, out *User) {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeSubdModels2(out *jwriter.Writer, in User) {
+func easyjsonD2b7633eEncodeSubdModels2(out *jwriter.Writer, in UsersPage) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"nickname\":"
+		const prefix string = ",\"users\":"
 		out.RawString(prefix[1:])
-		out.String(string(in.Nickname))
-	}
-	{
-		const prefix string = ",\"fullname\":"
-		out.RawString(prefix)
-		out.String(string(in.Fullname))
-	}
-	{
-		const prefix string = ",\"about\":"
-		out.RawString(prefix)
-		out.String(string(in.About))
+		(in.Users).MarshalEasyJSON(out)
 	}
-	{
-		const prefix string = ",\"email\":"
+	if in.Next != "" {
+		const prefix string = ",\"next\":"
 		out.RawString(prefix)
-		out.Raw((in.Email).MarshalJSON())
+		out.String(string(in.Next))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v User) MarshalJSON() ([]byte, error) {
+func (v UsersPage) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonD2b7633eEncodeSubdModels2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v User) MarshalEasyJSON(w *jwriter.Writer) {
+func (v UsersPage) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonD2b7633eEncodeSubdModels2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *User) UnmarshalJSON(data []byte) error {
+func (v *UsersPage) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonD2b7633eDecodeSubdModels2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *User) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *UsersPage) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeSubdModels2(l, v)
 }
-func easyjsonD2b7633eDecodeSubdModels3(in *jlexer.Lexer, out *Threads) {
+func easyjsonD2b7633eDecodeSubdModels3(in *jlexer.Lexer, out *Users) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -254,15 +308,15 @@ func easyjsonD2b7633eDecodeSubdModels3(in *jlexer.Lexer, out *Threads) {
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(Threads, 0, 0)
+				*out = make(Users, 0, 1)
 			} else {
-				*out = Threads{}
+				*out = Users{}
 			}
 		} else {
 			*out = (*out)[:0]
 		}
 		for !in.IsDelim(']') {
-			var v4 Thread
+			var v4 User
 			(v4).UnmarshalEasyJSON(in)
 			*out = append(*out, v4)
 			in.WantComma()
@@ -273,7 +327,7 @@ func easyjsonD2b7633eDecodeSubdModels3(in *jlexer.Lexer, out *Threads) {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeSubdModels3(out *jwriter.Writer, in Threads) {
+func easyjsonD2b7633eEncodeSubdModels3(out *jwriter.Writer, in Users) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
@@ -289,29 +343,29 @@ func easyjsonD2b7633eEncodeSubdModels3(out *jwriter.Writer, in Threads) {
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Threads) MarshalJSON() ([]byte, error) {
+func (v Users) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonD2b7633eEncodeSubdModels3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Threads) MarshalEasyJSON(w *jwriter.Writer) {
+func (v Users) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonD2b7633eEncodeSubdModels3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Threads) UnmarshalJSON(data []byte) error {
+func (v *Users) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonD2b7633eDecodeSubdModels3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Threads) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *Users) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeSubdModels3(l, v)
 }
-func easyjsonD2b7633eDecodeSubdModels4(in *jlexer.Lexer, out *Thread) {
+func easyjsonD2b7633eDecodeSubdModels4(in *jlexer.Lexer, out *User) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -330,24 +384,16 @@ func easyjsonD2b7633eDecodeSubdModels4(in *jlexer.Lexer, out *Thread) {
 			continue
 		}
 		switch key {
-		case "id":
-			out.Id = uint64(in.Uint64())
-		case "author":
-			out.Author = string(in.String())
-		case "created":
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "fullname":
+			out.Fullname = string(in.String())
+		case "about":
+			out.About = string(in.String())
+		case "email":
 			if data := in.Raw(); in.Ok() {
-				in.AddError((out.Created).UnmarshalJSON(data))
+				in.AddError((out.Email).UnmarshalJSON(data))
 			}
-		case "forum":
-			out.Forum = string(in.String())
-		case "message":
-			out.Message = string(in.String())
-		case "slug":
-			out.Slug = string(in.String())
-		case "title":
-			out.Title = string(in.String())
-		case "votes":
-			out.Votes = int(in.Int())
 		default:
 			in.SkipRecursive()
 		}
@@ -358,77 +404,57 @@ func easyjsonD2b7633eDecodeSubdModels4(in *jlexer.Lexer, out *Thread) {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeSubdModels4(out *jwriter.Writer, in Thread) {
+func easyjsonD2b7633eEncodeSubdModels4(out *jwriter.Writer, in User) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"id\":"
+		const prefix string = ",\"nickname\":"
 		out.RawString(prefix[1:])
-		out.Uint64(uint64(in.Id))
-	}
-	{
-		const prefix string = ",\"author\":"
-		out.RawString(prefix)
-		out.String(string(in.Author))
-	}
-	{
-		const prefix string = ",\"created\":"
-		out.RawString(prefix)
-		out.Raw((in.Created).MarshalJSON())
-	}
-	{
-		const prefix string = ",\"forum\":"
-		out.RawString(prefix)
-		out.String(string(in.Forum))
-	}
-	{
-		const prefix string = ",\"message\":"
-		out.RawString(prefix)
-		out.String(string(in.Message))
+		out.String(string(in.Nickname))
 	}
 	{
-		const prefix string = ",\"slug\":"
+		const prefix string = ",\"fullname\":"
 		out.RawString(prefix)
-		out.String(string(in.Slug))
+		out.String(string(in.Fullname))
 	}
 	{
-		const prefix string = ",\"title\":"
+		const prefix string = ",\"about\":"
 		out.RawString(prefix)
-		out.String(string(in.Title))
+		out.String(string(in.About))
 	}
 	{
-		const prefix string = ",\"votes\":"
+		const prefix string = ",\"email\":"
 		out.RawString(prefix)
-		out.Int(int(in.Votes))
+		out.Raw((in.Email).MarshalJSON())
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Thread) MarshalJSON() ([]byte, error) {
+func (v User) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonD2b7633eEncodeSubdModels4(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Thread) MarshalEasyJSON(w *jwriter.Writer) {
+func (v User) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonD2b7633eEncodeSubdModels4(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Thread) UnmarshalJSON(data []byte) error {
+func (v *User) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonD2b7633eDecodeSubdModels4(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Thread) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *User) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeSubdModels4(l, v)
 }
-func easyjsonD2b7633eDecodeSubdModels5(in *jlexer.Lexer, out *Status) {
+func easyjsonD2b7633eDecodeSubdModels5(in *jlexer.Lexer, out *UnreadNotifications) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -447,14 +473,24 @@ func easyjsonD2b7633eDecodeSubdModels5(in *jlexer.Lexer, out *Status) {
 			continue
 		}
 		switch key {
-		case "forum":
-			out.Forum = uint64(in.Uint64())
-		case "post":
-			out.Post = uint64(in.Uint64())
-		case "thread":
-			out.Thread = uint64(in.Uint64())
-		case "user":
-			out.User = uint64(in.Uint64())
+		case "unread":
+			out.Unread = int(in.Int())
+		case "byType":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.ByType = make(map[string]int)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v7 int
+					v7 = int(in.Int())
+					(out.ByType)[key] = v7
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -465,57 +501,63 @@ func easyjsonD2b7633eDecodeSubdModels5(in *jlexer.Lexer, out *Status) {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeSubdModels5(out *jwriter.Writer, in Status) {
+func easyjsonD2b7633eEncodeSubdModels5(out *jwriter.Writer, in UnreadNotifications) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"forum\":"
+		const prefix string = ",\"unread\":"
 		out.RawString(prefix[1:])
-		out.Uint64(uint64(in.Forum))
-	}
-	{
-		const prefix string = ",\"post\":"
-		out.RawString(prefix)
-		out.Uint64(uint64(in.Post))
-	}
-	{
-		const prefix string = ",\"thread\":"
-		out.RawString(prefix)
-		out.Uint64(uint64(in.Thread))
+		out.Int(int(in.Unread))
 	}
 	{
-		const prefix string = ",\"user\":"
+		const prefix string = ",\"byType\":"
 		out.RawString(prefix)
-		out.Uint64(uint64(in.User))
+		if in.ByType == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v8First := true
+			for v8Name, v8Value := range in.ByType {
+				if v8First {
+					v8First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v8Name))
+				out.RawByte(':')
+				out.Int(int(v8Value))
+			}
+			out.RawByte('}')
+		}
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Status) MarshalJSON() ([]byte, error) {
+func (v UnreadNotifications) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonD2b7633eEncodeSubdModels5(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Status) MarshalEasyJSON(w *jwriter.Writer) {
+func (v UnreadNotifications) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonD2b7633eEncodeSubdModels5(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Status) UnmarshalJSON(data []byte) error {
+func (v *UnreadNotifications) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonD2b7633eDecodeSubdModels5(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Status) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *UnreadNotifications) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeSubdModels5(l, v)
 }
-func easyjsonD2b7633eDecodeSubdModels6(in *jlexer.Lexer, out *Posts) {
+func easyjsonD2b7633eDecodeSubdModels6(in *jlexer.Lexer, out *Threads) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -524,17 +566,17 @@ func easyjsonD2b7633eDecodeSubdModels6(in *jlexer.Lexer, out *Posts) {
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(Posts, 0, 0)
+				*out = make(Threads, 0, 0)
 			} else {
-				*out = Posts{}
+				*out = Threads{}
 			}
 		} else {
 			*out = (*out)[:0]
 		}
 		for !in.IsDelim(']') {
-			var v7 Post
-			(v7).UnmarshalEasyJSON(in)
-			*out = append(*out, v7)
+			var v9 Thread
+			(v9).UnmarshalEasyJSON(in)
+			*out = append(*out, v9)
 			in.WantComma()
 		}
 		in.Delim(']')
@@ -543,45 +585,45 @@ func easyjsonD2b7633eDecodeSubdModels6(in *jlexer.Lexer, out *Posts) {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeSubdModels6(out *jwriter.Writer, in Posts) {
+func easyjsonD2b7633eEncodeSubdModels6(out *jwriter.Writer, in Threads) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
 		out.RawByte('[')
-		for v8, v9 := range in {
-			if v8 > 0 {
+		for v10, v11 := range in {
+			if v10 > 0 {
 				out.RawByte(',')
 			}
-			(v9).MarshalEasyJSON(out)
+			(v11).MarshalEasyJSON(out)
 		}
 		out.RawByte(']')
 	}
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Posts) MarshalJSON() ([]byte, error) {
+func (v Threads) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonD2b7633eEncodeSubdModels6(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Posts) MarshalEasyJSON(w *jwriter.Writer) {
+func (v Threads) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonD2b7633eEncodeSubdModels6(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Posts) UnmarshalJSON(data []byte) error {
+func (v *Threads) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonD2b7633eDecodeSubdModels6(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Posts) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *Threads) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeSubdModels6(l, v)
 }
-func easyjsonD2b7633eDecodeSubdModels7(in *jlexer.Lexer, out *PostNullMessage) {
+func easyjsonD2b7633eDecodeSubdModels7(in *jlexer.Lexer, out *ThreadSubscription) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -600,22 +642,22 @@ func easyjsonD2b7633eDecodeSubdModels7(in *jlexer.Lexer, out *PostNullMessage) {
 			continue
 		}
 		switch key {
-		case "author":
-			out.Author = string(in.String())
+		case "thread":
+			out.Thread = int(in.Int())
+		case "slug":
+			out.Slug = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "forum":
+			out.Forum = string(in.String())
+		case "lastRead":
+			out.LastRead = int(in.Int())
+		case "unread":
+			out.Unread = int(in.Int())
 		case "created":
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.Created).UnmarshalJSON(data))
 			}
-		case "forum":
-			out.Forum = string(in.String())
-		case "id":
-			out.Id = int(in.Int())
-		case "message":
-			out.Message = string(in.String())
-		case "parent":
-			out.Parent = int(in.Int())
-		case "thread":
-			out.Thread = int(in.Int())
 		default:
 			in.SkipRecursive()
 		}
@@ -626,72 +668,72 @@ func easyjsonD2b7633eDecodeSubdModels7(in *jlexer.Lexer, out *PostNullMessage) {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeSubdModels7(out *jwriter.Writer, in PostNullMessage) {
+func easyjsonD2b7633eEncodeSubdModels7(out *jwriter.Writer, in ThreadSubscription) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"author\":"
+		const prefix string = ",\"thread\":"
 		out.RawString(prefix[1:])
-		out.String(string(in.Author))
+		out.Int(int(in.Thread))
 	}
-	{
-		const prefix string = ",\"created\":"
+	if in.Slug != "" {
+		const prefix string = ",\"slug\":"
 		out.RawString(prefix)
-		out.Raw((in.Created).MarshalJSON())
+		out.String(string(in.Slug))
 	}
 	{
-		const prefix string = ",\"forum\":"
+		const prefix string = ",\"title\":"
 		out.RawString(prefix)
-		out.String(string(in.Forum))
+		out.String(string(in.Title))
 	}
 	{
-		const prefix string = ",\"id\":"
+		const prefix string = ",\"forum\":"
 		out.RawString(prefix)
-		out.Int(int(in.Id))
+		out.String(string(in.Forum))
 	}
 	{
-		const prefix string = ",\"message\":"
+		const prefix string = ",\"lastRead\":"
 		out.RawString(prefix)
-		out.String(string(in.Message))
+		out.Int(int(in.LastRead))
 	}
 	{
-		const prefix string = ",\"parent\":"
+		const prefix string = ",\"unread\":"
 		out.RawString(prefix)
-		out.Int(int(in.Parent))
+		out.Int(int(in.Unread))
 	}
 	{
-		const prefix string = ",\"thread\":"
+		const prefix string = ",\"created\":"
 		out.RawString(prefix)
-		out.Int(int(in.Thread))
+		out.Raw((in.Created).MarshalJSON())
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v PostNullMessage) MarshalJSON() ([]byte, error) {
+func (v ThreadSubscription) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonD2b7633eEncodeSubdModels7(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v PostNullMessage) MarshalEasyJSON(w *jwriter.Writer) {
+func (v ThreadSubscription) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonD2b7633eEncodeSubdModels7(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *PostNullMessage) UnmarshalJSON(data []byte) error {
+func (v *ThreadSubscription) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonD2b7633eDecodeSubdModels7(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *PostNullMessage) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *ThreadSubscription) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeSubdModels7(l, v)
 }
-func easyjsonD2b7633eDecodeSubdModels8(in *jlexer.Lexer, out *Post) {
+func easyjsonD2b7633eDecodeSubdModels8(in *jlexer.Lexer, out *ThreadRead) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -710,24 +752,14 @@ func easyjsonD2b7633eDecodeSubdModels8(in *jlexer.Lexer, out *Post) {
 			continue
 		}
 		switch key {
-		case "author":
-			out.Author = string(in.String())
-		case "created":
-			if data := in.Raw(); in.Ok() {
-				in.AddError((out.Created).UnmarshalJSON(data))
-			}
-		case "forum":
-			out.Forum = string(in.String())
-		case "id":
-			out.Id = int(in.Int())
-		case "isEdited":
-			out.IsEdited = bool(in.Bool())
-		case "message":
-			out.Message = string(in.String())
-		case "parent":
-			out.Parent = int(in.Int())
 		case "thread":
 			out.Thread = int(in.Int())
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "lastRead":
+			out.LastRead = int(in.Int())
+		case "unread":
+			out.Unread = int(in.Int())
 		default:
 			in.SkipRecursive()
 		}
@@ -738,77 +770,57 @@ func easyjsonD2b7633eDecodeSubdModels8(in *jlexer.Lexer, out *Post) {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeSubdModels8(out *jwriter.Writer, in Post) {
+func easyjsonD2b7633eEncodeSubdModels8(out *jwriter.Writer, in ThreadRead) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"author\":"
+		const prefix string = ",\"thread\":"
 		out.RawString(prefix[1:])
-		out.String(string(in.Author))
-	}
-	{
-		const prefix string = ",\"created\":"
-		out.RawString(prefix)
-		out.Raw((in.Created).MarshalJSON())
-	}
-	{
-		const prefix string = ",\"forum\":"
-		out.RawString(prefix)
-		out.String(string(in.Forum))
-	}
-	{
-		const prefix string = ",\"id\":"
-		out.RawString(prefix)
-		out.Int(int(in.Id))
-	}
-	{
-		const prefix string = ",\"isEdited\":"
-		out.RawString(prefix)
-		out.Bool(bool(in.IsEdited))
+		out.Int(int(in.Thread))
 	}
 	{
-		const prefix string = ",\"message\":"
+		const prefix string = ",\"nickname\":"
 		out.RawString(prefix)
-		out.String(string(in.Message))
+		out.String(string(in.Nickname))
 	}
 	{
-		const prefix string = ",\"parent\":"
+		const prefix string = ",\"lastRead\":"
 		out.RawString(prefix)
-		out.Int(int(in.Parent))
+		out.Int(int(in.LastRead))
 	}
 	{
-		const prefix string = ",\"thread\":"
+		const prefix string = ",\"unread\":"
 		out.RawString(prefix)
-		out.Int(int(in.Thread))
+		out.Int(int(in.Unread))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Post) MarshalJSON() ([]byte, error) {
+func (v ThreadRead) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonD2b7633eEncodeSubdModels8(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Post) MarshalEasyJSON(w *jwriter.Writer) {
+func (v ThreadRead) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonD2b7633eEncodeSubdModels8(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Post) UnmarshalJSON(data []byte) error {
+func (v *ThreadRead) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonD2b7633eDecodeSubdModels8(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Post) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *ThreadRead) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeSubdModels8(l, v)
 }
-func easyjsonD2b7633eDecodeSubdModels9(in *jlexer.Lexer, out *NewMessage) {
+func easyjsonD2b7633eDecodeSubdModels9(in *jlexer.Lexer, out *ThreadModeration) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -827,8 +839,38 @@ func easyjsonD2b7633eDecodeSubdModels9(in *jlexer.Lexer, out *NewMessage) {
 			continue
 		}
 		switch key {
-		case "message":
-			out.Message = string(in.String())
+		case "locked":
+			if in.IsNull() {
+				in.Skip()
+				out.Locked = nil
+			} else {
+				if out.Locked == nil {
+					out.Locked = new(bool)
+				}
+				*out.Locked = bool(in.Bool())
+			}
+		case "pinned":
+			if in.IsNull() {
+				in.Skip()
+				out.Pinned = nil
+			} else {
+				if out.Pinned == nil {
+					out.Pinned = new(bool)
+				}
+				*out.Pinned = bool(in.Bool())
+			}
+		case "closed":
+			if in.IsNull() {
+				in.Skip()
+				out.Closed = nil
+			} else {
+				if out.Closed == nil {
+					out.Closed = new(bool)
+				}
+				*out.Closed = bool(in.Bool())
+			}
+		case "reason":
+			out.Reason = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -839,42 +881,73 @@ func easyjsonD2b7633eDecodeSubdModels9(in *jlexer.Lexer, out *NewMessage) {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeSubdModels9(out *jwriter.Writer, in NewMessage) {
+func easyjsonD2b7633eEncodeSubdModels9(out *jwriter.Writer, in ThreadModeration) {
 	out.RawByte('{')
 	first := true
 	_ = first
-	{
-		const prefix string = ",\"message\":"
+	if in.Locked != nil {
+		const prefix string = ",\"locked\":"
+		first = false
 		out.RawString(prefix[1:])
-		out.String(string(in.Message))
+		out.Bool(bool(*in.Locked))
+	}
+	if in.Pinned != nil {
+		const prefix string = ",\"pinned\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(*in.Pinned))
+	}
+	if in.Closed != nil {
+		const prefix string = ",\"closed\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(*in.Closed))
+	}
+	if in.Reason != "" {
+		const prefix string = ",\"reason\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Reason))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v NewMessage) MarshalJSON() ([]byte, error) {
+func (v ThreadModeration) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonD2b7633eEncodeSubdModels9(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v NewMessage) MarshalEasyJSON(w *jwriter.Writer) {
+func (v ThreadModeration) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonD2b7633eEncodeSubdModels9(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *NewMessage) UnmarshalJSON(data []byte) error {
+func (v *ThreadModeration) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonD2b7633eDecodeSubdModels9(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *NewMessage) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *ThreadModeration) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeSubdModels9(l, v)
 }
-func easyjsonD2b7633eDecodeSubdModels10(in *jlexer.Lexer, out *FullPost) {
+func easyjsonD2b7633eDecodeSubdModels10(in *jlexer.Lexer, out *Thread) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -893,45 +966,47 @@ func easyjsonD2b7633eDecodeSubdModels10(in *jlexer.Lexer, out *FullPost) {
 			continue
 		}
 		switch key {
+		case "id":
+			out.Id = uint64(in.Uint64())
 		case "author":
-			if in.IsNull() {
-				in.Skip()
-				out.Author = nil
-			} else {
-				if out.Author == nil {
-					out.Author = new(User)
-				}
-				(*out.Author).UnmarshalEasyJSON(in)
+			out.Author = string(in.String())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
 			}
 		case "forum":
+			out.Forum = string(in.String())
+		case "message":
+			out.Message = string(in.String())
+		case "slug":
+			out.Slug = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "votes":
+			out.Votes = int(in.Int())
+		case "isArchived":
+			out.IsArchived = bool(in.Bool())
+		case "isLocked":
+			out.IsLocked = bool(in.Bool())
+		case "isPinned":
+			out.IsPinned = bool(in.Bool())
+		case "isClosed":
+			out.IsClosed = bool(in.Bool())
+		case "closeReason":
+			out.CloseReason = string(in.String())
+		case "posts":
+			out.PostCount = int(in.Int())
+		case "lastPostAt":
 			if in.IsNull() {
 				in.Skip()
-				out.Forum = nil
-			} else {
-				if out.Forum == nil {
-					out.Forum = new(Forum)
-				}
-				(*out.Forum).UnmarshalEasyJSON(in)
-			}
-		case "post":
-			if in.IsNull() {
-				in.Skip()
-				out.Post = nil
+				out.LastPostAt = nil
 			} else {
-				if out.Post == nil {
-					out.Post = new(Post)
+				if out.LastPostAt == nil {
+					out.LastPostAt = new(strfmt.DateTime)
 				}
-				(*out.Post).UnmarshalEasyJSON(in)
-			}
-		case "thread":
-			if in.IsNull() {
-				in.Skip()
-				out.Thread = nil
-			} else {
-				if out.Thread == nil {
-					out.Thread = new(Thread)
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.LastPostAt).UnmarshalJSON(data))
 				}
-				(*out.Thread).UnmarshalEasyJSON(in)
 			}
 		default:
 			in.SkipRecursive()
@@ -943,73 +1018,3145 @@ func easyjsonD2b7633eDecodeSubdModels10(in *jlexer.Lexer, out *FullPost) {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeSubdModels10(out *jwriter.Writer, in FullPost) {
+func easyjsonD2b7633eEncodeSubdModels10(out *jwriter.Writer, in Thread) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"author\":"
+		const prefix string = ",\"id\":"
 		out.RawString(prefix[1:])
-		if in.Author == nil {
-			out.RawString("null")
-		} else {
-			(*in.Author).MarshalEasyJSON(out)
-		}
+		out.Uint64(uint64(in.Id))
+	}
+	{
+		const prefix string = ",\"author\":"
+		out.RawString(prefix)
+		out.String(string(in.Author))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
 	}
 	{
 		const prefix string = ",\"forum\":"
 		out.RawString(prefix)
-		if in.Forum == nil {
-			out.RawString("null")
-		} else {
-			(*in.Forum).MarshalEasyJSON(out)
-		}
+		out.String(string(in.Forum))
 	}
 	{
-		const prefix string = ",\"post\":"
+		const prefix string = ",\"message\":"
 		out.RawString(prefix)
-		if in.Post == nil {
-			out.RawString("null")
-		} else {
-			(*in.Post).MarshalEasyJSON(out)
-		}
+		out.String(string(in.Message))
 	}
 	{
-		const prefix string = ",\"thread\":"
+		const prefix string = ",\"slug\":"
 		out.RawString(prefix)
-		if in.Thread == nil {
-			out.RawString("null")
-		} else {
-			(*in.Thread).MarshalEasyJSON(out)
-		}
+		out.String(string(in.Slug))
 	}
-	out.RawByte('}')
-}
-
-// MarshalJSON supports json.Marshaler interface
-func (v FullPost) MarshalJSON() ([]byte, error) {
-	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeSubdModels10(&w, v)
-	return w.Buffer.BuildBytes(), w.Error
-}
-
-// MarshalEasyJSON supports easyjson.Marshaler interface
-func (v FullPost) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeSubdModels10(w, v)
-}
-
-// UnmarshalJSON supports json.Unmarshaler interface
-func (v *FullPost) UnmarshalJSON(data []byte) error {
-	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeSubdModels10(&r, v)
-	return r.Error()
-}
+	{
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"votes\":"
+		out.RawString(prefix)
+		out.Int(int(in.Votes))
+	}
+	if in.IsArchived {
+		const prefix string = ",\"isArchived\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsArchived))
+	}
+	if in.IsLocked {
+		const prefix string = ",\"isLocked\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsLocked))
+	}
+	if in.IsPinned {
+		const prefix string = ",\"isPinned\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsPinned))
+	}
+	if in.IsClosed {
+		const prefix string = ",\"isClosed\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsClosed))
+	}
+	if in.CloseReason != "" {
+		const prefix string = ",\"closeReason\":"
+		out.RawString(prefix)
+		out.String(string(in.CloseReason))
+	}
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix)
+		out.Int(int(in.PostCount))
+	}
+	if in.LastPostAt != nil {
+		const prefix string = ",\"lastPostAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.LastPostAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Thread) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels10(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Thread) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels10(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Thread) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels10(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Thread) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels10(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels11(in *jlexer.Lexer, out *Subscriptions) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "forums":
+			if in.IsNull() {
+				in.Skip()
+				out.Forums = nil
+			} else {
+				in.Delim('[')
+				if out.Forums == nil {
+					if !in.IsDelim(']') {
+						out.Forums = make([]ForumSubscription, 0, 1)
+					} else {
+						out.Forums = []ForumSubscription{}
+					}
+				} else {
+					out.Forums = (out.Forums)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v12 ForumSubscription
+					(v12).UnmarshalEasyJSON(in)
+					out.Forums = append(out.Forums, v12)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "threads":
+			if in.IsNull() {
+				in.Skip()
+				out.Threads = nil
+			} else {
+				in.Delim('[')
+				if out.Threads == nil {
+					if !in.IsDelim(']') {
+						out.Threads = make([]ThreadSubscription, 0, 0)
+					} else {
+						out.Threads = []ThreadSubscription{}
+					}
+				} else {
+					out.Threads = (out.Threads)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v13 ThreadSubscription
+					(v13).UnmarshalEasyJSON(in)
+					out.Threads = append(out.Threads, v13)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels11(out *jwriter.Writer, in Subscriptions) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"forums\":"
+		out.RawString(prefix[1:])
+		if in.Forums == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v14, v15 := range in.Forums {
+				if v14 > 0 {
+					out.RawByte(',')
+				}
+				(v15).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"threads\":"
+		out.RawString(prefix)
+		if in.Threads == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v16, v17 := range in.Threads {
+				if v16 > 0 {
+					out.RawByte(',')
+				}
+				(v17).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Subscriptions) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels11(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Subscriptions) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels11(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Subscriptions) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels11(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Subscriptions) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels11(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels12(in *jlexer.Lexer, out *Status) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "forum":
+			out.Forum = uint64(in.Uint64())
+		case "post":
+			out.Post = uint64(in.Uint64())
+		case "thread":
+			out.Thread = uint64(in.Uint64())
+		case "user":
+			out.User = uint64(in.Uint64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels12(out *jwriter.Writer, in Status) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix[1:])
+		out.Uint64(uint64(in.Forum))
+	}
+	{
+		const prefix string = ",\"post\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Post))
+	}
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Thread))
+	}
+	{
+		const prefix string = ",\"user\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.User))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Status) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels12(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Status) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels12(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Status) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels12(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Status) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels12(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels13(in *jlexer.Lexer, out *Signup) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "password":
+			out.Password = string(in.String())
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "fullname":
+			out.Fullname = string(in.String())
+		case "about":
+			out.About = string(in.String())
+		case "email":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Email).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels13(out *jwriter.Writer, in Signup) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Password != "" {
+		const prefix string = ",\"password\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.String(string(in.Password))
+	}
+	{
+		const prefix string = ",\"nickname\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"fullname\":"
+		out.RawString(prefix)
+		out.String(string(in.Fullname))
+	}
+	{
+		const prefix string = ",\"about\":"
+		out.RawString(prefix)
+		out.String(string(in.About))
+	}
+	{
+		const prefix string = ",\"email\":"
+		out.RawString(prefix)
+		out.Raw((in.Email).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Signup) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels13(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Signup) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels13(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Signup) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels13(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Signup) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels13(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels14(in *jlexer.Lexer, out *Session) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "token":
+			out.Token = string(in.String())
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "expires":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Expires).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels14(out *jwriter.Writer, in Session) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"token\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Token))
+	}
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix)
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"expires\":"
+		out.RawString(prefix)
+		out.Raw((in.Expires).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Session) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels14(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Session) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels14(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Session) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels14(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Session) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels14(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels15(in *jlexer.Lexer, out *SearchResult) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "hits":
+			(out.Hits).UnmarshalEasyJSON(in)
+		case "next":
+			out.Next = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels15(out *jwriter.Writer, in SearchResult) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"hits\":"
+		out.RawString(prefix[1:])
+		(in.Hits).MarshalEasyJSON(out)
+	}
+	if in.Next != "" {
+		const prefix string = ",\"next\":"
+		out.RawString(prefix)
+		out.String(string(in.Next))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SearchResult) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels15(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SearchResult) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels15(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SearchResult) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels15(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SearchResult) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels15(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels16(in *jlexer.Lexer, out *SearchHits) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(SearchHits, 0, 0)
+			} else {
+				*out = SearchHits{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v18 SearchHit
+			(v18).UnmarshalEasyJSON(in)
+			*out = append(*out, v18)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels16(out *jwriter.Writer, in SearchHits) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v19, v20 := range in {
+			if v19 > 0 {
+				out.RawByte(',')
+			}
+			(v20).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SearchHits) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels16(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SearchHits) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels16(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SearchHits) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels16(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SearchHits) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels16(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels17(in *jlexer.Lexer, out *SearchHit) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "kind":
+			out.Kind = string(in.String())
+		case "id":
+			out.Id = int64(in.Int64())
+		case "thread":
+			out.Thread = int64(in.Int64())
+		case "forum":
+			out.Forum = string(in.String())
+		case "author":
+			out.Author = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "snippet":
+			out.Snippet = string(in.String())
+		case "rank":
+			out.Rank = float32(in.Float32())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels17(out *jwriter.Writer, in SearchHit) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"kind\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Kind))
+	}
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Id))
+	}
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Thread))
+	}
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		out.String(string(in.Forum))
+	}
+	{
+		const prefix string = ",\"author\":"
+		out.RawString(prefix)
+		out.String(string(in.Author))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"snippet\":"
+		out.RawString(prefix)
+		out.String(string(in.Snippet))
+	}
+	{
+		const prefix string = ",\"rank\":"
+		out.RawString(prefix)
+		out.Float32(float32(in.Rank))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SearchHit) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels17(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SearchHit) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels17(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SearchHit) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels17(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SearchHit) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels17(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels18(in *jlexer.Lexer, out *RoleChange) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "role":
+			out.Role = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels18(out *jwriter.Writer, in RoleChange) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"role\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Role))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RoleChange) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels18(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RoleChange) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels18(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RoleChange) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels18(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RoleChange) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels18(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels19(in *jlexer.Lexer, out *Revisions) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Revisions, 0, 1)
+			} else {
+				*out = Revisions{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v21 Revision
+			(v21).UnmarshalEasyJSON(in)
+			*out = append(*out, v21)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels19(out *jwriter.Writer, in Revisions) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v22, v23 := range in {
+			if v22 > 0 {
+				out.RawByte(',')
+			}
+			(v23).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Revisions) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels19(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Revisions) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels19(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Revisions) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels19(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Revisions) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels19(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels20(in *jlexer.Lexer, out *RevisionDiff) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "post":
+			out.Post = int(in.Int())
+		case "from":
+			out.From = int(in.Int())
+		case "to":
+			out.To = int(in.Int())
+		case "lines":
+			if in.IsNull() {
+				in.Skip()
+				out.Lines = nil
+			} else {
+				in.Delim('[')
+				if out.Lines == nil {
+					if !in.IsDelim(']') {
+						out.Lines = make([]DiffLine, 0, 2)
+					} else {
+						out.Lines = []DiffLine{}
+					}
+				} else {
+					out.Lines = (out.Lines)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v24 DiffLine
+					(v24).UnmarshalEasyJSON(in)
+					out.Lines = append(out.Lines, v24)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels20(out *jwriter.Writer, in RevisionDiff) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"post\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Post))
+	}
+	{
+		const prefix string = ",\"from\":"
+		out.RawString(prefix)
+		out.Int(int(in.From))
+	}
+	{
+		const prefix string = ",\"to\":"
+		out.RawString(prefix)
+		out.Int(int(in.To))
+	}
+	{
+		const prefix string = ",\"lines\":"
+		out.RawString(prefix)
+		if in.Lines == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v25, v26 := range in.Lines {
+				if v25 > 0 {
+					out.RawByte(',')
+				}
+				(v26).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RevisionDiff) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels20(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RevisionDiff) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels20(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RevisionDiff) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels20(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RevisionDiff) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels20(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels21(in *jlexer.Lexer, out *Revision) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "revision":
+			out.Revision = int(in.Int())
+		case "message":
+			out.Message = string(in.String())
+		case "editor":
+			out.Editor = string(in.String())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels21(out *jwriter.Writer, in Revision) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"revision\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Revision))
+	}
+	{
+		const prefix string = ",\"message\":"
+		out.RawString(prefix)
+		out.String(string(in.Message))
+	}
+	if in.Editor != "" {
+		const prefix string = ",\"editor\":"
+		out.RawString(prefix)
+		out.String(string(in.Editor))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Revision) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels21(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Revision) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels21(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Revision) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels21(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Revision) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels21(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels22(in *jlexer.Lexer, out *ReadMarker) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "post":
+			out.Post = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels22(out *jwriter.Writer, in ReadMarker) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"post\":"
+		out.RawString(prefix)
+		out.Int(int(in.Post))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ReadMarker) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels22(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ReadMarker) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels22(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ReadMarker) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels22(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ReadMarker) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels22(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels23(in *jlexer.Lexer, out *PrivateMessage) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.Id = int64(in.Int64())
+		case "conversation":
+			out.Conversation = int(in.Int())
+		case "author":
+			out.Author = string(in.String())
+		case "message":
+			out.Message = string(in.String())
+		case "isEdited":
+			out.IsEdited = bool(in.Bool())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		case "editedAt":
+			if in.IsNull() {
+				in.Skip()
+				out.EditedAt = nil
+			} else {
+				if out.EditedAt == nil {
+					out.EditedAt = new(strfmt.DateTime)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.EditedAt).UnmarshalJSON(data))
+				}
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels23(out *jwriter.Writer, in PrivateMessage) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Id))
+	}
+	{
+		const prefix string = ",\"conversation\":"
+		out.RawString(prefix)
+		out.Int(int(in.Conversation))
+	}
+	{
+		const prefix string = ",\"author\":"
+		out.RawString(prefix)
+		out.String(string(in.Author))
+	}
+	{
+		const prefix string = ",\"message\":"
+		out.RawString(prefix)
+		out.String(string(in.Message))
+	}
+	{
+		const prefix string = ",\"isEdited\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsEdited))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	if in.EditedAt != nil {
+		const prefix string = ",\"editedAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.EditedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PrivateMessage) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels23(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PrivateMessage) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels23(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PrivateMessage) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels23(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PrivateMessage) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels23(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels24(in *jlexer.Lexer, out *Posts) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Posts, 0, 0)
+			} else {
+				*out = Posts{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v27 Post
+			(v27).UnmarshalEasyJSON(in)
+			*out = append(*out, v27)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels24(out *jwriter.Writer, in Posts) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v28, v29 := range in {
+			if v28 > 0 {
+				out.RawByte(',')
+			}
+			(v29).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Posts) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels24(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Posts) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels24(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Posts) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels24(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Posts) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels24(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels25(in *jlexer.Lexer, out *PostNullMessage) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "author":
+			out.Author = string(in.String())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		case "forum":
+			out.Forum = string(in.String())
+		case "id":
+			out.Id = int(in.Int())
+		case "message":
+			out.Message = string(in.String())
+		case "parent":
+			out.Parent = int(in.Int())
+		case "thread":
+			out.Thread = int(in.Int())
+		case "isDeleted":
+			out.IsDeleted = bool(in.Bool())
+		case "editCount":
+			out.EditCount = int(in.Int())
+		case "lastEditedAt":
+			if in.IsNull() {
+				in.Skip()
+				out.LastEditedAt = nil
+			} else {
+				if out.LastEditedAt == nil {
+					out.LastEditedAt = new(strfmt.DateTime)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.LastEditedAt).UnmarshalJSON(data))
+				}
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels25(out *jwriter.Writer, in PostNullMessage) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"author\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Author))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		out.String(string(in.Forum))
+	}
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix)
+		out.Int(int(in.Id))
+	}
+	{
+		const prefix string = ",\"message\":"
+		out.RawString(prefix)
+		out.String(string(in.Message))
+	}
+	{
+		const prefix string = ",\"parent\":"
+		out.RawString(prefix)
+		out.Int(int(in.Parent))
+	}
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		out.Int(int(in.Thread))
+	}
+	if in.IsDeleted {
+		const prefix string = ",\"isDeleted\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsDeleted))
+	}
+	if in.EditCount != 0 {
+		const prefix string = ",\"editCount\":"
+		out.RawString(prefix)
+		out.Int(int(in.EditCount))
+	}
+	if in.LastEditedAt != nil {
+		const prefix string = ",\"lastEditedAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.LastEditedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PostNullMessage) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels25(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PostNullMessage) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels25(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PostNullMessage) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels25(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PostNullMessage) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels25(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels26(in *jlexer.Lexer, out *Post) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "author":
+			out.Author = string(in.String())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		case "forum":
+			out.Forum = string(in.String())
+		case "id":
+			out.Id = int(in.Int())
+		case "isEdited":
+			out.IsEdited = bool(in.Bool())
+		case "message":
+			out.Message = string(in.String())
+		case "parent":
+			out.Parent = int(in.Int())
+		case "thread":
+			out.Thread = int(in.Int())
+		case "isDeleted":
+			out.IsDeleted = bool(in.Bool())
+		case "editCount":
+			out.EditCount = int(in.Int())
+		case "lastEditedAt":
+			if in.IsNull() {
+				in.Skip()
+				out.LastEditedAt = nil
+			} else {
+				if out.LastEditedAt == nil {
+					out.LastEditedAt = new(strfmt.DateTime)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.LastEditedAt).UnmarshalJSON(data))
+				}
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels26(out *jwriter.Writer, in Post) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"author\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Author))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		out.String(string(in.Forum))
+	}
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix)
+		out.Int(int(in.Id))
+	}
+	{
+		const prefix string = ",\"isEdited\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsEdited))
+	}
+	{
+		const prefix string = ",\"message\":"
+		out.RawString(prefix)
+		out.String(string(in.Message))
+	}
+	{
+		const prefix string = ",\"parent\":"
+		out.RawString(prefix)
+		out.Int(int(in.Parent))
+	}
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		out.Int(int(in.Thread))
+	}
+	if in.IsDeleted {
+		const prefix string = ",\"isDeleted\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsDeleted))
+	}
+	if in.EditCount != 0 {
+		const prefix string = ",\"editCount\":"
+		out.RawString(prefix)
+		out.Int(int(in.EditCount))
+	}
+	if in.LastEditedAt != nil {
+		const prefix string = ",\"lastEditedAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.LastEditedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Post) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels26(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Post) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels26(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Post) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels26(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Post) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels26(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels27(in *jlexer.Lexer, out *Participant) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "lastRead":
+			out.LastRead = int64(in.Int64())
+		case "joined":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Joined).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels27(out *jwriter.Writer, in Participant) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"lastRead\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.LastRead))
+	}
+	{
+		const prefix string = ",\"joined\":"
+		out.RawString(prefix)
+		out.Raw((in.Joined).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Participant) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels27(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Participant) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels27(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Participant) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels27(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Participant) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels27(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels28(in *jlexer.Lexer, out *NotificationsPage) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "notifications":
+			if in.IsNull() {
+				in.Skip()
+				out.Notifications = nil
+			} else {
+				in.Delim('[')
+				if out.Notifications == nil {
+					if !in.IsDelim(']') {
+						out.Notifications = make(Notifications, 0, 0)
+					} else {
+						out.Notifications = Notifications{}
+					}
+				} else {
+					out.Notifications = (out.Notifications)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v30 Notification
+					(v30).UnmarshalEasyJSON(in)
+					out.Notifications = append(out.Notifications, v30)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "unread":
+			out.Unread = int(in.Int())
+		case "next":
+			out.Next = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels28(out *jwriter.Writer, in NotificationsPage) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"notifications\":"
+		out.RawString(prefix[1:])
+		if in.Notifications == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v31, v32 := range in.Notifications {
+				if v31 > 0 {
+					out.RawByte(',')
+				}
+				(v32).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"unread\":"
+		out.RawString(prefix)
+		out.Int(int(in.Unread))
+	}
+	if in.Next != "" {
+		const prefix string = ",\"next\":"
+		out.RawString(prefix)
+		out.String(string(in.Next))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v NotificationsPage) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels28(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v NotificationsPage) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels28(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *NotificationsPage) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels28(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *NotificationsPage) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels28(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels29(in *jlexer.Lexer, out *NotificationsMarked) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "marked":
+			out.Marked = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels29(out *jwriter.Writer, in NotificationsMarked) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"marked\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Marked))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v NotificationsMarked) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels29(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v NotificationsMarked) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels29(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *NotificationsMarked) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels29(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *NotificationsMarked) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels29(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels30(in *jlexer.Lexer, out *Notification) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.Id = int64(in.Int64())
+		case "type":
+			out.Type = string(in.String())
+		case "actor":
+			out.Actor = string(in.String())
+		case "forum":
+			out.Forum = string(in.String())
+		case "thread":
+			out.Thread = int(in.Int())
+		case "post":
+			out.Post = int(in.Int())
+		case "read":
+			out.Read = bool(in.Bool())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels30(out *jwriter.Writer, in Notification) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Id))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.Type))
+	}
+	{
+		const prefix string = ",\"actor\":"
+		out.RawString(prefix)
+		out.String(string(in.Actor))
+	}
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		out.String(string(in.Forum))
+	}
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		out.Int(int(in.Thread))
+	}
+	if in.Post != 0 {
+		const prefix string = ",\"post\":"
+		out.RawString(prefix)
+		out.Int(int(in.Post))
+	}
+	{
+		const prefix string = ",\"read\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Read))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Notification) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels30(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Notification) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels30(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Notification) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels30(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Notification) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels30(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels31(in *jlexer.Lexer, out *NewMessage) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "message":
+			out.Message = string(in.String())
+		case "editor":
+			out.Editor = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels31(out *jwriter.Writer, in NewMessage) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"message\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Message))
+	}
+	if in.Editor != "" {
+		const prefix string = ",\"editor\":"
+		out.RawString(prefix)
+		out.String(string(in.Editor))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v NewMessage) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels31(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v NewMessage) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels31(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *NewMessage) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels31(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *NewMessage) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels31(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels32(in *jlexer.Lexer, out *NewConversation) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "participants":
+			if in.IsNull() {
+				in.Skip()
+				out.Participants = nil
+			} else {
+				in.Delim('[')
+				if out.Participants == nil {
+					if !in.IsDelim(']') {
+						out.Participants = make([]string, 0, 4)
+					} else {
+						out.Participants = []string{}
+					}
+				} else {
+					out.Participants = (out.Participants)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v33 string
+					v33 = string(in.String())
+					out.Participants = append(out.Participants, v33)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "title":
+			out.Title = string(in.String())
+		case "message":
+			out.Message = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels32(out *jwriter.Writer, in NewConversation) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"participants\":"
+		out.RawString(prefix[1:])
+		if in.Participants == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v34, v35 := range in.Participants {
+				if v34 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v35))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"message\":"
+		out.RawString(prefix)
+		out.String(string(in.Message))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v NewConversation) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels32(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v NewConversation) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels32(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *NewConversation) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels32(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *NewConversation) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels32(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels33(in *jlexer.Lexer, out *Login) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "password":
+			out.Password = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels33(out *jwriter.Writer, in Login) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"password\":"
+		out.RawString(prefix)
+		out.String(string(in.Password))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Login) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels33(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Login) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels33(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Login) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels33(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Login) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels33(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels34(in *jlexer.Lexer, out *HookEvent) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.Id = int64(in.Int64())
+		case "type":
+			out.Type = string(in.String())
+		case "forum":
+			out.Forum = string(in.String())
+		case "data":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Data).UnmarshalJSON(data))
+			}
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels34(out *jwriter.Writer, in HookEvent) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Id))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.Type))
+	}
+	if in.Forum != "" {
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		out.String(string(in.Forum))
+	}
+	{
+		const prefix string = ",\"data\":"
+		out.RawString(prefix)
+		out.Raw((in.Data).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HookEvent) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels34(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HookEvent) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels34(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HookEvent) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels34(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HookEvent) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels34(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels35(in *jlexer.Lexer, out *FullPost) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "author":
+			if in.IsNull() {
+				in.Skip()
+				out.Author = nil
+			} else {
+				if out.Author == nil {
+					out.Author = new(User)
+				}
+				(*out.Author).UnmarshalEasyJSON(in)
+			}
+		case "forum":
+			if in.IsNull() {
+				in.Skip()
+				out.Forum = nil
+			} else {
+				if out.Forum == nil {
+					out.Forum = new(Forum)
+				}
+				(*out.Forum).UnmarshalEasyJSON(in)
+			}
+		case "post":
+			if in.IsNull() {
+				in.Skip()
+				out.Post = nil
+			} else {
+				if out.Post == nil {
+					out.Post = new(Post)
+				}
+				(*out.Post).UnmarshalEasyJSON(in)
+			}
+		case "thread":
+			if in.IsNull() {
+				in.Skip()
+				out.Thread = nil
+			} else {
+				if out.Thread == nil {
+					out.Thread = new(Thread)
+				}
+				(*out.Thread).UnmarshalEasyJSON(in)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels35(out *jwriter.Writer, in FullPost) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"author\":"
+		out.RawString(prefix[1:])
+		if in.Author == nil {
+			out.RawString("null")
+		} else {
+			(*in.Author).MarshalEasyJSON(out)
+		}
+	}
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		if in.Forum == nil {
+			out.RawString("null")
+		} else {
+			(*in.Forum).MarshalEasyJSON(out)
+		}
+	}
+	{
+		const prefix string = ",\"post\":"
+		out.RawString(prefix)
+		if in.Post == nil {
+			out.RawString("null")
+		} else {
+			(*in.Post).MarshalEasyJSON(out)
+		}
+	}
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		if in.Thread == nil {
+			out.RawString("null")
+		} else {
+			(*in.Thread).MarshalEasyJSON(out)
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v FullPost) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels35(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v FullPost) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels35(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *FullPost) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels35(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *FullPost) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels35(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels36(in *jlexer.Lexer, out *ForumSubscription) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "forum":
+			out.Forum = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "unread":
+			out.Unread = int(in.Int())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels36(out *jwriter.Writer, in ForumSubscription) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Forum))
+	}
+	{
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"unread\":"
+		out.RawString(prefix)
+		out.Int(int(in.Unread))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ForumSubscription) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels36(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ForumSubscription) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels36(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ForumSubscription) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels36(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ForumSubscription) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels36(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels37(in *jlexer.Lexer, out *Forum) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "title":
+			out.Title = string(in.String())
+		case "user":
+			out.Owner = string(in.String())
+		case "posts":
+			out.Posts = uint64(in.Uint64())
+		case "threads":
+			out.Threads = uint64(in.Uint64())
+		case "slug":
+			out.Slug = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels37(out *jwriter.Writer, in Forum) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"title\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"user\":"
+		out.RawString(prefix)
+		out.String(string(in.Owner))
+	}
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Posts))
+	}
+	{
+		const prefix string = ",\"threads\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Threads))
+	}
+	{
+		const prefix string = ",\"slug\":"
+		out.RawString(prefix)
+		out.String(string(in.Slug))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Forum) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels37(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Forum) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels37(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Forum) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels37(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Forum) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels37(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels38(in *jlexer.Lexer, out *Event) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.Id = int64(in.Int64())
+		case "type":
+			out.Type = string(in.String())
+		case "forum":
+			out.Forum = string(in.String())
+		case "thread":
+			out.Thread = int(in.Int())
+		case "data":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Data).UnmarshalJSON(data))
+			}
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels38(out *jwriter.Writer, in Event) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Id))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.Type))
+	}
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		out.String(string(in.Forum))
+	}
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		out.Int(int(in.Thread))
+	}
+	{
+		const prefix string = ",\"data\":"
+		out.RawString(prefix)
+		out.Raw((in.Data).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Event) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels38(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Event) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels38(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Event) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels38(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Event) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels38(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels39(in *jlexer.Lexer, out *DiffLine) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "op":
+			out.Op = string(in.String())
+		case "text":
+			out.Text = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels39(out *jwriter.Writer, in DiffLine) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"op\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Op))
+	}
+	{
+		const prefix string = ",\"text\":"
+		out.RawString(prefix)
+		out.String(string(in.Text))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v DiffLine) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels39(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v DiffLine) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels39(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *DiffLine) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels39(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *DiffLine) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels39(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels40(in *jlexer.Lexer, out *Delivery) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.Id = int64(in.Int64())
+		case "webhook":
+			out.Webhook = int(in.Int())
+		case "event":
+			out.Event = int64(in.Int64())
+		case "type":
+			out.Type = string(in.String())
+		case "state":
+			out.State = string(in.String())
+		case "attempts":
+			out.Attempts = int(in.Int())
+		case "nextAttempt":
+			if in.IsNull() {
+				in.Skip()
+				out.NextAttempt = nil
+			} else {
+				if out.NextAttempt == nil {
+					out.NextAttempt = new(strfmt.DateTime)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.NextAttempt).UnmarshalJSON(data))
+				}
+			}
+		case "lastStatus":
+			out.LastStatus = int(in.Int())
+		case "lastError":
+			out.LastError = string(in.String())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		case "deliveredAt":
+			if in.IsNull() {
+				in.Skip()
+				out.DeliveredAt = nil
+			} else {
+				if out.DeliveredAt == nil {
+					out.DeliveredAt = new(strfmt.DateTime)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.DeliveredAt).UnmarshalJSON(data))
+				}
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels40(out *jwriter.Writer, in Delivery) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Id))
+	}
+	{
+		const prefix string = ",\"webhook\":"
+		out.RawString(prefix)
+		out.Int(int(in.Webhook))
+	}
+	{
+		const prefix string = ",\"event\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Event))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.Type))
+	}
+	{
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		out.String(string(in.State))
+	}
+	{
+		const prefix string = ",\"attempts\":"
+		out.RawString(prefix)
+		out.Int(int(in.Attempts))
+	}
+	if in.NextAttempt != nil {
+		const prefix string = ",\"nextAttempt\":"
+		out.RawString(prefix)
+		out.Raw((*in.NextAttempt).MarshalJSON())
+	}
+	if in.LastStatus != 0 {
+		const prefix string = ",\"lastStatus\":"
+		out.RawString(prefix)
+		out.Int(int(in.LastStatus))
+	}
+	if in.LastError != "" {
+		const prefix string = ",\"lastError\":"
+		out.RawString(prefix)
+		out.String(string(in.LastError))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	if in.DeliveredAt != nil {
+		const prefix string = ",\"deliveredAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.DeliveredAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Delivery) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels40(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Delivery) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels40(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Delivery) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels40(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Delivery) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels40(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels41(in *jlexer.Lexer, out *ConversationRead) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "conversation":
+			out.Conversation = int(in.Int())
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "lastRead":
+			out.LastRead = int64(in.Int64())
+		case "unread":
+			out.Unread = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels41(out *jwriter.Writer, in ConversationRead) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"conversation\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Conversation))
+	}
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix)
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"lastRead\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.LastRead))
+	}
+	{
+		const prefix string = ",\"unread\":"
+		out.RawString(prefix)
+		out.Int(int(in.Unread))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ConversationRead) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels41(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ConversationRead) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels41(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ConversationRead) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels41(&r, v)
+	return r.Error()
+}
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *FullPost) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeSubdModels10(l, v)
+func (v *ConversationRead) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels41(l, v)
 }
-func easyjsonD2b7633eDecodeSubdModels11(in *jlexer.Lexer, out *Forum) {
+func easyjsonD2b7633eDecodeSubdModels42(in *jlexer.Lexer, out *Conversation) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -1028,16 +4175,43 @@ func easyjsonD2b7633eDecodeSubdModels11(in *jlexer.Lexer, out *Forum) {
 			continue
 		}
 		switch key {
+		case "id":
+			out.Id = int(in.Int())
 		case "title":
 			out.Title = string(in.String())
-		case "user":
-			out.Owner = string(in.String())
-		case "posts":
-			out.Posts = uint64(in.Uint64())
-		case "threads":
-			out.Threads = uint64(in.Uint64())
-		case "slug":
-			out.Slug = string(in.String())
+		case "creator":
+			out.Creator = string(in.String())
+		case "participants":
+			if in.IsNull() {
+				in.Skip()
+				out.Participants = nil
+			} else {
+				in.Delim('[')
+				if out.Participants == nil {
+					if !in.IsDelim(']') {
+						out.Participants = make([]Participant, 0, 1)
+					} else {
+						out.Participants = []Participant{}
+					}
+				} else {
+					out.Participants = (out.Participants)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v36 Participant
+					(v36).UnmarshalEasyJSON(in)
+					out.Participants = append(out.Participants, v36)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "lastMessage":
+			out.LastMessage = int64(in.Int64())
+		case "unread":
+			out.Unread = int(in.Int())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -1048,58 +4222,340 @@ func easyjsonD2b7633eDecodeSubdModels11(in *jlexer.Lexer, out *Forum) {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeSubdModels11(out *jwriter.Writer, in Forum) {
+func easyjsonD2b7633eEncodeSubdModels42(out *jwriter.Writer, in Conversation) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"title\":"
+		const prefix string = ",\"id\":"
 		out.RawString(prefix[1:])
+		out.Int(int(in.Id))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
 		out.String(string(in.Title))
 	}
 	{
-		const prefix string = ",\"user\":"
+		const prefix string = ",\"creator\":"
 		out.RawString(prefix)
-		out.String(string(in.Owner))
+		out.String(string(in.Creator))
 	}
 	{
-		const prefix string = ",\"posts\":"
+		const prefix string = ",\"participants\":"
 		out.RawString(prefix)
-		out.Uint64(uint64(in.Posts))
+		if in.Participants == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v37, v38 := range in.Participants {
+				if v37 > 0 {
+					out.RawByte(',')
+				}
+				(v38).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
 	}
 	{
-		const prefix string = ",\"threads\":"
+		const prefix string = ",\"lastMessage\":"
 		out.RawString(prefix)
-		out.Uint64(uint64(in.Threads))
+		out.Int64(int64(in.LastMessage))
 	}
 	{
-		const prefix string = ",\"slug\":"
+		const prefix string = ",\"unread\":"
 		out.RawString(prefix)
-		out.String(string(in.Slug))
+		out.Int(int(in.Unread))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Forum) MarshalJSON() ([]byte, error) {
+func (v Conversation) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeSubdModels11(&w, v)
+	easyjsonD2b7633eEncodeSubdModels42(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Forum) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeSubdModels11(w, v)
+func (v Conversation) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels42(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Forum) UnmarshalJSON(data []byte) error {
+func (v *Conversation) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeSubdModels11(&r, v)
+	easyjsonD2b7633eDecodeSubdModels42(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Forum) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeSubdModels11(l, v)
+func (v *Conversation) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels42(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels43(in *jlexer.Lexer, out *Block) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "blocked":
+			out.Blocked = string(in.String())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels43(out *jwriter.Writer, in Block) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"blocked\":"
+		out.RawString(prefix)
+		out.String(string(in.Blocked))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Block) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels43(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Block) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels43(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Block) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels43(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Block) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels43(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels44(in *jlexer.Lexer, out *Bans) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Bans, 0, 0)
+			} else {
+				*out = Bans{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v39 Ban
+			(v39).UnmarshalEasyJSON(in)
+			*out = append(*out, v39)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels44(out *jwriter.Writer, in Bans) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v40, v41 := range in {
+			if v40 > 0 {
+				out.RawByte(',')
+			}
+			(v41).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Bans) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels44(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Bans) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels44(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Bans) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels44(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Bans) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels44(l, v)
+}
+func easyjsonD2b7633eDecodeSubdModels45(in *jlexer.Lexer, out *Ban) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "forum":
+			out.Forum = string(in.String())
+		case "reason":
+			out.Reason = string(in.String())
+		case "bannedBy":
+			out.BannedBy = string(in.String())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		case "expires":
+			if in.IsNull() {
+				in.Skip()
+				out.Expires = nil
+			} else {
+				if out.Expires == nil {
+					out.Expires = new(strfmt.DateTime)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.Expires).UnmarshalJSON(data))
+				}
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeSubdModels45(out *jwriter.Writer, in Ban) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	if in.Forum != "" {
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		out.String(string(in.Forum))
+	}
+	if in.Reason != "" {
+		const prefix string = ",\"reason\":"
+		out.RawString(prefix)
+		out.String(string(in.Reason))
+	}
+	if in.BannedBy != "" {
+		const prefix string = ",\"bannedBy\":"
+		out.RawString(prefix)
+		out.String(string(in.BannedBy))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	if in.Expires != nil {
+		const prefix string = ",\"expires\":"
+		out.RawString(prefix)
+		out.Raw((*in.Expires).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Ban) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeSubdModels45(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Ban) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeSubdModels45(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Ban) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeSubdModels45(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Ban) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeSubdModels45(l, v)
 }
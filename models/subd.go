@@ -2,12 +2,18 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
 	"github.com/go-openapi/strfmt"
+	"regexp"
+	"strings"
+	"time"
 )
 
+//go:generate easyjson -all -output_filename models_easyjson.go subd.go
+
 type Vote struct {
-	Nickname string `json:"nickname"`
-	Voice int `json:"voice"`
+	Nickname string `json:"nickname" valid:"required,nickname"`
+	Voice int `json:"voice" valid:"required,in(-1|1)"`
 }
 
 type PostNullMessage struct {
@@ -18,14 +24,17 @@ type PostNullMessage struct {
 	Message  string          `json:"message"`
 	Parent   int           `json:"parent"`
 	Thread   int           `json:"thread"`
+	IsDeleted bool           `json:"isDeleted,omitempty"`
+	EditCount int            `json:"editCount,omitempty"`
+	LastEditedAt *strfmt.DateTime `json:"lastEditedAt,omitempty"`
 }
 
 type Forum struct {
-	Title string `json:"title"`
-	Owner string `json:"user"`
+	Title string `json:"title" valid:"required,runelength(1|256)"`
+	Owner string `json:"user" valid:"required,nickname"`
 	Posts uint64 `json:"posts"`
 	Threads uint64 `json:"threads"`
-	Slug string `json:"slug"`
+	Slug string `json:"slug" valid:"required,slug,runelength(1|128)"`
 }
 
 type FullPost struct {
@@ -43,20 +52,665 @@ type Status struct {
 }
 
 type NewMessage struct {
-	Message string `json:"message"`
+	Message string `json:"message" valid:"runelength(1|65536)"`
+	// Editor is recorded with the revision the edit creates.
+	Editor string `json:"editor,omitempty" valid:"nickname"`
 }
 
 type Post struct {
-	Author   string          `json:"author"`
+	Author   string          `json:"author" valid:"required,nickname"`
 	Created  strfmt.DateTime `json:"created"`
 	Forum    string          `json:"forum"`
 	Id       int           `json:"id"`
 	IsEdited bool            `json:"isEdited"`
-	Message  string          `json:"message"`
+	Message  string          `json:"message" valid:"required,runelength(1|65536)"`
 	Parent   int           `json:"parent"`
 	Thread   int           `json:"thread"`
+	// IsDeleted marks a tombstone: the post keeps its place in the thread
+	// tree but its message is hidden.
+	IsDeleted bool           `json:"isDeleted,omitempty"`
+	EditCount int            `json:"editCount,omitempty"`
+	LastEditedAt *strfmt.DateTime `json:"lastEditedAt,omitempty"`
+}
+
+// Revision is a message a post had before an edit, with the user who made
+// the edit and when.
+type Revision struct {
+	Revision int             `json:"revision"`
+	Message  string          `json:"message"`
+	Editor   string          `json:"editor,omitempty"`
+	Created  strfmt.DateTime `json:"created"`
+}
+
+//easyjson:json
+type Revisions []Revision
+
+// Ban keeps a user from creating threads, posts and votes in Forum or,
+// when it is empty, everywhere, until Expires if set.
+type Ban struct {
+	Nickname string           `json:"nickname"`
+	Forum    string           `json:"forum,omitempty"`
+	Reason   string           `json:"reason,omitempty" valid:"runelength(0|1000)"`
+	BannedBy string           `json:"bannedBy,omitempty"`
+	Created  strfmt.DateTime  `json:"created"`
+	Expires  *strfmt.DateTime `json:"expires,omitempty"`
+}
+
+//easyjson:json
+type Bans []Ban
+
+// Kinds of search hits.
+const (
+	HitPost   = "post"
+	HitThread = "thread"
+)
+
+// SearchQuery is a full-text search with its filters; empty filters match
+// everything.
+//easyjson:skip
+type SearchQuery struct {
+	Text   string `json:"q" valid:"required,runelength(1|256)"`
+	Kind   string `json:"type"`
+	Forum  string `json:"forum"`
+	Author string `json:"author"`
+	Thread int    `json:"thread"`
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	After  *SearchKey
+}
+
+// SearchKey is the position of a hit in the results, which are ordered by
+// descending rank, kind and id.
+//easyjson:skip
+type SearchKey struct {
+	Rank float32 `json:"r"`
+	Kind string  `json:"k"`
+	Id   int64   `json:"i"`
+}
+
+// SearchHit is a post or a thread matching a search. Snippet is the
+// matching text, HTML-escaped, with the matched words in <mark> tags.
+type SearchHit struct {
+	Kind    string          `json:"kind"`
+	Id      int64           `json:"id"`
+	Thread  int64           `json:"thread"`
+	Forum   string          `json:"forum"`
+	Author  string          `json:"author"`
+	Title   string          `json:"title,omitempty"`
+	Snippet string          `json:"snippet"`
+	Rank    float32         `json:"rank"`
+	Created strfmt.DateTime `json:"created"`
+}
+
+func (h SearchHit) Key() SearchKey {
+	return SearchKey{Rank: h.Rank, Kind: h.Kind, Id: h.Id}
+}
+
+//easyjson:json
+type SearchHits []SearchHit
+
+// Orders of the user directory.
+const (
+	UsersByRelevance = "relevance"
+	UsersByNickname  = "nickname"
+)
+
+// UserQuery looks users up by a prefix of, or a name similar to, their
+// nickname or full name. An empty Text lists every user.
+//easyjson:skip
+type UserQuery struct {
+	Text  string `json:"query" valid:"runelength(0|64)"`
+	Forum string `json:"forum"`
+	Sort  string `json:"sort"`
+	Desc  bool
+	Limit int
+	After *UserKey
+}
+
+// UserKey is the position of a user in the directory or the users of a
+// forum; Score only counts when ordering by relevance, and Desc tells the
+// direction of the listing.
+//easyjson:skip
+type UserKey struct {
+	Score    float32 `json:"s,omitempty"`
+	Desc     bool    `json:"d,omitempty"`
+	Nickname string  `json:"n"`
+}
+
+// UserMatch is a user found by a UserQuery with how well it matched.
+//easyjson:skip
+type UserMatch struct {
+	User
+	Score float32
+}
+
+func (m UserMatch) Key() UserKey {
+	return UserKey{Score: m.Score, Nickname: m.Nickname}
+}
+
+// UsersPage is a page of the user directory; Next is the cursor of the
+// following page.
+type UsersPage struct {
+	Users Users  `json:"users"`
+	Next  string `json:"next,omitempty"`
+}
+
+// Types of live events. Data holds the new posts of posts.created, the
+// post of post.edited and the thread of the others.
+const (
+	EventPostsCreated  = "posts.created"
+	EventPostEdited    = "post.edited"
+	EventThreadVoted   = "thread.voted"
+	EventThreadUpdated = "thread.updated"
+)
+
+// Event is a change in a thread, as streamed to live clients. Ids grow in
+// the order events are added.
+type Event struct {
+	Id      int64           `json:"id"`
+	Type    string          `json:"type"`
+	Forum   string          `json:"forum"`
+	Thread  int             `json:"thread"`
+	Data    json.RawMessage `json:"data"`
+	Created strfmt.DateTime `json:"created"`
+}
+
+type Events []Event
+
+// EventQuery selects the events following After, of one thread or forum
+// when set.
+//easyjson:skip
+type EventQuery struct {
+	Forum  string
+	Thread int
+	After  int64
+	Limit  int
+}
+
+// Matches reports whether e is one of the events the query selects,
+// regardless of its position.
+func (q EventQuery) Matches(e Event) bool {
+	return (q.Thread == 0 || q.Thread == e.Thread) &&
+		(q.Forum == "" || strings.EqualFold(q.Forum, e.Forum))
+}
+
+// Types of webhook events.
+const (
+	HookUserCreated   = "user.created"
+	HookUserUpdated   = "user.updated"
+	HookForumCreated  = "forum.created"
+	HookThreadCreated = "thread.created"
+	HookThreadUpdated = "thread.updated"
+	HookThreadDeleted = "thread.deleted"
+	HookPostCreated   = "post.created"
+	HookPostUpdated   = "post.updated"
+	HookVoteCast      = "vote.cast"
+)
+
+// HookTypes lists every webhook event type.
+var HookTypes = []string{HookUserCreated, HookUserUpdated, HookForumCreated, HookThreadCreated,
+	HookThreadUpdated, HookThreadDeleted, HookPostCreated, HookPostUpdated, HookVoteCast}
+
+// Webhook posts the events of a forum, or of the whole site without one,
+// to Url. An empty Events takes every type. Secret signs the payloads; it
+// is only shown when the webhook is created.
+type Webhook struct {
+	Id      int             `json:"id"`
+	Url     string          `json:"url" valid:"required,hookurl,runelength(1|2048)"`
+	Forum   string          `json:"forum,omitempty"`
+	Events  []string        `json:"events"`
+	Secret  string          `json:"secret,omitempty" valid:"minstringlength(16),runelength(0|256)"`
+	Created strfmt.DateTime `json:"created"`
 }
 
+// Takes reports whether the webhook posts events of the given type about
+// the given forum; site-wide events have no forum.
+func (w Webhook) Takes(kind string, forum string) bool {
+	if w.Forum != "" && !strings.EqualFold(w.Forum, forum) {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == kind {
+			return true
+		}
+	}
+	return false
+}
+
+type Webhooks []Webhook
+
+// States of a webhook delivery. A dead delivery ran out of attempts and
+// waits for a redelivery.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// HookEvent is an event of the outbox, as posted to webhooks.
+type HookEvent struct {
+	Id      int64           `json:"id"`
+	Type    string          `json:"type"`
+	Forum   string          `json:"forum,omitempty"`
+	Data    json.RawMessage `json:"data"`
+	Created strfmt.DateTime `json:"created"`
+}
+
+// Delivery is the sending of an event to a webhook.
+type Delivery struct {
+	Id          int64            `json:"id"`
+	Webhook     int              `json:"webhook"`
+	Event       int64            `json:"event"`
+	Type        string           `json:"type"`
+	State       string           `json:"state"`
+	Attempts    int              `json:"attempts"`
+	NextAttempt *strfmt.DateTime `json:"nextAttempt,omitempty"`
+	LastStatus  int              `json:"lastStatus,omitempty"`
+	LastError   string           `json:"lastError,omitempty"`
+	Created     strfmt.DateTime  `json:"created"`
+	DeliveredAt *strfmt.DateTime `json:"deliveredAt,omitempty"`
+}
+
+type Deliveries []Delivery
+
+// DueDelivery is a delivery claimed for an attempt, with what it takes to
+// make it.
+//easyjson:skip
+type DueDelivery struct {
+	Id       int64
+	Attempts int
+	Url      string
+	Secret   string
+	Event    HookEvent
+}
+
+// DeliveryResult records an attempt. A failed delivery is tried again at
+// NextAttempt, unless it is Dead.
+//easyjson:skip
+type DeliveryResult struct {
+	Delivered   bool
+	Status      int
+	Error       string
+	Dead        bool
+	NextAttempt time.Time
+}
+
+// Types of notifications: a reply to a post of the user, a post mentioning
+// them and a new thread in a forum they subscribed to.
+const (
+	NotifyReply   = "reply"
+	NotifyMention = "mention"
+	NotifyThread  = "thread"
+)
+
+// Notification tells a user what Actor did. Post is the reply or the
+// mention, absent for a new thread.
+type Notification struct {
+	Id      int64           `json:"id"`
+	Type    string          `json:"type"`
+	Actor   string          `json:"actor"`
+	Forum   string          `json:"forum"`
+	Thread  int             `json:"thread"`
+	Post    int             `json:"post,omitempty"`
+	Read    bool            `json:"read"`
+	Created strfmt.DateTime `json:"created"`
+}
+
+type Notifications []Notification
+
+// NotificationQuery pages through the notifications of a user, newest
+// first; After is the id of the last one of the previous page.
+//easyjson:skip
+type NotificationQuery struct {
+	Nickname string
+	Unread   bool
+	Limit    int
+	After    int64
+}
+
+//easyjson:skip
+type NotificationKey struct {
+	Id int64 `json:"i"`
+}
+
+// NotificationsPage is a page of notifications with the number of unread
+// ones; Next is the cursor of the following page.
+type NotificationsPage struct {
+	Notifications Notifications `json:"notifications"`
+	Unread        int           `json:"unread"`
+	Next          string        `json:"next,omitempty"`
+}
+
+// UnreadNotifications counts the unread notifications of a user, in all
+// and by type.
+type UnreadNotifications struct {
+	Unread int            `json:"unread"`
+	ByType map[string]int `json:"byType"`
+}
+
+// NotificationsMarked answers marking notifications as read with how many
+// were unread.
+type NotificationsMarked struct {
+	Marked int `json:"marked"`
+}
+
+// ForumSubscription is a forum a user follows. Unread counts the new
+// threads the user was notified of and has not read.
+type ForumSubscription struct {
+	Forum   string          `json:"forum"`
+	Title   string          `json:"title"`
+	Unread  int             `json:"unread"`
+	Created strfmt.DateTime `json:"created"`
+}
+
+// ThreadSubscription is a thread a user follows. Unread counts the live
+// posts of others after LastRead, the last post the user read.
+type ThreadSubscription struct {
+	Thread   int             `json:"thread"`
+	Slug     string          `json:"slug,omitempty"`
+	Title    string          `json:"title"`
+	Forum    string          `json:"forum"`
+	LastRead int             `json:"lastRead"`
+	Unread   int             `json:"unread"`
+	Created  strfmt.DateTime `json:"created"`
+}
+
+type Subscriptions struct {
+	Forums  []ForumSubscription  `json:"forums"`
+	Threads []ThreadSubscription `json:"threads"`
+}
+
+// ReadMarker moves the last post Nickname read in a thread forward to
+// Post, or to the latest post without one.
+type ReadMarker struct {
+	Nickname string `json:"nickname" valid:"required,nickname"`
+	Post     int    `json:"post"`
+}
+
+// ThreadRead is where a user is in a thread.
+type ThreadRead struct {
+	Thread   int    `json:"thread"`
+	Nickname string `json:"nickname"`
+	LastRead int    `json:"lastRead"`
+	Unread   int    `json:"unread"`
+}
+
+// MaxParticipants bounds the users of a conversation, its creator
+// included: conversations are one-to-one or between a small group.
+const MaxParticipants = 16
+
+// Participant is a user of a conversation; LastRead is the last message
+// they read in it.
+type Participant struct {
+	Nickname string          `json:"nickname"`
+	LastRead int64           `json:"lastRead"`
+	Joined   strfmt.DateTime `json:"joined"`
+}
+
+// Conversation is a private conversation between a few users. Unread
+// counts the messages of others the user it is shown to has not read.
+type Conversation struct {
+	Id           int             `json:"id"`
+	Title        string          `json:"title,omitempty"`
+	Creator      string          `json:"creator"`
+	Participants []Participant   `json:"participants"`
+	LastMessage  int64           `json:"lastMessage"`
+	Unread       int             `json:"unread"`
+	Created      strfmt.DateTime `json:"created"`
+}
+
+type Conversations []Conversation
+
+// NewConversation starts a conversation with the given users and a first
+// message, which follows the rules of a post.
+type NewConversation struct {
+	Participants []string `json:"participants"`
+	Title        string   `json:"title" valid:"runelength(0|256)"`
+	Message      string   `json:"message" valid:"required,runelength(1|65536)"`
+}
+
+// ConversationQuery pages through the conversations of a user, the one
+// with the latest message first; After is the last message of the last
+// conversation of the previous page.
+//easyjson:skip
+type ConversationQuery struct {
+	Nickname string
+	Limit    int
+	After    int64
+}
+
+//easyjson:skip
+type ConversationKey struct {
+	Last int64 `json:"l"`
+}
+
+// PrivateMessage is a message of a conversation. Its text follows the
+// rules of a post.
+type PrivateMessage struct {
+	Id           int64            `json:"id"`
+	Conversation int              `json:"conversation"`
+	Author       string           `json:"author"`
+	Message      string           `json:"message" valid:"required,runelength(1|65536)"`
+	IsEdited     bool             `json:"isEdited"`
+	Created      strfmt.DateTime  `json:"created"`
+	EditedAt     *strfmt.DateTime `json:"editedAt,omitempty"`
+}
+
+type PrivateMessages []PrivateMessage
+
+// MessageQuery lists the messages of a conversation like the flat sort
+// lists the posts of a thread: by id, after Since or, with Desc, before
+// it. The messages of the users Reader blocked are left out.
+//easyjson:skip
+type MessageQuery struct {
+	Conversation int
+	Reader       string
+	Limit        int
+	Since        int64
+	Desc         bool
+}
+
+//easyjson:skip
+type MessageKey struct {
+	Desc bool  `json:"d,omitempty"`
+	Id   int64 `json:"i"`
+}
+
+// ConversationsPage and MessagesPage are pages of the conversation and
+// message listings; Next is the cursor of the following page.
+//easyjson:skip
+type ConversationsPage struct {
+	Conversations Conversations
+	Next          string
+}
+
+//easyjson:skip
+type MessagesPage struct {
+	Messages PrivateMessages
+	Next     string
+}
+
+// ConversationRead is where a user is in a conversation.
+type ConversationRead struct {
+	Conversation int    `json:"conversation"`
+	Nickname     string `json:"nickname"`
+	LastRead     int64  `json:"lastRead"`
+	Unread       int    `json:"unread"`
+}
+
+// Block is a user, Nickname, blocking another. Neither of the two can
+// start a conversation with the other nor write to one they share, and the
+// messages the blocked user wrote before are hidden from Nickname.
+type Block struct {
+	Nickname string          `json:"nickname"`
+	Blocked  string          `json:"blocked"`
+	Created  strfmt.DateTime `json:"created"`
+}
+
+type Blocks []Block
+
+// maxMentions bounds the users a single post notifies by mentioning them.
+const maxMentions = 20
+
+// mentionPattern finds @nickname not preceded by a nickname character, so
+// that email addresses are not mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_.@])@([A-Za-z0-9_.]+)`)
+
+// Mentions returns the nicknames mentioned in a message, case-folded and
+// without repeats. A trailing dot ends the sentence, not the nickname.
+func Mentions(message string) []string {
+	var nicknames []string
+	seen := make(map[string]bool)
+	for _, m := range mentionPattern.FindAllStringSubmatch(message, -1) {
+		nickname := strings.ToLower(strings.TrimRight(m[1], "."))
+		if nickname == "" || seen[nickname] {
+			continue
+		}
+		seen[nickname] = true
+		nicknames = append(nicknames, nickname)
+		if len(nicknames) == maxMentions {
+			break
+		}
+	}
+	return nicknames
+}
+
+// Orders of the forum thread listing: by creation time, by votes, by the
+// time of the last post (or creation, for threads without posts) and by
+// the number of live posts.
+const (
+	ThreadsByCreated  = "created"
+	ThreadsByVotes    = "votes"
+	ThreadsByActivity = "activity"
+	ThreadsByPosts    = "posts"
+)
+
+// ThreadQuery lists the threads of a forum, pinned ones first, in Sort
+// order with the id as a tiebreaker. Since is the legacy RFC 3339 lower
+// bound on creation time, inclusive, and only applies to the created
+// order; After, when set, takes its place. Author, Title, From and To are
+// filters, empty ones match everything: Title is a case-insensitive
+// substring, From and To bound the creation time, inclusive.
+//easyjson:skip
+type ThreadQuery struct {
+	Forum  string
+	Sort   string
+	Author string `valid:"nickname"`
+	Title  string `valid:"runelength(0|256)"`
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Since  string
+	Desc   bool
+	After  *ThreadKey
+}
+
+// ThreadKey is the position of a thread in a forum listing. Only the
+// field of its Sort is set besides Pinned, Created and Id; Desc tells the
+// direction of the listing.
+//easyjson:skip
+type ThreadKey struct {
+	Sort     string     `json:"s"`
+	Desc     bool       `json:"d,omitempty"`
+	Pinned   bool       `json:"p,omitempty"`
+	Votes    int        `json:"v,omitempty"`
+	Posts    int        `json:"n,omitempty"`
+	Activity *time.Time `json:"a,omitempty"`
+	Created  time.Time  `json:"c"`
+	Id       uint64     `json:"i"`
+}
+
+// Key is the position of t in a listing in the given order.
+func (t Thread) Key(order string) ThreadKey {
+	key := ThreadKey{Sort: order, Pinned: t.IsPinned, Created: time.Time(t.Created), Id: t.Id}
+	switch order {
+	case ThreadsByVotes:
+		key.Votes = t.Votes
+	case ThreadsByPosts:
+		key.Posts = t.PostCount
+	case ThreadsByActivity:
+		activity := t.Activity()
+		key.Activity = &activity
+	}
+	return key
+}
+
+// Activity is the time of the last post in t, or its creation time if it
+// has none.
+func (t Thread) Activity() time.Time {
+	if t.LastPostAt != nil {
+		return time.Time(*t.LastPostAt)
+	}
+	return time.Time(t.Created)
+}
+
+// Sorts of the posts of a thread.
+const (
+	PostsFlat       = "flat"
+	PostsTree       = "tree"
+	PostsParentTree = "parent_tree"
+)
+
+// PostKey is the position of a post in a thread listing in the given Sort
+// and direction. The flat sort orders by Created and Id; the tree sorts go
+// by the path of post Id, which the store knows.
+//easyjson:skip
+type PostKey struct {
+	Sort    string     `json:"s"`
+	Desc    bool       `json:"d,omitempty"`
+	Created *time.Time `json:"c,omitempty"`
+	Id      int        `json:"i"`
+}
+
+// Key is the position of p in a listing in the given sort and direction.
+func (p Post) Key(sort string, desc bool) PostKey {
+	key := PostKey{Sort: sort, Desc: desc, Id: p.Id}
+	if sort == PostsFlat {
+		created := time.Time(p.Created)
+		key.Created = &created
+	}
+	return key
+}
+
+// ThreadsPage and PostsPage are pages of the forum and thread listings;
+// Next is the cursor of the following page.
+//easyjson:skip
+type ThreadsPage struct {
+	Threads Threads
+	Next    string
+}
+
+//easyjson:skip
+type PostsPage struct {
+	Posts Posts
+	Next  string
+}
+
+// SearchResult is a page of hits; Next is the cursor of the following page.
+type SearchResult struct {
+	Hits SearchHits `json:"hits"`
+	Next string     `json:"next,omitempty"`
+}
+
+// DiffLine is a line of a RevisionDiff; Op is "=" for a line both versions
+// share, "-" for a removed and "+" for an added one.
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type RevisionDiff struct {
+	Post  int        `json:"post"`
+	From  int        `json:"from"`
+	To    int        `json:"to"`
+	Lines []DiffLine `json:"lines"`
+}
+
+//easyjson:skip
 type ThreadSQL struct {
 	Id uint64 `json:"id"`
 	Author string `json:"author"`
@@ -66,24 +720,107 @@ type ThreadSQL struct {
 	Slug sql.NullString `json:"slug"`
 	Title string `json:"title"`
 	Votes int `json:"votes"`
+	ArchivedAt sql.NullTime `json:"archivedAt"`
+	Locked bool `json:"locked"`
+	Pinned bool `json:"pinned"`
+	ClosedAt sql.NullTime `json:"closedAt"`
+	CloseReason sql.NullString `json:"closeReason"`
+	PostCount int `json:"postCount"`
+	LastPostAt sql.NullTime `json:"lastPostAt"`
 }
 
 type Thread struct {
 	Id uint64 `json:"id"`
-	Author string `json:"author"`
+	Author string `json:"author" valid:"required,nickname"`
 	Created strfmt.DateTime `json:"created"`
 	Forum string `json:"forum"`
-	Message string `json:"message"`
-	Slug string `json:"slug"`
-	Title string `json:"title"`
+	Message string `json:"message" valid:"required,runelength(1|65536)"`
+	Slug string `json:"slug" valid:"slug,runelength(1|128)"`
+	Title string `json:"title" valid:"required,runelength(1|256)"`
 	Votes int `json:"votes"`
+	// IsArchived marks a read-only thread.
+	IsArchived bool `json:"isArchived,omitempty"`
+	// IsLocked and IsClosed threads take no new posts or votes.
+	IsLocked bool `json:"isLocked,omitempty"`
+	IsPinned bool `json:"isPinned,omitempty"`
+	IsClosed bool `json:"isClosed,omitempty"`
+	CloseReason string `json:"closeReason,omitempty"`
+	// PostCount counts the live posts; LastPostAt is when the latest was
+	// made.
+	PostCount int `json:"posts"`
+	LastPostAt *strfmt.DateTime `json:"lastPostAt,omitempty"`
+}
+
+// ThreadModeration changes the flags of a thread; absent fields are kept.
+// Reopening a thread drops its close reason.
+type ThreadModeration struct {
+	Locked *bool `json:"locked,omitempty"`
+	Pinned *bool `json:"pinned,omitempty"`
+	Closed *bool `json:"closed,omitempty"`
+	Reason string `json:"reason,omitempty" valid:"runelength(0|1024)"`
 }
 
 type User struct {
-	Nickname string `json:"nickname"`
-	Fullname string `json:"fullname"`
-	About string `json:"about"`
-	Email strfmt.Email `json:"email"`
+	Nickname string `json:"nickname" valid:"required,nickname,runelength(1|64)"`
+	Fullname string `json:"fullname" valid:"required,runelength(1|256)"`
+	About string `json:"about" valid:"runelength(0|65536)"`
+	Email strfmt.Email `json:"email" valid:"required,email,runelength(1|256)"`
+}
+
+// Signup is the body of a user creation: the profile and, when accounts
+// are in use, the password to sign in with.
+type Signup struct {
+	User
+	Password string `json:"password,omitempty" valid:"minstringlength(8),runelength(0|256)"`
+}
+
+type Login struct {
+	Nickname string `json:"nickname" valid:"required,nickname"`
+	Password string `json:"password" valid:"required"`
+}
+
+// Session is handed out on login; Token is the bearer token of the
+// following requests.
+type Session struct {
+	Token    string          `json:"token"`
+	Nickname string          `json:"nickname"`
+	Expires  strfmt.DateTime `json:"expires"`
+}
+
+// Site roles. Owners and moderators are roles within a forum and are not
+// stored here.
+const (
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+	RoleBanned = "banned"
+)
+
+// RoleChange is the body of a site role assignment.
+type RoleChange struct {
+	Role string `json:"role" valid:"required,role"`
+}
+
+// Credentials is what the store keeps to sign a user in.
+//easyjson:skip
+type Credentials struct {
+	Nickname     string
+	PasswordHash []byte
+	Role         string
+}
+
+// Caller is the authenticated user a request acts for.
+//easyjson:skip
+type Caller struct {
+	Nickname string
+	Role     string
+}
+
+func (c Caller) IsAdmin() bool {
+	return c.Role == RoleAdmin
+}
+
+func (c Caller) IsBanned() bool {
+	return c.Role == RoleBanned
 }
 
 //easyjson:json
@@ -104,6 +841,9 @@ func ConvertPostToNullMessage(post Post) (PostNullMessage) {
 	newPost.Author = post.Author
 	newPost.Created = post.Created
 	newPost.Parent = post.Parent
+	newPost.IsDeleted = post.IsDeleted
+	newPost.EditCount = post.EditCount
+	newPost.LastEditedAt = post.LastEditedAt
 	return newPost
 }
 
@@ -117,5 +857,15 @@ func ConvertThread(old ThreadSQL) (Thread) {
 	newThread.Created = old.Created
 	newThread.Message = old.Message
 	newThread.Votes = old.Votes
+	newThread.IsArchived = old.ArchivedAt.Valid
+	newThread.IsLocked = old.Locked
+	newThread.IsPinned = old.Pinned
+	newThread.IsClosed = old.ClosedAt.Valid
+	newThread.CloseReason = old.CloseReason.String
+	newThread.PostCount = old.PostCount
+	if old.LastPostAt.Valid {
+		lastPostAt := strfmt.DateTime(old.LastPostAt.Time)
+		newThread.LastPostAt = &lastPostAt
+	}
 	return newThread
 }
\ No newline at end of file
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMentions(t *testing.T) {
+	var many []string
+	for i := 0; i < maxMentions+5; i++ {
+		many = append(many, "@user"+strconv.Itoa(i))
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"start", "@alice look", "alice"},
+		{"case-folded", "thanks, @Alice", "alice"},
+		{"end of a sentence", "ask @alice.", "alice"},
+		{"dotted nickname", "ask @a.b. now", "a.b"},
+		{"punctuation", "(@alice),@bob;@carol!", "alice bob carol"},
+		{"new line", "hi\n@alice", "alice"},
+		{"repeats", "@alice @ALICE @alice", "alice"},
+		{"email address", "write to bob@example.com", ""},
+		{"after a nickname character", "x@alice _@bob .@carol", ""},
+		{"doubled at", "@@alice", ""},
+		{"no nickname", "@ @. @-", ""},
+		{"cyrillic", "@алиса", ""},
+		{"bounded", strings.Join(many, " "), strings.Join(many[:maxMentions], " ")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := strings.ReplaceAll(tt.want, "@", "")
+			if got := strings.Join(Mentions(tt.message), " "); got != want {
+				t.Errorf("Mentions(%q) = %q, want %q", tt.message, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+)
+
+// The rules live in the `valid` tags of the models and are checked by
+// govalidator. Besides its built-in validators four more are registered:
+// nickname, slug, role and hookurl. A slug must contain a non-digit so that it can never
+// be mistaken for a thread id in /api/thread/:slug_or_id.
+var (
+	nicknamePattern = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+	slugPattern     = regexp.MustCompile(`^[\w-]*[A-Za-z_-][\w-]*$`)
+)
+
+func init() {
+	govalidator.TagMap["nickname"] = nicknamePattern.MatchString
+	govalidator.TagMap["slug"] = slugPattern.MatchString
+	govalidator.TagMap["role"] = func(s string) bool {
+		return s == RoleMember || s == RoleAdmin || s == RoleBanned
+	}
+	govalidator.TagMap["hookurl"] = func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	}
+}
+
+// FieldError describes one rule a field broke.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every broken rule of a request, ordered by field.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, f := range e {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Prefix qualifies the field names, e.g. message becomes posts[2].message.
+func (e ValidationError) Prefix(prefix string) ValidationError {
+	for i := range e {
+		e[i].Field = prefix + "." + e[i].Field
+	}
+	return e
+}
+
+// Validate checks v, a pointer to a model, against its rules.
+func Validate(v interface{}) error {
+	return validate(v, false)
+}
+
+// ValidatePartial checks v like Validate but accepts missing fields, for
+// updates where an empty field keeps the stored value.
+func ValidatePartial(v interface{}) error {
+	return validate(v, true)
+}
+
+func validate(v interface{}, partial bool) error {
+	_, err := govalidator.ValidateStruct(v)
+	if err == nil {
+		return nil
+	}
+
+	var errs govalidator.Errors
+	if !errors.As(err, &errs) {
+		return err
+	}
+
+	fields, ok := flatten(errs)
+	if !ok {
+		return err
+	}
+	var out ValidationError
+	for _, e := range fields {
+		if partial && e.Validator == "required" {
+			continue
+		}
+		out = append(out, FieldError{Field: e.Name, Rule: e.Validator, Message: message(e)})
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Field < out[j].Field })
+
+	return out
+}
+
+// flatten lists the field errors of errs, which nests the errors of
+// embedded structs such as the User of a Signup, and reports false if
+// errs holds anything else.
+func flatten(errs govalidator.Errors) ([]govalidator.Error, bool) {
+	var fields []govalidator.Error
+	for _, fe := range errs.Errors() {
+		var nested govalidator.Errors
+		if errors.As(fe, &nested) {
+			more, ok := flatten(nested)
+			if !ok {
+				return nil, false
+			}
+			fields = append(fields, more...)
+			continue
+		}
+		var e govalidator.Error
+		if !errors.As(fe, &e) {
+			return nil, false
+		}
+		fields = append(fields, e)
+	}
+	return fields, true
+}
+
+func message(e govalidator.Error) string {
+	switch e.Validator {
+	case "required":
+		return "is required"
+	case "nickname":
+		return "may only contain latin letters, digits, '_' and '.'"
+	case "slug":
+		return "may only contain latin letters, digits, '_' and '-', and must not be a number"
+	case "email":
+		return "is not a valid email address"
+	case "runelength":
+		return "is too long"
+	case "minstringlength":
+		return "is too short"
+	case "in":
+		return "must be -1 or 1"
+	case "role":
+		return "must be member, admin or banned"
+	case "hookurl":
+		return "must be an http or https URL"
+	}
+	return e.Err.Error()
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/asaskevich/govalidator"
+)
+
+func TestPatterns(t *testing.T) {
+	tests := []struct {
+		value    string
+		nickname bool
+		slug     bool
+	}{
+		{"alice", true, true},
+		{"Alice_1.2", true, false},
+		{"a.b", true, false},
+		{"forum-2", false, true},
+		{"_", true, true},
+		{"-", false, true},
+		{"42", true, false},
+		{"4-2", false, true},
+		{"", false, false},
+		{"a b", false, false},
+		{"алиса", false, false},
+		{"a/b", false, false},
+	}
+	for _, tt := range tests {
+		if got := nicknamePattern.MatchString(tt.value); got != tt.nickname {
+			t.Errorf("nickname %q matches: %v, want %v", tt.value, got, tt.nickname)
+		}
+		if got := slugPattern.MatchString(tt.value); got != tt.slug {
+			t.Errorf("slug %q matches: %v, want %v", tt.value, got, tt.slug)
+		}
+	}
+}
+
+// broken lists the broken rules of err as field:rule.
+func broken(t *testing.T, err error) string {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	var verr ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("%v is not a ValidationError", err)
+	}
+	rules := make([]string, len(verr))
+	for i, f := range verr {
+		if f.Message == "" {
+			t.Errorf("%s:%s has no message", f.Field, f.Rule)
+		}
+		rules[i] = f.Field + ":" + f.Rule
+	}
+	return strings.Join(rules, " ")
+}
+
+func TestValidate(t *testing.T) {
+	user := User{Nickname: "alice", Fullname: "Alice", Email: "alice@example.com"}
+	long := strings.Repeat("a", 257)
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"user", &user, ""},
+		{"nickname pattern", &User{Nickname: "a b", Fullname: "A", Email: "a@example.com"}, "nickname:nickname"},
+		{"nickname length", &User{Nickname: strings.Repeat("a", 65), Fullname: "A", Email: "a@example.com"},
+			"nickname:runelength"},
+		{"email", &User{Nickname: "alice", Fullname: "A", Email: "alice"}, "email:email"},
+		{"missing fields", &User{}, "email:required fullname:required nickname:required"},
+		{"runes, not bytes", &Forum{Title: strings.Repeat("ё", 256), Owner: "alice", Slug: "forum"}, ""},
+		{"title length", &Forum{Title: long, Owner: "alice", Slug: "forum"}, "title:runelength"},
+		{"numeric slug", &Forum{Title: "Forum", Owner: "alice", Slug: "42"}, "slug:slug"},
+		{"optional slug", &Thread{Author: "alice", Title: "T", Message: "m"}, ""},
+		{"upvote", &Vote{Nickname: "alice", Voice: 1}, ""},
+		{"downvote", &Vote{Nickname: "alice", Voice: -1}, ""},
+		{"no voice", &Vote{Nickname: "alice"}, "voice:required"},
+		{"voice out of range", &Vote{Nickname: "alice", Voice: 2}, "voice:in"},
+		{"embedded user", &Signup{User: User{Nickname: "a-b", Fullname: "A", Email: "a@example.com"}, Password: "secret"},
+			"nickname:nickname password:minstringlength"},
+		{"role", &RoleChange{Role: "owner"}, "role:role"},
+		{"hook url", &Webhook{Url: "ftp://example.com"}, "url:hookurl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := broken(t, Validate(tt.value)); got != tt.want {
+				t.Errorf("broken rules: %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePartial(t *testing.T) {
+	if err := ValidatePartial(&User{}); err != nil {
+		t.Errorf("ValidatePartial of an empty update = %v", err)
+	}
+	if got := broken(t, ValidatePartial(&User{Email: "alice"})); got != "email:email" {
+		t.Errorf("broken rules: %q, want email:email", got)
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := ValidationError{
+		{Field: "author", Rule: "required", Message: "is required"},
+		{Field: "message", Rule: "runelength", Message: "is too long"},
+	}.Prefix("posts[2]")
+
+	want := "posts[2].author: is required; posts[2].message: is too long"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	field := govalidator.Error{Name: "title", Err: errors.New("bad"), Validator: "required"}
+	nested := govalidator.Errors{govalidator.Errors{field}, field}
+
+	fields, ok := flatten(nested)
+	if !ok || len(fields) != 2 {
+		t.Errorf("flatten = %d fields, %v, want 2, true", len(fields), ok)
+	}
+	if _, ok := flatten(govalidator.Errors{field, errors.New("not about a field")}); ok {
+		t.Error("flatten took an error about no field")
+	}
+}
@@ -1,54 +1,172 @@
 package event
 
 import (
+	"context"
 	"subd/models"
 	"time"
 )
 
 type Repository interface {
-	CheckUser(user string) (bool, error)
-	CheckUserByEmail(email string) (bool, error)
-	CheckUserByNicknameOrEmail(nickname string, email string) (bool, error)
-	AddNewForum(newForum *models.Forum) (error, bool)
-	GetForumCounts(slug string) (uint64, uint64, error)
-	GetForum(slug string) (models.Forum, int)
-	CheckForum(slug string) (bool, error)
-	CheckThread(slug string) (bool, error)
-	CheckThreadById(id int) (bool, error)
-	CheckPost(id int) (bool, error)
-	GetThread(slug string) (models.Thread, error)
-	GetThreadStatus(slug string) (models.Thread, int)
-	GetThreadById(id int) (models.Thread, int)
-	GetPost(id int) (models.Post, int)
-	GetUser(name string) (models.User, int)
-	AddNewThread(newThread models.Thread) (uint64, error)
-	GetForumUsers(slug string, limit int, since string, desc bool) (models.Users, error)
-	AddForumUsers(slug string, author string) error
-	GetForumThreads(slug string, limit int, since string, desc bool) (models.Threads, error)
-	EditMessage(id int, message string) error
-	Clear() error
-	Status() (models.Status, error)
-	CreateUser(nickname string, user models.User) error
-	GetUserByNicknameOrEmail(nickname string, email string) (models.Users, error)
-	UpdateUser(nickname string, user models.User) error
-	IncrementThreads(forum string) error
-	IncrementPosts(forum string) error
-	AddPost(newPosts []*models.Post, thread models.Thread, now time.Time) int
-	UpdateThread(slugOrId string, thread models.Thread) (models.Thread, error)
-	UpdateThreadById(id int, thread models.Thread) (models.Thread, error)
-	CheckVote(id int, nickname string) (bool, error)
-	AddVote(id int, vote models.Vote) error
-	UpdateVote(id int, vote models.Vote) error
-	GetValueVote(id int, nickname string) (int, error)
-	GetPostsFlat(id int ,limit int, since int) (models.Posts, error)
-	GetPostsFlatDesc(id int ,limit int, since int) (models.Posts, error)
-	GetPostsTree(id int ,limit int) (models.Posts, error)
-	GetPostsTreeDesc(id int ,limit int) (models.Posts, error)
-	GetPostsTreeSince(id int ,limit int, since int) (models.Posts, error)
-	GetPostsTreeSinceDesc(id int ,limit int, since int) (models.Posts, error)
-	GetPostsParentTree(id int ,limit int) (models.Posts, error)
-	GetPostsParentTreeDesc(id int ,limit int) (models.Posts, error)
-	GetPostsParentTreeSince(id int ,limit int, since int) (models.Posts, error)
-	GetPostsParentTreeSinceDesc(id int ,limit int, since int) (models.Posts, error)
-	GetPostNull(id int) (models.PostNullMessage, int)
+	CheckUser(ctx context.Context, user string) (bool, error)
+	CheckUserByEmail(ctx context.Context, email string) (bool, error)
+	CheckUserByNicknameOrEmail(ctx context.Context, nickname string, email string) (bool, error)
+	AddNewForum(ctx context.Context, newForum *models.Forum) (error, bool)
+	GetForumCounts(ctx context.Context, slug string) (uint64, uint64, error)
+	GetForum(ctx context.Context, slug string) (models.Forum, int)
+	CheckForum(ctx context.Context, slug string) (bool, error)
+	CheckThread(ctx context.Context, slug string) (bool, error)
+	CheckThreadById(ctx context.Context, id int) (bool, error)
+	CheckPost(ctx context.Context, id int) (bool, error)
+	GetThread(ctx context.Context, slug string) (models.Thread, error)
+	GetThreadStatus(ctx context.Context, slug string) (models.Thread, int)
+	GetThreadById(ctx context.Context, id int) (models.Thread, int)
+	GetPost(ctx context.Context, id int) (models.Post, int)
+	GetUser(ctx context.Context, name string) (models.User, int)
+	AddNewThread(ctx context.Context, newThread models.Thread) (uint64, error)
+	GetForumUsers(ctx context.Context, slug string, limit int, since string, desc bool, excludeBanned bool) (models.Users, error)
+	AddForumUsers(ctx context.Context, slug string, author string) error
+	GetForumThreads(ctx context.Context, query models.ThreadQuery) (models.Threads, error)
+	EditMessage(ctx context.Context, id int, message string, editor string) error
+	GetPostRevisions(ctx context.Context, id int) (models.Revisions, error)
+	// DeletePost and RestorePost report http.StatusOK, also when the post
+	// is already in the requested state, or http.StatusNotFound.
+	DeletePost(ctx context.Context, id int) int
+	RestorePost(ctx context.Context, id int) int
+	Clear(ctx context.Context) error
+	Status(ctx context.Context) (models.Status, error)
+	CreateUser(ctx context.Context, nickname string, user models.User) error
+	GetUserByNicknameOrEmail(ctx context.Context, nickname string, email string) (models.Users, error)
+	UpdateUser(ctx context.Context, nickname string, user models.User) error
+	IncrementThreads(ctx context.Context, forum string) error
+	IncrementPosts(ctx context.Context, forum string) error
+	AddPost(ctx context.Context, newPosts []*models.Post, thread models.Thread, now time.Time) int
+	UpdateThread(ctx context.Context, slugOrId string, thread models.Thread) (models.Thread, error)
+	UpdateThreadById(ctx context.Context, id int, thread models.Thread) (models.Thread, error)
+	DeleteThread(ctx context.Context, id int) int
+	SetThreadArchived(ctx context.Context, id int, archived bool) int
+	ModerateThread(ctx context.Context, id int, m models.ThreadModeration) int
+	CheckVote(ctx context.Context, id int, nickname string) (bool, error)
+	AddVote(ctx context.Context, id int, vote models.Vote) error
+	UpdateVote(ctx context.Context, id int, vote models.Vote) error
+	GetValueVote(ctx context.Context, id int, nickname string) (int, error)
+	// GetPostsFlat and GetPostsFlatDesc list the posts of a thread by
+	// creation and id, following after unless it is nil.
+	GetPostsFlat(ctx context.Context, id int ,limit int, after *models.PostKey) (models.Posts, error)
+	GetPostsFlatDesc(ctx context.Context, id int ,limit int, after *models.PostKey) (models.Posts, error)
+	GetPostsTree(ctx context.Context, id int ,limit int) (models.Posts, error)
+	GetPostsTreeDesc(ctx context.Context, id int ,limit int) (models.Posts, error)
+	GetPostsTreeSince(ctx context.Context, id int ,limit int, since int) (models.Posts, error)
+	GetPostsTreeSinceDesc(ctx context.Context, id int ,limit int, since int) (models.Posts, error)
+	GetPostsParentTree(ctx context.Context, id int ,limit int) (models.Posts, error)
+	GetPostsParentTreeDesc(ctx context.Context, id int ,limit int) (models.Posts, error)
+	GetPostsParentTreeSince(ctx context.Context, id int ,limit int, since int) (models.Posts, error)
+	GetPostsParentTreeSinceDesc(ctx context.Context, id int ,limit int, since int) (models.Posts, error)
+	GetPostNull(ctx context.Context, id int) (models.PostNullMessage, int)
+	SetCredentials(ctx context.Context, nickname string, passwordHash []byte) error
+	GetCredentials(ctx context.Context, nickname string) (models.Credentials, int)
+	AddSession(ctx context.Context, tokenHash []byte, nickname string, expires time.Time) error
+	GetSession(ctx context.Context, tokenHash []byte) (models.Caller, int)
+	DeleteSession(ctx context.Context, tokenHash []byte) error
+	// SetRole reports http.StatusNotFound for users without credentials.
+	SetRole(ctx context.Context, nickname string, role string) int
+	AddModerator(ctx context.Context, forum string, nickname string) error
+	// RemoveModerator reports http.StatusNotFound if nickname does not
+	// moderate forum.
+	RemoveModerator(ctx context.Context, forum string, nickname string) int
+	GetModerators(ctx context.Context, forum string) (models.Users, error)
+	IsModerator(ctx context.Context, forum string, nickname string) (bool, error)
+	// AddBan replaces an earlier ban of the user in the same forum.
+	AddBan(ctx context.Context, ban models.Ban) error
+	// RemoveBan lifts a forum ban, or the site-wide ban when forum is
+	// empty, and reports http.StatusNotFound if there was none.
+	RemoveBan(ctx context.Context, forum string, nickname string) int
+	// GetForumBans and FindBans list the bans in force in a forum, the
+	// site-wide ones included.
+	GetForumBans(ctx context.Context, forum string) (models.Bans, error)
+	FindBans(ctx context.Context, forum string, nicknames []string) (models.Bans, error)
+	// Search returns up to query.Limit hits following query.After.
+	Search(ctx context.Context, query models.SearchQuery) (models.SearchHits, error)
+	// FindUsers returns up to query.Limit users following query.After.
+	FindUsers(ctx context.Context, query models.UserQuery) ([]models.UserMatch, error)
+	// GetEvents returns up to query.Limit events following query.After, in
+	// id order. Events are added by AddPost, EditMessage, UpdateThread,
+	// UpdateThreadById, AddVote and UpdateVote as part of their change, and
+	// wake up the ListenEvents of every server sharing the storage.
+	GetEvents(ctx context.Context, query models.EventQuery) (models.Events, error)
+	// LastEventId is the id of the latest event GetEvents would return, 0 if
+	// there is none.
+	LastEventId(ctx context.Context) (int64, error)
+	// ListenEvents calls notify once listening and then whenever events
+	// may have been added, until ctx is done or the storage fails.
+	ListenEvents(ctx context.Context, notify func()) error
+	// PruneEvents drops the events added before a time.
+	PruneEvents(ctx context.Context, before time.Time) error
+	// AddWebhook sets the id and creation time of hook.
+	AddWebhook(ctx context.Context, hook *models.Webhook) error
+	// GetWebhooks lists the webhooks of a forum, or all of them when forum
+	// is empty.
+	GetWebhooks(ctx context.Context, forum string) (models.Webhooks, error)
+	GetWebhook(ctx context.Context, id int) (models.Webhook, int)
+	DeleteWebhook(ctx context.Context, id int) int
+	// DispatchOutbox turns up to limit events of the outbox into deliveries
+	// to the webhooks taking them, and reports how many events it took.
+	DispatchOutbox(ctx context.Context, limit int) (int, error)
+	// ClaimDeliveries returns up to limit pending deliveries due at now and
+	// postpones them to until, so that no other dispatcher takes them
+	// meanwhile.
+	ClaimDeliveries(ctx context.Context, now time.Time, until time.Time, limit int) ([]models.DueDelivery, error)
+	RecordDelivery(ctx context.Context, id int64, result models.DeliveryResult) error
+	// GetDeliveries lists the latest deliveries to a webhook, of one state
+	// when it is set.
+	GetDeliveries(ctx context.Context, webhook int, state string, limit int) (models.Deliveries, error)
+	// Redeliver makes a delivery pending again with fresh attempts, and
+	// reports http.StatusNotFound if webhook has no such delivery.
+	Redeliver(ctx context.Context, webhook int, id int64) (models.Delivery, int)
+	// PruneDeliveries drops the deliveries made before a time that are no
+	// longer pending, and the events left without deliveries.
+	PruneDeliveries(ctx context.Context, before time.Time) error
+	SubscribeForum(ctx context.Context, forum string, nickname string) error
+	UnsubscribeForum(ctx context.Context, forum string, nickname string) int
+	GetNotifications(ctx context.Context, query models.NotificationQuery) (models.Notifications, error)
+	// CountUnreadNotifications counts the unread notifications of a user
+	// by type.
+	CountUnreadNotifications(ctx context.Context, nickname string) (map[string]int, error)
+	// MarkNotificationRead reports http.StatusNotFound if the user has no
+	// such notification.
+	MarkNotificationRead(ctx context.Context, nickname string, id int64) int
+	// MarkNotificationsRead marks the notifications of a user up to an id,
+	// all of them for 0, as read and reports how many were unread.
+	MarkNotificationsRead(ctx context.Context, nickname string, upTo int64) (int, error)
+	SubscribeThread(ctx context.Context, thread int, nickname string) error
+	UnsubscribeThread(ctx context.Context, thread int, nickname string) int
+	GetSubscriptions(ctx context.Context, nickname string) (models.Subscriptions, error)
+	// GetLastRead returns the last post a user read in a thread, 0 if none.
+	GetLastRead(ctx context.Context, thread int, nickname string) (int, error)
+	// MarkThreadRead moves the last post a user read in a thread forward to
+	// post, or to the latest post for 0, and marks the notifications about
+	// what the user has now read as read.
+	MarkThreadRead(ctx context.Context, thread int, nickname string, post int) (models.ThreadRead, error)
+	// AddConversation stores a conversation between the participants, as
+	// stored, along with its first message, and sets the ids and creation
+	// times of both.
+	AddConversation(ctx context.Context, conversation *models.Conversation, message *models.PrivateMessage) error
+	// GetConversation returns a conversation as nickname sees it, and
+	// reports http.StatusNotFound unless they take part in it.
+	GetConversation(ctx context.Context, id int, nickname string) (models.Conversation, int)
+	GetConversations(ctx context.Context, query models.ConversationQuery) (models.Conversations, error)
+	// AddPrivateMessage sets the id and creation time of message, and moves
+	// the read marker of its author to it.
+	AddPrivateMessage(ctx context.Context, message *models.PrivateMessage) error
+	GetPrivateMessage(ctx context.Context, conversation int, id int64) (models.PrivateMessage, int)
+	GetPrivateMessages(ctx context.Context, query models.MessageQuery) (models.PrivateMessages, error)
+	EditPrivateMessage(ctx context.Context, conversation int, id int64, message string) (models.PrivateMessage, int)
+	// MarkConversationRead moves the last message a participant read
+	// forward to upTo, or to the latest message for 0.
+	MarkConversationRead(ctx context.Context, conversation int, nickname string, upTo int64) (models.ConversationRead, error)
+	Block(ctx context.Context, nickname string, blocked string) error
+	Unblock(ctx context.Context, nickname string, blocked string) int
+	GetBlocks(ctx context.Context, nickname string) (models.Blocks, error)
+	// Blocking returns those of others nickname blocked or who blocked
+	// nickname.
+	Blocking(ctx context.Context, nickname string, others []string) ([]string, error)
 }
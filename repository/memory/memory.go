@@ -0,0 +1,2557 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	event "subd"
+	"subd/models"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+var (
+	errNoRows           = errors.New("no rows in result set")
+	errUniqueViolation  = errors.New("duplicate key value violates unique constraint")
+	errForeignKey       = errors.New("insert or update violates foreign key constraint")
+	errInvalidTimestamp = errors.New("invalid input syntax for type timestamp with time zone")
+)
+
+type post struct {
+	models.Post
+	path      []int
+	revisions models.Revisions
+}
+
+// view is the post as clients see it: a tombstone keeps its message only
+// for a later restore.
+func (p *post) view() models.Post {
+	v := p.Post
+	if v.IsDeleted {
+		v.Message = ""
+	}
+	return v
+}
+
+type session struct {
+	nickname string
+	expires  time.Time
+}
+
+// hookEvent is an event of the outbox.
+type hookEvent struct {
+	models.HookEvent
+	dispatched bool
+}
+
+// delivery keeps when a delivery is next tried, also while it is not
+// pending.
+type delivery struct {
+	models.Delivery
+	next time.Time
+}
+
+// subscription is a user following a forum or a thread.
+type subscription struct {
+	nickname string
+	created  strfmt.DateTime
+}
+
+// notification keeps whom a notification is for.
+type notification struct {
+	models.Notification
+	nickname string
+}
+
+// conversation keeps the participants of a conversation, ordered by
+// nickname, and its messages, ordered by id.
+type conversation struct {
+	models.Conversation
+	participants []*models.Participant
+	messages     []*models.PrivateMessage
+}
+
+func (c *conversation) participant(nickname string) *models.Participant {
+	for _, p := range c.participants {
+		if fold(p.Nickname) == fold(nickname) {
+			return p
+		}
+	}
+	return nil
+}
+
+func (d *delivery) view() models.Delivery {
+	v := d.Delivery
+	if v.State == models.DeliveryPending {
+		next := strfmt.DateTime(d.next)
+		v.NextAttempt = &next
+	}
+	return v
+}
+
+// MemoryDatabase keeps the whole forum in process memory. It mirrors the
+// constraints and triggers of the migrations in migrations/sql: nicknames,
+// emails and slugs are compared case-insensitively, posts carry a
+// materialized path and votes update the thread counter the same way the
+// insert_votes and update_votes triggers do.
+type MemoryDatabase struct {
+	mu sync.RWMutex
+
+	users      []*models.User
+	usersByKey map[string]*models.User
+
+	forums      map[string]*models.Forum
+	forumUsers  map[string]map[string]string
+	moderators  map[string]map[string]string
+	bans        map[banKey]*models.Ban
+	threads     map[int]*models.Thread
+	threadSlugs map[string]*models.Thread
+	posts       map[int]*post
+	threadPosts map[int][]*post
+	votes       map[int]map[string]int
+	credentials map[string]*models.Credentials
+	sessions    map[string]session
+	events      models.Events
+	webhooks    map[int]*models.Webhook
+	outbox      []*hookEvent
+	deliveries  []*delivery
+	// forumSubs and threadSubs map a forum or thread to its subscribers by
+	// folded nickname; reads maps a thread to the last post each user read.
+	forumSubs     map[string]map[string]*subscription
+	threadSubs    map[int]map[string]*subscription
+	reads         map[int]map[string]int
+	notifications []*notification
+	conversations map[int]*conversation
+	// blocks maps a user to those they blocked, both by folded nickname.
+	blocks map[string]map[string]*models.Block
+
+	// listeners are the wake-up channels of ListenEvents.
+	listeners map[chan struct{}]bool
+
+	lastThreadId int
+	lastPostId   int
+	lastEventId  int64
+
+	lastWebhookId  int
+	lastOutboxId   int64
+	lastDeliveryId int64
+
+	lastNotificationId int64
+
+	lastConversationId int
+	lastMessageId      int64
+}
+
+func NewMemoryDatabase() event.Repository {
+	db := &MemoryDatabase{listeners: make(map[chan struct{}]bool)}
+	db.reset()
+	return db
+}
+
+func (md *MemoryDatabase) reset() {
+	md.users = nil
+	md.usersByKey = make(map[string]*models.User)
+	md.forums = make(map[string]*models.Forum)
+	md.forumUsers = make(map[string]map[string]string)
+	md.moderators = make(map[string]map[string]string)
+	md.bans = make(map[banKey]*models.Ban)
+	md.threads = make(map[int]*models.Thread)
+	md.threadSlugs = make(map[string]*models.Thread)
+	md.posts = make(map[int]*post)
+	md.threadPosts = make(map[int][]*post)
+	md.votes = make(map[int]map[string]int)
+	md.credentials = make(map[string]*models.Credentials)
+	md.sessions = make(map[string]session)
+	md.events = nil
+	md.webhooks = make(map[int]*models.Webhook)
+	md.outbox = nil
+	md.deliveries = nil
+	md.forumSubs = make(map[string]map[string]*subscription)
+	md.threadSubs = make(map[int]map[string]*subscription)
+	md.reads = make(map[int]map[string]int)
+	md.notifications = nil
+	md.conversations = make(map[int]*conversation)
+	md.blocks = make(map[string]map[string]*models.Block)
+}
+
+// fold is the citext comparison key.
+func fold(s string) string {
+	return strings.ToLower(s)
+}
+
+func (md *MemoryDatabase) user(nickname string) *models.User {
+	return md.usersByKey[fold(nickname)]
+}
+
+func (md *MemoryDatabase) userByEmail(email string) *models.User {
+	for _, u := range md.users {
+		if fold(u.Email.String()) == fold(email) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (md *MemoryDatabase) CheckThread(ctx context.Context, slug string) (bool, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	_, ok := md.threadSlugs[fold(slug)]
+	return ok, nil
+}
+
+func (md *MemoryDatabase) CheckThreadById(ctx context.Context, id int) (bool, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	_, ok := md.threads[id]
+	return ok, nil
+}
+
+func (md *MemoryDatabase) CheckForum(ctx context.Context, slug string) (bool, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	_, ok := md.forums[fold(slug)]
+	return ok, nil
+}
+
+func (md *MemoryDatabase) CheckUser(ctx context.Context, user string) (bool, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	return md.user(user) != nil, nil
+}
+
+func (md *MemoryDatabase) CheckUserByEmail(ctx context.Context, email string) (bool, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	return md.userByEmail(email) != nil, nil
+}
+
+func (md *MemoryDatabase) CheckUserByNicknameOrEmail(ctx context.Context, nickname string, email string) (bool, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	return md.user(nickname) != nil || md.userByEmail(email) != nil, nil
+}
+
+func (md *MemoryDatabase) CheckPost(ctx context.Context, id int) (bool, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	_, ok := md.posts[id]
+	return ok, nil
+}
+
+func (md *MemoryDatabase) CheckVote(ctx context.Context, id int, nickname string) (bool, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	_, ok := md.votes[id][fold(nickname)]
+	return ok, nil
+}
+
+func (md *MemoryDatabase) AddNewForum(ctx context.Context, newForum *models.Forum) (error, bool) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if md.user(newForum.Owner) == nil {
+		return errForeignKey, false
+	}
+	if _, ok := md.forums[fold(newForum.Slug)]; ok {
+		return errUniqueViolation, false
+	}
+	forum := &models.Forum{
+		Title: newForum.Title,
+		Owner: newForum.Owner,
+		Slug:  newForum.Slug,
+	}
+	md.forums[fold(newForum.Slug)] = forum
+	md.emit(models.HookForumCreated, forum.Slug, forum)
+
+	return nil, false
+}
+
+func (md *MemoryDatabase) AddVote(ctx context.Context, id int, vote models.Vote) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	thread, ok := md.threads[id]
+	if !ok || md.user(vote.Nickname) == nil {
+		return errForeignKey
+	}
+	if md.votes[id] == nil {
+		md.votes[id] = make(map[string]int)
+	}
+	if _, ok := md.votes[id][fold(vote.Nickname)]; ok {
+		return errUniqueViolation
+	}
+	md.votes[id][fold(vote.Nickname)] = vote.Voice
+
+	if vote.Voice > 0 {
+		thread.Votes++
+	} else {
+		thread.Votes--
+	}
+	md.emitVote(thread, vote)
+	md.addEvent(models.EventThreadVoted, thread.Forum, id, *thread)
+
+	return nil
+}
+
+func (md *MemoryDatabase) GetForumCounts(ctx context.Context, slug string) (uint64, uint64, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	forum, ok := md.forums[fold(slug)]
+	if !ok {
+		return 0, 0, errNoRows
+	}
+
+	return forum.Posts, forum.Threads, nil
+}
+
+func (md *MemoryDatabase) GetForum(ctx context.Context, slug string) (models.Forum, int) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	forum, ok := md.forums[fold(slug)]
+	if !ok {
+		return models.Forum{}, http.StatusNotFound
+	}
+
+	return *forum, http.StatusOK
+}
+
+func (md *MemoryDatabase) GetPost(ctx context.Context, id int) (models.Post, int) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	p, ok := md.posts[id]
+	if !ok {
+		return models.Post{}, http.StatusNotFound
+	}
+
+	return p.view(), http.StatusOK
+}
+
+func (md *MemoryDatabase) GetPostNull(ctx context.Context, id int) (models.PostNullMessage, int) {
+	post, status := md.GetPost(ctx, id)
+	if status != http.StatusOK {
+		return models.PostNullMessage{}, status
+	}
+
+	return models.ConvertPostToNullMessage(post), http.StatusOK
+}
+
+func (md *MemoryDatabase) GetUser(ctx context.Context, name string) (models.User, int) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	user := md.user(name)
+	if user == nil {
+		return models.User{}, http.StatusNotFound
+	}
+
+	return *user, http.StatusOK
+}
+
+func (md *MemoryDatabase) GetThreadById(ctx context.Context, id int) (models.Thread, int) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	thread, ok := md.threads[id]
+	if !ok {
+		return models.Thread{}, http.StatusNotFound
+	}
+
+	return *thread, http.StatusOK
+}
+
+func (md *MemoryDatabase) GetThreadStatus(ctx context.Context, slug string) (models.Thread, int) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	thread, ok := md.threadSlugs[fold(slug)]
+	if !ok {
+		return models.Thread{}, http.StatusNotFound
+	}
+
+	return *thread, http.StatusOK
+}
+
+func (md *MemoryDatabase) GetThread(ctx context.Context, slug string) (models.Thread, error) {
+	thread, _ := md.GetThreadStatus(ctx, slug)
+
+	return thread, nil
+}
+
+func (md *MemoryDatabase) GetValueVote(ctx context.Context, id int, nickname string) (int, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	voice, ok := md.votes[id][fold(nickname)]
+	if !ok {
+		return 0, errNoRows
+	}
+
+	return voice, nil
+}
+
+func (md *MemoryDatabase) AddNewThread(ctx context.Context, newThread models.Thread) (uint64, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if md.user(newThread.Author) == nil {
+		return 0, errForeignKey
+	}
+	forum, ok := md.forums[fold(newThread.Forum)]
+	if !ok {
+		return 0, errForeignKey
+	}
+	if newThread.Slug != "" {
+		if _, ok := md.threadSlugs[fold(newThread.Slug)]; ok {
+			return 0, errUniqueViolation
+		}
+	}
+
+	md.lastThreadId++
+	thread := newThread
+	thread.Id = uint64(md.lastThreadId)
+	thread.Votes = 0
+	md.threads[md.lastThreadId] = &thread
+	if thread.Slug != "" {
+		md.threadSlugs[fold(thread.Slug)] = &thread
+	}
+	forum.Threads++
+	md.addForumUser(forum.Slug, thread.Author)
+	md.emit(models.HookThreadCreated, thread.Forum, thread)
+	for key, sub := range md.forumSubs[fold(thread.Forum)] {
+		if key != fold(thread.Author) {
+			md.notify(sub.nickname, models.Notification{Type: models.NotifyThread, Actor: thread.Author,
+				Forum: thread.Forum, Thread: int(thread.Id), Created: strfmt.DateTime(time.Now())})
+		}
+	}
+
+	return thread.Id, nil
+}
+
+func (md *MemoryDatabase) AddPost(ctx context.Context, newPosts []*models.Post, thread models.Thread, now time.Time) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	forum, ok := md.forums[fold(thread.Forum)]
+	if !ok {
+		return http.StatusNotFound
+	}
+
+	// Parents are resolved first, like the post_path trigger which runs
+	// before the foreign key checks on author.
+	batch := make([]*post, len(newPosts))
+	inBatch := make(map[int]*post, len(newPosts))
+	for i := range newPosts {
+		p := &post{Post: *newPosts[i]}
+		p.Id = md.lastPostId + i + 1
+		p.Thread = int(thread.Id)
+		p.Forum = thread.Forum
+		p.Created = strfmt.DateTime(now)
+		p.IsEdited = false
+
+		if p.Parent == 0 {
+			p.path = []int{p.Id}
+		} else {
+			parent, ok := md.posts[p.Parent]
+			if !ok {
+				parent, ok = inBatch[p.Parent]
+			}
+			if !ok || parent.Thread != p.Thread {
+				return http.StatusConflict
+			}
+			p.path = append(append([]int{}, parent.path...), p.Id)
+		}
+		batch[i] = p
+		inBatch[p.Id] = p
+	}
+	for _, p := range batch {
+		if md.user(p.Author) == nil {
+			return http.StatusNotFound
+		}
+	}
+
+	md.lastPostId += len(batch)
+	for i, p := range batch {
+		md.posts[p.Id] = p
+		md.threadPosts[p.Thread] = append(md.threadPosts[p.Thread], p)
+		md.addForumUser(forum.Slug, p.Author)
+		*newPosts[i] = p.Post
+		md.emit(models.HookPostCreated, p.Forum, p.view())
+	}
+	for _, p := range batch {
+		md.notifyPost(p)
+	}
+	forum.Posts += uint64(len(batch))
+	if t, ok := md.threads[int(thread.Id)]; ok {
+		lastPostAt := strfmt.DateTime(now)
+		t.PostCount += len(batch)
+		t.LastPostAt = &lastPostAt
+	}
+	md.addEvent(models.EventPostsCreated, forum.Slug, int(thread.Id), newPosts)
+
+	return http.StatusCreated
+}
+
+func (md *MemoryDatabase) addForumUser(slug string, author string) bool {
+	user := md.user(author)
+	if user == nil {
+		return false
+	}
+	members, ok := md.forumUsers[fold(slug)]
+	if !ok {
+		members = make(map[string]string)
+		md.forumUsers[fold(slug)] = members
+	}
+	if _, ok := members[fold(user.Nickname)]; ok {
+		return false
+	}
+	members[fold(user.Nickname)] = user.Nickname
+
+	return true
+}
+
+func (md *MemoryDatabase) GetForumUsers(ctx context.Context, slug string, limit int, since string, desc bool, excludeBanned bool) (models.Users, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	var keys []string
+	for key := range md.forumUsers[fold(slug)] {
+		if excludeBanned && len(md.activeBans(slug, []string{key})) != 0 {
+			continue
+		}
+		if since != "" {
+			if desc && key >= fold(since) || !desc && key <= fold(since) {
+				continue
+			}
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if desc {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	}
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	users := models.Users{}
+	for _, key := range keys {
+		users = append(users, *md.usersByKey[key])
+	}
+
+	return users, nil
+}
+
+func (md *MemoryDatabase) selectPosts(id int, filter func(p *post) bool, less func(a, b *post) bool, limit int) models.Posts {
+	var selected []*post
+	for _, p := range md.threadPosts[id] {
+		if filter == nil || filter(p) {
+			selected = append(selected, p)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return less(selected[i], selected[j])
+	})
+	if limit >= 0 && len(selected) > limit {
+		selected = selected[:limit]
+	}
+
+	posts := models.Posts{}
+	for _, p := range selected {
+		posts = append(posts, p.view())
+	}
+	return posts
+}
+
+func createdLess(a, b *post) bool {
+	ta, tb := time.Time(a.Created), time.Time(b.Created)
+	if !ta.Equal(tb) {
+		return ta.Before(tb)
+	}
+	return a.Id < b.Id
+}
+
+func createdGreater(a, b *post) bool {
+	return createdLess(b, a)
+}
+
+// comparePaths orders materialized paths the way PostgreSQL orders BIGINT[].
+func comparePaths(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+func pathLess(a, b *post) bool {
+	return comparePaths(a.path, b.path) < 0
+}
+
+func pathGreater(a, b *post) bool {
+	return comparePaths(a.path, b.path) > 0
+}
+
+func (md *MemoryDatabase) GetPostsFlat(ctx context.Context, id int, limit int, after *models.PostKey) (models.Posts, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	return md.selectPosts(id, func(p *post) bool {
+		return after == nil || createdLess(keyPost(after), p)
+	}, createdLess, limit), nil
+}
+
+func (md *MemoryDatabase) GetPostsFlatDesc(ctx context.Context, id int, limit int, after *models.PostKey) (models.Posts, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	return md.selectPosts(id, func(p *post) bool {
+		return after == nil || createdGreater(keyPost(after), p)
+	}, createdGreater, limit), nil
+}
+
+// keyPost stands for the post at a position of the flat sort.
+func keyPost(key *models.PostKey) *post {
+	p := &post{}
+	p.Id = key.Id
+	if key.Created != nil {
+		p.Created = strfmt.DateTime(*key.Created)
+	}
+	return p
+}
+
+func (md *MemoryDatabase) GetPostsTree(ctx context.Context, id int, limit int) (models.Posts, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	return md.selectPosts(id, nil, pathLess, limit), nil
+}
+
+func (md *MemoryDatabase) GetPostsTreeDesc(ctx context.Context, id int, limit int) (models.Posts, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	return md.selectPosts(id, nil, pathGreater, limit), nil
+}
+
+func (md *MemoryDatabase) GetPostsTreeSince(ctx context.Context, id int, limit int, since int) (models.Posts, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	sincePost, ok := md.posts[since]
+	if !ok {
+		return models.Posts{}, nil
+	}
+	return md.selectPosts(id, func(p *post) bool {
+		return comparePaths(p.path, sincePost.path) > 0
+	}, pathLess, limit), nil
+}
+
+func (md *MemoryDatabase) GetPostsTreeSinceDesc(ctx context.Context, id int, limit int, since int) (models.Posts, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	sincePost, ok := md.posts[since]
+	if !ok {
+		return models.Posts{}, nil
+	}
+	return md.selectPosts(id, func(p *post) bool {
+		return comparePaths(p.path, sincePost.path) < 0
+	}, pathGreater, limit), nil
+}
+
+// parentTree returns whole subtrees of the first limit root posts accepted
+// by filter, ordered by root and then by path inside each subtree.
+func (md *MemoryDatabase) parentTree(id int, limit int, filter func(root int) bool, desc bool) models.Posts {
+	var roots []int
+	for _, p := range md.threadPosts[id] {
+		if p.Parent == 0 && (filter == nil || filter(p.Id)) {
+			roots = append(roots, p.Id)
+		}
+	}
+	sort.Ints(roots)
+	if desc {
+		sort.Sort(sort.Reverse(sort.IntSlice(roots)))
+	}
+	if len(roots) > limit {
+		roots = roots[:limit]
+	}
+
+	rank := make(map[int]int, len(roots))
+	for i, root := range roots {
+		rank[root] = i
+	}
+	return md.selectPosts(id, func(p *post) bool {
+		_, ok := rank[p.path[0]]
+		return ok
+	}, func(a, b *post) bool {
+		if a.path[0] != b.path[0] {
+			return rank[a.path[0]] < rank[b.path[0]]
+		}
+		return pathLess(a, b)
+	}, -1)
+}
+
+func (md *MemoryDatabase) GetPostsParentTree(ctx context.Context, id int, limit int) (models.Posts, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	return md.parentTree(id, limit, nil, false), nil
+}
+
+func (md *MemoryDatabase) GetPostsParentTreeDesc(ctx context.Context, id int, limit int) (models.Posts, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	return md.parentTree(id, limit, nil, true), nil
+}
+
+func (md *MemoryDatabase) GetPostsParentTreeSince(ctx context.Context, id int, limit int, since int) (models.Posts, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	sincePost, ok := md.posts[since]
+	if !ok {
+		return models.Posts{}, nil
+	}
+	return md.parentTree(id, limit, func(root int) bool {
+		return root > sincePost.path[0]
+	}, false), nil
+}
+
+func (md *MemoryDatabase) GetPostsParentTreeSinceDesc(ctx context.Context, id int, limit int, since int) (models.Posts, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	sincePost, ok := md.posts[since]
+	if !ok {
+		return models.Posts{}, nil
+	}
+	return md.parentTree(id, limit, func(root int) bool {
+		return root < sincePost.path[0]
+	}, true), nil
+}
+
+func (md *MemoryDatabase) AddForumUsers(ctx context.Context, slug string, author string) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if _, ok := md.forums[fold(slug)]; !ok || md.user(author) == nil {
+		return errForeignKey
+	}
+	if !md.addForumUser(slug, author) {
+		return errUniqueViolation
+	}
+
+	return nil
+}
+
+func (md *MemoryDatabase) GetForumThreads(ctx context.Context, query models.ThreadQuery) (models.Threads, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	order := query.Sort
+	if order == "" {
+		order = models.ThreadsByCreated
+	}
+	var sinceTime time.Time
+	if query.Since != "" && query.After == nil && order == models.ThreadsByCreated {
+		var err error
+		sinceTime, err = time.Parse(time.RFC3339Nano, query.Since)
+		if err != nil {
+			return nil, errInvalidTimestamp
+		}
+	}
+
+	// compare orders two keys by the query's column alone.
+	compare := func(a, b models.ThreadKey) int {
+		switch order {
+		case models.ThreadsByVotes:
+			return a.Votes - b.Votes
+		case models.ThreadsByPosts:
+			return a.Posts - b.Posts
+		case models.ThreadsByActivity:
+			if a.Activity == nil || b.Activity == nil || a.Activity.Equal(*b.Activity) {
+				return 0
+			}
+			if a.Activity.Before(*b.Activity) {
+				return -1
+			}
+			return 1
+		}
+		if a.Created.Equal(b.Created) {
+			return 0
+		}
+		if a.Created.Before(b.Created) {
+			return -1
+		}
+		return 1
+	}
+	before := func(a, b models.ThreadKey) bool {
+		if a.Pinned != b.Pinned {
+			return a.Pinned
+		}
+		if c := compare(a, b); c != 0 {
+			return c < 0 != query.Desc
+		}
+		return a.Id != b.Id && (a.Id < b.Id) != query.Desc
+	}
+
+	var selected []*models.Thread
+	for _, thread := range md.threads {
+		if fold(thread.Forum) != fold(query.Forum) {
+			continue
+		}
+		created := time.Time(thread.Created)
+		if query.Author != "" && fold(thread.Author) != fold(query.Author) ||
+			query.Title != "" && !strings.Contains(fold(thread.Title), fold(query.Title)) ||
+			query.From != nil && created.Before(*query.From) ||
+			query.To != nil && created.After(*query.To) {
+			continue
+		}
+		if query.After != nil {
+			if !before(*query.After, thread.Key(order)) {
+				continue
+			}
+		} else if query.Since != "" && order == models.ThreadsByCreated {
+			if query.Desc && created.After(sinceTime) || !query.Desc && created.Before(sinceTime) {
+				continue
+			}
+		}
+		selected = append(selected, thread)
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return before(selected[i].Key(order), selected[j].Key(order))
+	})
+	if len(selected) > query.Limit {
+		selected = selected[:query.Limit]
+	}
+
+	threads := models.Threads{}
+	for _, thread := range selected {
+		threads = append(threads, *thread)
+	}
+	return threads, nil
+}
+
+func (md *MemoryDatabase) EditMessage(ctx context.Context, id int, message string, editor string) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	p, ok := md.posts[id]
+	if !ok {
+		return nil
+	}
+	if editor != "" {
+		u := md.user(editor)
+		if u == nil {
+			return errForeignKey
+		}
+		editor = u.Nickname
+	}
+
+	now := strfmt.DateTime(time.Now())
+	p.revisions = append(p.revisions, models.Revision{
+		Revision: len(p.revisions) + 1,
+		Message:  p.Message,
+		Editor:   editor,
+		Created:  now,
+	})
+	p.IsEdited = true
+	p.Message = message
+	p.EditCount++
+	p.LastEditedAt = &now
+	if p.Message != p.revisions[len(p.revisions)-1].Message {
+		md.emit(models.HookPostUpdated, p.Forum, p.view())
+	}
+	md.addEvent(models.EventPostEdited, p.Forum, p.Thread, p.view())
+
+	return nil
+}
+
+func (md *MemoryDatabase) GetPostRevisions(ctx context.Context, id int) (models.Revisions, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	revisions := models.Revisions{}
+	if p, ok := md.posts[id]; ok {
+		revisions = append(revisions, p.revisions...)
+	}
+
+	return revisions, nil
+}
+
+func (md *MemoryDatabase) DeletePost(ctx context.Context, id int) int {
+	return md.setPostDeleted(id, true)
+}
+
+func (md *MemoryDatabase) RestorePost(ctx context.Context, id int) int {
+	return md.setPostDeleted(id, false)
+}
+
+func (md *MemoryDatabase) setPostDeleted(id int, deleted bool) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	p, ok := md.posts[id]
+	if !ok {
+		return http.StatusNotFound
+	}
+	if p.IsDeleted == deleted {
+		return http.StatusOK
+	}
+	p.IsDeleted = deleted
+
+	if forum, ok := md.forums[fold(p.Forum)]; ok {
+		if deleted {
+			forum.Posts--
+		} else {
+			forum.Posts++
+		}
+	}
+	if thread, ok := md.threads[p.Thread]; ok {
+		if deleted {
+			thread.PostCount--
+		} else {
+			thread.PostCount++
+		}
+	}
+	md.emit(models.HookPostUpdated, p.Forum, p.view())
+
+	return http.StatusOK
+}
+
+func (md *MemoryDatabase) UpdateThread(ctx context.Context, slugOrId string, thread models.Thread) (models.Thread, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	old, ok := md.threadSlugs[fold(slugOrId)]
+	if !ok {
+		return models.Thread{}, errNoRows
+	}
+	md.updateThread(old, func(t *models.Thread) {
+		t.Message = thread.Message
+		t.Title = thread.Title
+	})
+	md.addEvent(models.EventThreadUpdated, old.Forum, int(old.Id), *old)
+
+	return *old, nil
+}
+
+func (md *MemoryDatabase) UpdateVote(ctx context.Context, id int, vote models.Vote) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if _, ok := md.votes[id][fold(vote.Nickname)]; !ok {
+		return nil
+	}
+	md.votes[id][fold(vote.Nickname)] = vote.Voice
+
+	if vote.Voice > 0 {
+		md.threads[id].Votes += 2
+	} else {
+		md.threads[id].Votes -= 2
+	}
+	md.emitVote(md.threads[id], vote)
+	md.addEvent(models.EventThreadVoted, md.threads[id].Forum, id, *md.threads[id])
+
+	return nil
+}
+
+func (md *MemoryDatabase) UpdateThreadById(ctx context.Context, id int, thread models.Thread) (models.Thread, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	old, ok := md.threads[id]
+	if !ok {
+		return models.Thread{}, errNoRows
+	}
+	md.updateThread(old, func(t *models.Thread) {
+		t.Message = thread.Message
+		t.Title = thread.Title
+	})
+	md.addEvent(models.EventThreadUpdated, old.Forum, int(old.Id), *old)
+
+	return *old, nil
+}
+
+func (md *MemoryDatabase) DeleteThread(ctx context.Context, id int) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	thread, ok := md.threads[id]
+	if !ok {
+		return http.StatusNotFound
+	}
+
+	authors := []string{thread.Author}
+	live := 0
+	for _, p := range md.threadPosts[id] {
+		authors = append(authors, p.Author)
+		if !p.IsDeleted {
+			live++
+		}
+		delete(md.posts, p.Id)
+	}
+	delete(md.threadPosts, id)
+	delete(md.votes, id)
+	delete(md.threads, id)
+	if thread.Slug != "" {
+		delete(md.threadSlugs, fold(thread.Slug))
+	}
+	md.emit(models.HookThreadDeleted, thread.Forum, thread)
+	kept := md.notifications[:0]
+	for _, n := range md.notifications {
+		if n.Thread != id {
+			kept = append(kept, n)
+		}
+	}
+	md.notifications = kept
+	delete(md.threadSubs, id)
+	delete(md.reads, id)
+
+	if forum, ok := md.forums[fold(thread.Forum)]; ok {
+		forum.Threads--
+		forum.Posts -= uint64(live)
+	}
+
+	members := md.forumUsers[fold(thread.Forum)]
+	for _, author := range authors {
+		if !md.hasContent(thread.Forum, author) {
+			delete(members, fold(author))
+		}
+	}
+
+	return http.StatusOK
+}
+
+// hasContent tells whether author has a thread or a post in forum.
+func (md *MemoryDatabase) hasContent(forum string, author string) bool {
+	for _, t := range md.threads {
+		if fold(t.Forum) == fold(forum) && fold(t.Author) == fold(author) {
+			return true
+		}
+	}
+	for _, p := range md.posts {
+		if fold(p.Forum) == fold(forum) && fold(p.Author) == fold(author) {
+			return true
+		}
+	}
+	return false
+}
+
+func (md *MemoryDatabase) SetThreadArchived(ctx context.Context, id int, archived bool) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	thread, ok := md.threads[id]
+	if !ok {
+		return http.StatusNotFound
+	}
+	md.updateThread(thread, func(t *models.Thread) {
+		t.IsArchived = archived
+	})
+
+	return http.StatusOK
+}
+
+func (md *MemoryDatabase) ModerateThread(ctx context.Context, id int, m models.ThreadModeration) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	thread, ok := md.threads[id]
+	if !ok {
+		return http.StatusNotFound
+	}
+	md.updateThread(thread, func(t *models.Thread) {
+		if m.Locked != nil {
+			t.IsLocked = *m.Locked
+		}
+		if m.Pinned != nil {
+			t.IsPinned = *m.Pinned
+		}
+		if m.Closed != nil {
+			t.IsClosed = *m.Closed
+			t.CloseReason = ""
+			if *m.Closed {
+				t.CloseReason = m.Reason
+			}
+		}
+	})
+
+	return http.StatusOK
+}
+
+func (md *MemoryDatabase) IncrementThreads(ctx context.Context, forum string) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if f, ok := md.forums[fold(forum)]; ok {
+		f.Threads++
+	}
+
+	return nil
+}
+
+func (md *MemoryDatabase) IncrementPosts(ctx context.Context, forum string) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if f, ok := md.forums[fold(forum)]; ok {
+		f.Posts++
+	}
+
+	return nil
+}
+
+func (md *MemoryDatabase) Clear(ctx context.Context) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	md.reset()
+
+	return nil
+}
+
+func (md *MemoryDatabase) Status(ctx context.Context) (models.Status, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	var posts uint64
+	for _, p := range md.posts {
+		if !p.IsDeleted {
+			posts++
+		}
+	}
+
+	return models.Status{
+		Forum:  uint64(len(md.forums)),
+		Post:   posts,
+		Thread: uint64(len(md.threads)),
+		User:   uint64(len(md.users)),
+	}, nil
+}
+
+func (md *MemoryDatabase) CreateUser(ctx context.Context, nickname string, user models.User) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if md.user(nickname) != nil || md.userByEmail(user.Email.String()) != nil {
+		return errUniqueViolation
+	}
+
+	user.Nickname = nickname
+	md.users = append(md.users, &user)
+	md.usersByKey[fold(nickname)] = &user
+	md.emit(models.HookUserCreated, "", user)
+
+	return nil
+}
+
+func (md *MemoryDatabase) UpdateUser(ctx context.Context, nickname string, user models.User) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	old := md.user(nickname)
+	if old == nil {
+		return nil
+	}
+	if other := md.userByEmail(user.Email.String()); other != nil && other != old {
+		return errUniqueViolation
+	}
+	old.Fullname = user.Fullname
+	old.About = user.About
+	old.Email = user.Email
+	md.emit(models.HookUserUpdated, "", old)
+
+	return nil
+}
+
+func (md *MemoryDatabase) GetUserByNicknameOrEmail(ctx context.Context, nickname string, email string) (models.Users, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	users := models.Users{}
+	for _, u := range md.users {
+		if fold(u.Nickname) == fold(nickname) || fold(u.Email.String()) == fold(email) {
+			users = append(users, *u)
+		}
+	}
+
+	return users, nil
+}
+
+func (md *MemoryDatabase) SetCredentials(ctx context.Context, nickname string, passwordHash []byte) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	user := md.user(nickname)
+	if user == nil {
+		return nil
+	}
+	if c, ok := md.credentials[fold(nickname)]; ok {
+		c.PasswordHash = passwordHash
+		return nil
+	}
+	md.credentials[fold(nickname)] = &models.Credentials{
+		Nickname:     user.Nickname,
+		PasswordHash: passwordHash,
+		Role:         models.RoleMember,
+	}
+
+	return nil
+}
+
+func (md *MemoryDatabase) GetCredentials(ctx context.Context, nickname string) (models.Credentials, int) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	c, ok := md.credentials[fold(nickname)]
+	if !ok {
+		return models.Credentials{}, http.StatusNotFound
+	}
+
+	return *c, http.StatusOK
+}
+
+func (md *MemoryDatabase) AddSession(ctx context.Context, tokenHash []byte, nickname string, expires time.Time) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	user := md.user(nickname)
+	if user == nil {
+		return errForeignKey
+	}
+	md.sessions[string(tokenHash)] = session{nickname: user.Nickname, expires: expires}
+
+	return nil
+}
+
+func (md *MemoryDatabase) GetSession(ctx context.Context, tokenHash []byte) (models.Caller, int) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	s, ok := md.sessions[string(tokenHash)]
+	if !ok || !time.Now().Before(s.expires) {
+		return models.Caller{}, http.StatusNotFound
+	}
+	caller := models.Caller{Nickname: s.nickname, Role: models.RoleMember}
+	if c, ok := md.credentials[fold(s.nickname)]; ok {
+		caller.Role = c.Role
+	}
+
+	return caller, http.StatusOK
+}
+
+func (md *MemoryDatabase) DeleteSession(ctx context.Context, tokenHash []byte) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	delete(md.sessions, string(tokenHash))
+
+	return nil
+}
+
+func (md *MemoryDatabase) SetRole(ctx context.Context, nickname string, role string) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	c, ok := md.credentials[fold(nickname)]
+	if !ok {
+		return http.StatusNotFound
+	}
+	c.Role = role
+
+	return http.StatusOK
+}
+
+func (md *MemoryDatabase) AddModerator(ctx context.Context, forum string, nickname string) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	user := md.user(nickname)
+	if user == nil || md.forums[fold(forum)] == nil {
+		return nil
+	}
+	moderators, ok := md.moderators[fold(forum)]
+	if !ok {
+		moderators = make(map[string]string)
+		md.moderators[fold(forum)] = moderators
+	}
+	moderators[fold(nickname)] = user.Nickname
+
+	return nil
+}
+
+func (md *MemoryDatabase) RemoveModerator(ctx context.Context, forum string, nickname string) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	moderators := md.moderators[fold(forum)]
+	if _, ok := moderators[fold(nickname)]; !ok {
+		return http.StatusNotFound
+	}
+	delete(moderators, fold(nickname))
+
+	return http.StatusOK
+}
+
+func (md *MemoryDatabase) GetModerators(ctx context.Context, forum string) (models.Users, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	var keys []string
+	for key := range md.moderators[fold(forum)] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	users := models.Users{}
+	for _, key := range keys {
+		users = append(users, *md.usersByKey[key])
+	}
+
+	return users, nil
+}
+
+func (md *MemoryDatabase) IsModerator(ctx context.Context, forum string, nickname string) (bool, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	_, ok := md.moderators[fold(forum)][fold(nickname)]
+
+	return ok, nil
+}
+
+// banKey identifies a ban by the folded forum, empty for site-wide bans,
+// and nickname.
+type banKey struct {
+	forum    string
+	nickname string
+}
+
+// activeBans returns the bans in force on nicknames in forum.
+func (md *MemoryDatabase) activeBans(forum string, nicknames []string) models.Bans {
+	now := time.Now()
+	bans := models.Bans{}
+	for _, nickname := range nicknames {
+		for _, f := range []string{"", fold(forum)} {
+			ban, ok := md.bans[banKey{forum: f, nickname: fold(nickname)}]
+			if ok && (ban.Expires == nil || now.Before(time.Time(*ban.Expires))) {
+				bans = append(bans, *ban)
+			}
+		}
+	}
+
+	return bans
+}
+
+func (md *MemoryDatabase) AddBan(ctx context.Context, ban models.Ban) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if md.user(ban.Nickname) == nil || ban.Forum != "" && md.forums[fold(ban.Forum)] == nil {
+		return nil
+	}
+	md.bans[banKey{forum: fold(ban.Forum), nickname: fold(ban.Nickname)}] = &ban
+
+	return nil
+}
+
+func (md *MemoryDatabase) RemoveBan(ctx context.Context, forum string, nickname string) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	key := banKey{forum: fold(forum), nickname: fold(nickname)}
+	if _, ok := md.bans[key]; !ok {
+		return http.StatusNotFound
+	}
+	delete(md.bans, key)
+
+	return http.StatusOK
+}
+
+func (md *MemoryDatabase) GetForumBans(ctx context.Context, forum string) (models.Bans, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	var nicknames []string
+	for key := range md.bans {
+		if key.forum == "" || key.forum == fold(forum) {
+			nicknames = append(nicknames, key.nickname)
+		}
+	}
+	sort.Strings(nicknames)
+	bans := md.activeBans(forum, dedupe(nicknames))
+	sort.SliceStable(bans, func(i, j int) bool {
+		return time.Time(bans[i].Created).After(time.Time(bans[j].Created))
+	})
+
+	return bans, nil
+}
+
+func (md *MemoryDatabase) FindBans(ctx context.Context, forum string, nicknames []string) (models.Bans, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	return md.activeBans(forum, dedupe(nicknames)), nil
+}
+
+// dedupe drops repeated nicknames, comparing them as citext does.
+func dedupe(nicknames []string) []string {
+	seen := make(map[string]bool, len(nicknames))
+	out := nicknames[:0:0]
+	for _, nickname := range nicknames {
+		if !seen[fold(nickname)] {
+			seen[fold(nickname)] = true
+			out = append(out, nickname)
+		}
+	}
+	return out
+}
+
+// words splits text for the search; unlike PostgreSQL it neither stems
+// nor drops stop words.
+var words = regexp.MustCompile(`[\pL\pN_]+`)
+
+// match ranks text against the search terms: the share of its words that
+// are terms, or 0 unless every term occurs.
+func match(text string, terms map[string]bool) float32 {
+	found := make(map[string]bool, len(terms))
+	all := words.FindAllString(strings.ToLower(text), -1)
+	hits := 0
+	for _, w := range all {
+		if terms[w] {
+			found[w] = true
+			hits++
+		}
+	}
+	if len(found) != len(terms) {
+		return 0
+	}
+	return float32(hits) / float32(len(all))
+}
+
+// highlight escapes text as HTML and puts the words among terms in <mark>
+// tags.
+func highlight(text string, terms map[string]bool) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range words.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:loc[0]]))
+		w := text[loc[0]:loc[1]]
+		if terms[strings.ToLower(w)] {
+			b.WriteString("<mark>" + w + "</mark>")
+		} else {
+			b.WriteString(w)
+		}
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+func (md *MemoryDatabase) Search(ctx context.Context, query models.SearchQuery) (models.SearchHits, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	terms := make(map[string]bool)
+	for _, w := range words.FindAllString(strings.ToLower(query.Text), -1) {
+		terms[w] = true
+	}
+	hits := models.SearchHits{}
+	if len(terms) == 0 {
+		return hits, nil
+	}
+
+	keep := func(forum, author string, created strfmt.DateTime) bool {
+		t := time.Time(created)
+		return (query.Forum == "" || fold(forum) == fold(query.Forum)) &&
+			(query.Author == "" || fold(author) == fold(query.Author)) &&
+			(query.From == nil || !t.Before(*query.From)) &&
+			(query.To == nil || t.Before(*query.To))
+	}
+
+	if query.Kind != models.HitThread {
+		for _, p := range md.posts {
+			if p.IsDeleted || query.Thread != 0 && p.Thread != query.Thread || !keep(p.Forum, p.Author, p.Created) {
+				continue
+			}
+			if rank := match(p.Message, terms); rank > 0 {
+				hits = append(hits, models.SearchHit{Kind: models.HitPost, Id: int64(p.Id), Thread: int64(p.Thread),
+					Forum: p.Forum, Author: p.Author, Snippet: highlight(p.Message, terms), Rank: rank, Created: p.Created})
+			}
+		}
+	}
+	if query.Kind != models.HitPost {
+		for _, t := range md.threads {
+			if query.Thread != 0 && int(t.Id) != query.Thread || !keep(t.Forum, t.Author, t.Created) {
+				continue
+			}
+			text := t.Title + " " + t.Message
+			if rank := match(text, terms); rank > 0 {
+				hits = append(hits, models.SearchHit{Kind: models.HitThread, Id: int64(t.Id), Thread: int64(t.Id),
+					Forum: t.Forum, Author: t.Author, Title: t.Title, Snippet: highlight(text, terms), Rank: rank, Created: t.Created})
+			}
+		}
+	}
+
+	before := func(a, b models.SearchKey) bool {
+		if a.Rank != b.Rank {
+			return a.Rank > b.Rank
+		}
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind
+		}
+		return a.Id > b.Id
+	}
+	sort.Slice(hits, func(i, j int) bool { return before(hits[i].Key(), hits[j].Key()) })
+	if query.After != nil {
+		i := sort.Search(len(hits), func(i int) bool { return before(*query.After, hits[i].Key()) })
+		hits = hits[i:]
+	}
+	if len(hits) > query.Limit {
+		hits = hits[:query.Limit]
+	}
+
+	return hits, nil
+}
+
+// trigrams splits s into the trigrams pg_trgm compares: those of each word
+// padded with two spaces in front and one behind.
+func trigrams(s string) map[string]bool {
+	out := make(map[string]bool)
+	for _, w := range words.FindAllString(strings.ToLower(s), -1) {
+		r := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			out[string(r[i:i+3])] = true
+		}
+	}
+	return out
+}
+
+// similarity is the share of trigrams a and b have in common, as in pg_trgm.
+func similarity(a, b string) float32 {
+	ta, tb := trigrams(a), trigrams(b)
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+	if all := len(ta) + len(tb) - common; all != 0 {
+		return float32(common) / float32(all)
+	}
+	return 0
+}
+
+// wordPrefix tells whether s or one of its space-separated words starts
+// with prefix.
+func wordPrefix(s, prefix string) bool {
+	s = strings.ToLower(s)
+	if strings.HasPrefix(s, prefix) {
+		return true
+	}
+	return strings.Contains(s, " "+prefix)
+}
+
+// similarityThreshold is the default pg_trgm.similarity_threshold.
+const similarityThreshold = 0.3
+
+func (md *MemoryDatabase) FindUsers(ctx context.Context, query models.UserQuery) ([]models.UserMatch, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	text := strings.ToLower(query.Text)
+	var members map[string]string
+	if query.Forum != "" {
+		members = md.forumUsers[fold(query.Forum)]
+	}
+
+	matches := []models.UserMatch{}
+	for _, u := range md.users {
+		if query.Forum != "" {
+			if _, ok := members[fold(u.Nickname)]; !ok {
+				continue
+			}
+		}
+		m := models.UserMatch{User: *u}
+		if text != "" {
+			switch {
+			case strings.HasPrefix(fold(u.Nickname), text):
+				m.Score = 2
+			case wordPrefix(u.Fullname, text):
+				m.Score = 1.5
+			default:
+				m.Score = similarity(u.Nickname, text)
+				if s := similarity(u.Fullname, text); s > m.Score {
+					m.Score = s
+				}
+				if m.Score < similarityThreshold {
+					continue
+				}
+			}
+		}
+		matches = append(matches, m)
+	}
+
+	before := func(a, b models.UserKey) bool {
+		if query.Sort == models.UsersByNickname {
+			if query.Desc {
+				return fold(a.Nickname) > fold(b.Nickname)
+			}
+			return fold(a.Nickname) < fold(b.Nickname)
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return fold(a.Nickname) < fold(b.Nickname)
+	}
+	sort.Slice(matches, func(i, j int) bool { return before(matches[i].Key(), matches[j].Key()) })
+	if query.After != nil {
+		i := sort.Search(len(matches), func(i int) bool { return before(*query.After, matches[i].Key()) })
+		matches = matches[i:]
+	}
+	if len(matches) > query.Limit {
+		matches = matches[:query.Limit]
+	}
+
+	return matches, nil
+}
+
+// addEvent appends an event about a change to the log, under the lock the
+// change is made with, and wakes up the listeners.
+func (md *MemoryDatabase) addEvent(kind string, forum string, thread int, data interface{}) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	md.lastEventId++
+	md.events = append(md.events, models.Event{
+		Id:      md.lastEventId,
+		Type:    kind,
+		Forum:   forum,
+		Thread:  thread,
+		Data:    b,
+		Created: strfmt.DateTime(time.Now()),
+	})
+
+	for wake := range md.listeners {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (md *MemoryDatabase) GetEvents(ctx context.Context, query models.EventQuery) (models.Events, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	i := sort.Search(len(md.events), func(i int) bool { return md.events[i].Id > query.After })
+	events := models.Events{}
+	for ; i < len(md.events) && len(events) < query.Limit; i++ {
+		if query.Matches(md.events[i]) {
+			events = append(events, md.events[i])
+		}
+	}
+
+	return events, nil
+}
+
+func (md *MemoryDatabase) LastEventId(ctx context.Context) (int64, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	return md.lastEventId, nil
+}
+
+func (md *MemoryDatabase) ListenEvents(ctx context.Context, notify func()) error {
+	wake := make(chan struct{}, 1)
+	md.mu.Lock()
+	md.listeners[wake] = true
+	md.mu.Unlock()
+	defer func() {
+		md.mu.Lock()
+		delete(md.listeners, wake)
+		md.mu.Unlock()
+	}()
+	notify()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+			notify()
+		}
+	}
+}
+
+func (md *MemoryDatabase) PruneEvents(ctx context.Context, before time.Time) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	i := sort.Search(len(md.events), func(i int) bool { return !time.Time(md.events[i].Created).Before(before) })
+	md.events = append(models.Events{}, md.events[i:]...)
+
+	return nil
+}
+
+// emit mirrors the outbox triggers: it adds an event to the outbox when a
+// webhook takes it.
+func (md *MemoryDatabase) emit(kind string, forum string, data interface{}) {
+	taken := false
+	for _, hook := range md.webhooks {
+		if hook.Takes(kind, forum) {
+			taken = true
+			break
+		}
+	}
+	if !taken {
+		return
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	md.lastOutboxId++
+	md.outbox = append(md.outbox, &hookEvent{HookEvent: models.HookEvent{
+		Id:      md.lastOutboxId,
+		Type:    kind,
+		Forum:   forum,
+		Data:    b,
+		Created: strfmt.DateTime(time.Now()),
+	}})
+}
+
+func (md *MemoryDatabase) emitVote(thread *models.Thread, vote models.Vote) {
+	if u := md.user(vote.Nickname); u != nil {
+		vote.Nickname = u.Nickname
+	}
+	md.emit(models.HookVoteCast, thread.Forum, map[string]interface{}{
+		"thread":   thread.Id,
+		"nickname": vote.Nickname,
+		"voice":    vote.Voice,
+	})
+}
+
+// updateThread applies change to a thread and, like the
+// outbox_threads_update trigger, emits thread.updated only when it changed
+// what a client can change.
+func (md *MemoryDatabase) updateThread(thread *models.Thread, change func(*models.Thread)) {
+	before := *thread
+	change(thread)
+	if before.Title != thread.Title || before.Message != thread.Message || before.Slug != thread.Slug ||
+		before.IsArchived != thread.IsArchived || before.IsLocked != thread.IsLocked ||
+		before.IsPinned != thread.IsPinned || before.IsClosed != thread.IsClosed ||
+		before.CloseReason != thread.CloseReason {
+		md.emit(models.HookThreadUpdated, thread.Forum, thread)
+	}
+}
+
+func (md *MemoryDatabase) AddWebhook(ctx context.Context, hook *models.Webhook) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if hook.Forum != "" {
+		f, ok := md.forums[fold(hook.Forum)]
+		if !ok {
+			return errForeignKey
+		}
+		hook.Forum = f.Slug
+	}
+	if hook.Events == nil {
+		hook.Events = []string{}
+	}
+	md.lastWebhookId++
+	hook.Id = md.lastWebhookId
+	hook.Created = strfmt.DateTime(time.Now())
+	stored := *hook
+	md.webhooks[hook.Id] = &stored
+
+	return nil
+}
+
+func (md *MemoryDatabase) GetWebhooks(ctx context.Context, forum string) (models.Webhooks, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	hooks := models.Webhooks{}
+	for _, hook := range md.webhooks {
+		if forum == "" || fold(hook.Forum) == fold(forum) {
+			hooks = append(hooks, *hook)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].Id < hooks[j].Id
+	})
+
+	return hooks, nil
+}
+
+func (md *MemoryDatabase) GetWebhook(ctx context.Context, id int) (models.Webhook, int) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	hook, ok := md.webhooks[id]
+	if !ok {
+		return models.Webhook{}, http.StatusNotFound
+	}
+
+	return *hook, http.StatusOK
+}
+
+func (md *MemoryDatabase) DeleteWebhook(ctx context.Context, id int) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if _, ok := md.webhooks[id]; !ok {
+		return http.StatusNotFound
+	}
+	delete(md.webhooks, id)
+	kept := md.deliveries[:0]
+	for _, d := range md.deliveries {
+		if d.Webhook != id {
+			kept = append(kept, d)
+		}
+	}
+	md.deliveries = kept
+
+	return http.StatusOK
+}
+
+func (md *MemoryDatabase) DispatchOutbox(ctx context.Context, limit int) (int, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	ids := make([]int, 0, len(md.webhooks))
+	for id := range md.webhooks {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	now := time.Now()
+	taken := 0
+	for _, event := range md.outbox {
+		if taken == limit {
+			break
+		}
+		if event.dispatched {
+			continue
+		}
+		event.dispatched = true
+		taken++
+		for _, id := range ids {
+			if !md.webhooks[id].Takes(event.Type, event.Forum) {
+				continue
+			}
+			md.lastDeliveryId++
+			md.deliveries = append(md.deliveries, &delivery{
+				Delivery: models.Delivery{
+					Id:      md.lastDeliveryId,
+					Webhook: id,
+					Event:   event.Id,
+					Type:    event.Type,
+					State:   models.DeliveryPending,
+					Created: strfmt.DateTime(now),
+				},
+				next: now,
+			})
+		}
+	}
+
+	return taken, nil
+}
+
+func (md *MemoryDatabase) ClaimDeliveries(ctx context.Context, now time.Time, until time.Time, limit int) ([]models.DueDelivery, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	var due []*delivery
+	for _, d := range md.deliveries {
+		if d.State == models.DeliveryPending && !d.next.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].next.Before(due[j].next)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].Id < due[j].Id
+	})
+
+	var deliveries []models.DueDelivery
+	for _, d := range due {
+		d.next = until
+		hook := md.webhooks[d.Webhook]
+		claimed := models.DueDelivery{Id: d.Id, Attempts: d.Attempts, Url: hook.Url, Secret: hook.Secret}
+		for _, event := range md.outbox {
+			if event.Id == d.Event {
+				claimed.Event = event.HookEvent
+				break
+			}
+		}
+		deliveries = append(deliveries, claimed)
+	}
+
+	return deliveries, nil
+}
+
+func (md *MemoryDatabase) RecordDelivery(ctx context.Context, id int64, result models.DeliveryResult) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	for _, d := range md.deliveries {
+		if d.Id != id {
+			continue
+		}
+		d.Attempts++
+		d.next = result.NextAttempt
+		d.LastStatus = result.Status
+		d.LastError = result.Error
+		d.DeliveredAt = nil
+		switch {
+		case result.Delivered:
+			d.State = models.DeliveryDelivered
+			now := strfmt.DateTime(time.Now())
+			d.DeliveredAt = &now
+		case result.Dead:
+			d.State = models.DeliveryDead
+		default:
+			d.State = models.DeliveryPending
+		}
+	}
+
+	return nil
+}
+
+func (md *MemoryDatabase) GetDeliveries(ctx context.Context, webhook int, state string, limit int) (models.Deliveries, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	deliveries := models.Deliveries{}
+	for i := len(md.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		d := md.deliveries[i]
+		if d.Webhook == webhook && (state == "" || d.State == state) {
+			deliveries = append(deliveries, d.view())
+		}
+	}
+
+	return deliveries, nil
+}
+
+func (md *MemoryDatabase) Redeliver(ctx context.Context, webhook int, id int64) (models.Delivery, int) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	for _, d := range md.deliveries {
+		if d.Id == id && d.Webhook == webhook {
+			d.State = models.DeliveryPending
+			d.Attempts = 0
+			d.next = time.Now()
+			return d.view(), http.StatusOK
+		}
+	}
+
+	return models.Delivery{}, http.StatusNotFound
+}
+
+func (md *MemoryDatabase) PruneDeliveries(ctx context.Context, before time.Time) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	kept := md.deliveries[:0]
+	waiting := make(map[int64]bool)
+	for _, d := range md.deliveries {
+		if d.State == models.DeliveryPending || !time.Time(d.Created).Before(before) {
+			kept = append(kept, d)
+			waiting[d.Event] = true
+		}
+	}
+	md.deliveries = kept
+
+	events := md.outbox[:0]
+	for _, event := range md.outbox {
+		if !event.dispatched || waiting[event.Id] || !time.Time(event.Created).Before(before) {
+			events = append(events, event)
+		}
+	}
+	md.outbox = events
+
+	return nil
+}
+
+// notify mirrors the notifications AddPost and AddNewThread write.
+func (md *MemoryDatabase) notify(nickname string, n models.Notification) {
+	md.lastNotificationId++
+	n.Id = md.lastNotificationId
+	md.notifications = append(md.notifications, &notification{Notification: n, nickname: nickname})
+}
+
+// notifyPost notifies the author of the parent of p and the users it
+// mentions, but neither of them twice nor the author of p.
+func (md *MemoryDatabase) notifyPost(p *post) {
+	n := models.Notification{Actor: p.Author, Forum: p.Forum, Thread: p.Thread, Post: p.Id, Created: p.Created}
+	author := md.user(p.Author)
+	var replied *models.User
+	if parent, ok := md.posts[p.Parent]; ok && p.Parent != 0 {
+		replied = md.user(parent.Author)
+		if replied != author {
+			n.Type = models.NotifyReply
+			md.notify(replied.Nickname, n)
+		}
+	}
+	for _, nickname := range models.Mentions(p.Message) {
+		u := md.user(nickname)
+		if u == nil || u == author || u == replied {
+			continue
+		}
+		n.Type = models.NotifyMention
+		md.notify(u.Nickname, n)
+	}
+}
+
+func (md *MemoryDatabase) SubscribeForum(ctx context.Context, forum string, nickname string) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	user := md.user(nickname)
+	if user == nil || md.forums[fold(forum)] == nil {
+		return errForeignKey
+	}
+	subs, ok := md.forumSubs[fold(forum)]
+	if !ok {
+		subs = make(map[string]*subscription)
+		md.forumSubs[fold(forum)] = subs
+	}
+	if _, ok := subs[fold(nickname)]; !ok {
+		subs[fold(nickname)] = &subscription{nickname: user.Nickname, created: strfmt.DateTime(time.Now())}
+	}
+
+	return nil
+}
+
+func (md *MemoryDatabase) UnsubscribeForum(ctx context.Context, forum string, nickname string) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	subs := md.forumSubs[fold(forum)]
+	if _, ok := subs[fold(nickname)]; !ok {
+		return http.StatusNotFound
+	}
+	delete(subs, fold(nickname))
+
+	return http.StatusOK
+}
+
+func (md *MemoryDatabase) GetNotifications(ctx context.Context, query models.NotificationQuery) (models.Notifications, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	notifications := models.Notifications{}
+	for i := len(md.notifications) - 1; i >= 0 && len(notifications) < query.Limit; i-- {
+		n := md.notifications[i]
+		if fold(n.nickname) != fold(query.Nickname) || query.Unread && n.Read ||
+			query.After != 0 && n.Id >= query.After {
+			continue
+		}
+		notifications = append(notifications, n.Notification)
+	}
+
+	return notifications, nil
+}
+
+func (md *MemoryDatabase) CountUnreadNotifications(ctx context.Context, nickname string) (map[string]int, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, n := range md.notifications {
+		if !n.Read && fold(n.nickname) == fold(nickname) {
+			counts[n.Type]++
+		}
+	}
+
+	return counts, nil
+}
+
+func (md *MemoryDatabase) MarkNotificationRead(ctx context.Context, nickname string, id int64) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	for _, n := range md.notifications {
+		if n.Id == id && fold(n.nickname) == fold(nickname) {
+			n.Read = true
+			return http.StatusOK
+		}
+	}
+
+	return http.StatusNotFound
+}
+
+func (md *MemoryDatabase) MarkNotificationsRead(ctx context.Context, nickname string, upTo int64) (int, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	marked := 0
+	for _, n := range md.notifications {
+		if !n.Read && fold(n.nickname) == fold(nickname) && (upTo == 0 || n.Id <= upTo) {
+			n.Read = true
+			marked++
+		}
+	}
+
+	return marked, nil
+}
+
+func (md *MemoryDatabase) SubscribeThread(ctx context.Context, thread int, nickname string) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	user := md.user(nickname)
+	if user == nil || md.threads[thread] == nil {
+		return errForeignKey
+	}
+	subs, ok := md.threadSubs[thread]
+	if !ok {
+		subs = make(map[string]*subscription)
+		md.threadSubs[thread] = subs
+	}
+	if _, ok := subs[fold(nickname)]; !ok {
+		subs[fold(nickname)] = &subscription{nickname: user.Nickname, created: strfmt.DateTime(time.Now())}
+	}
+
+	return nil
+}
+
+func (md *MemoryDatabase) UnsubscribeThread(ctx context.Context, thread int, nickname string) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	subs := md.threadSubs[thread]
+	if _, ok := subs[fold(nickname)]; !ok {
+		return http.StatusNotFound
+	}
+	delete(subs, fold(nickname))
+
+	return http.StatusOK
+}
+
+// unreadPosts counts the live posts of others in a thread after the last
+// one nickname read.
+func (md *MemoryDatabase) unreadPosts(thread int, nickname string) int {
+	lastRead := md.reads[thread][fold(nickname)]
+	unread := 0
+	for _, p := range md.threadPosts[thread] {
+		if p.Id > lastRead && !p.IsDeleted && fold(p.Author) != fold(nickname) {
+			unread++
+		}
+	}
+	return unread
+}
+
+func (md *MemoryDatabase) GetSubscriptions(ctx context.Context, nickname string) (models.Subscriptions, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	subscriptions := models.Subscriptions{Forums: []models.ForumSubscription{}, Threads: []models.ThreadSubscription{}}
+	for key, subs := range md.forumSubs {
+		sub, ok := subs[fold(nickname)]
+		if !ok {
+			continue
+		}
+		forum := md.forums[key]
+		unread := 0
+		for _, n := range md.notifications {
+			if !n.Read && n.Type == models.NotifyThread && fold(n.nickname) == fold(nickname) &&
+				fold(n.Forum) == key {
+				unread++
+			}
+		}
+		subscriptions.Forums = append(subscriptions.Forums, models.ForumSubscription{
+			Forum: forum.Slug, Title: forum.Title, Unread: unread, Created: sub.created})
+	}
+	sort.Slice(subscriptions.Forums, func(i, j int) bool {
+		return fold(subscriptions.Forums[i].Forum) < fold(subscriptions.Forums[j].Forum)
+	})
+
+	for id, subs := range md.threadSubs {
+		sub, ok := subs[fold(nickname)]
+		if !ok {
+			continue
+		}
+		thread := md.threads[id]
+		subscriptions.Threads = append(subscriptions.Threads, models.ThreadSubscription{
+			Thread:   id,
+			Slug:     thread.Slug,
+			Title:    thread.Title,
+			Forum:    thread.Forum,
+			LastRead: md.reads[id][fold(nickname)],
+			Unread:   md.unreadPosts(id, nickname),
+			Created:  sub.created,
+		})
+	}
+	sort.Slice(subscriptions.Threads, func(i, j int) bool {
+		return subscriptions.Threads[i].Thread < subscriptions.Threads[j].Thread
+	})
+
+	return subscriptions, nil
+}
+
+func (md *MemoryDatabase) GetLastRead(ctx context.Context, thread int, nickname string) (int, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	return md.reads[thread][fold(nickname)], nil
+}
+
+func (md *MemoryDatabase) MarkThreadRead(ctx context.Context, thread int, nickname string, post int) (models.ThreadRead, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if md.user(nickname) == nil || md.threads[thread] == nil {
+		return models.ThreadRead{}, errForeignKey
+	}
+	if post == 0 {
+		for _, p := range md.threadPosts[thread] {
+			if p.Id > post {
+				post = p.Id
+			}
+		}
+	}
+	reads, ok := md.reads[thread]
+	if !ok {
+		reads = make(map[string]int)
+		md.reads[thread] = reads
+	}
+	if post > reads[fold(nickname)] {
+		reads[fold(nickname)] = post
+	}
+	lastRead := reads[fold(nickname)]
+
+	for _, n := range md.notifications {
+		if n.Thread == thread && fold(n.nickname) == fold(nickname) && (n.Post == 0 || n.Post <= lastRead) {
+			n.Read = true
+		}
+	}
+
+	return models.ThreadRead{
+		Thread:   thread,
+		Nickname: nickname,
+		LastRead: lastRead,
+		Unread:   md.unreadPosts(thread, nickname),
+	}, nil
+}
+
+func (md *MemoryDatabase) blocked(nickname string, other string) bool {
+	_, ok := md.blocks[fold(nickname)][fold(other)]
+	return ok
+}
+
+// viewConversation is a conversation as participant p sees it, with the
+// unread messages of others except those of users p blocked.
+func (md *MemoryDatabase) viewConversation(c *conversation, p *models.Participant) models.Conversation {
+	v := c.Conversation
+	v.Participants = make([]models.Participant, len(c.participants))
+	for i, participant := range c.participants {
+		v.Participants[i] = *participant
+	}
+	for _, m := range c.messages {
+		if m.Id > p.LastRead && fold(m.Author) != fold(p.Nickname) && !md.blocked(p.Nickname, m.Author) {
+			v.Unread++
+		}
+	}
+	return v
+}
+
+// addMessage mirrors addMessage of the PostgreSQL store.
+func (md *MemoryDatabase) addMessage(c *conversation, message *models.PrivateMessage) error {
+	author := c.participant(message.Author)
+	if author == nil {
+		return errForeignKey
+	}
+	md.lastMessageId++
+	message.Id = md.lastMessageId
+	message.Created = strfmt.DateTime(time.Now())
+	m := *message
+	c.messages = append(c.messages, &m)
+	c.LastMessage = m.Id
+	author.LastRead = m.Id
+
+	return nil
+}
+
+func (md *MemoryDatabase) AddConversation(ctx context.Context, c *models.Conversation, message *models.PrivateMessage) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if md.user(c.Creator) == nil {
+		return errForeignKey
+	}
+	for _, p := range c.Participants {
+		if md.user(p.Nickname) == nil {
+			return errForeignKey
+		}
+	}
+
+	md.lastConversationId++
+	c.Id = md.lastConversationId
+	c.Created = strfmt.DateTime(time.Now())
+	stored := &conversation{Conversation: *c}
+	stored.Participants = nil
+	for _, p := range c.Participants {
+		stored.participants = append(stored.participants, &models.Participant{Nickname: p.Nickname, Joined: c.Created})
+	}
+	sort.Slice(stored.participants, func(i, j int) bool {
+		return fold(stored.participants[i].Nickname) < fold(stored.participants[j].Nickname)
+	})
+	message.Conversation = c.Id
+	message.Author = c.Creator
+	if err := md.addMessage(stored, message); err != nil {
+		return err
+	}
+	md.conversations[c.Id] = stored
+	*c = md.viewConversation(stored, stored.participant(c.Creator))
+
+	return nil
+}
+
+func (md *MemoryDatabase) GetConversation(ctx context.Context, id int, nickname string) (models.Conversation, int) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	c, ok := md.conversations[id]
+	if !ok {
+		return models.Conversation{}, http.StatusNotFound
+	}
+	p := c.participant(nickname)
+	if p == nil {
+		return models.Conversation{}, http.StatusNotFound
+	}
+
+	return md.viewConversation(c, p), http.StatusOK
+}
+
+func (md *MemoryDatabase) GetConversations(ctx context.Context, query models.ConversationQuery) (models.Conversations, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	conversations := models.Conversations{}
+	for _, c := range md.conversations {
+		p := c.participant(query.Nickname)
+		if p == nil || query.After != 0 && c.LastMessage >= query.After {
+			continue
+		}
+		conversations = append(conversations, md.viewConversation(c, p))
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].LastMessage > conversations[j].LastMessage
+	})
+	if len(conversations) > query.Limit {
+		conversations = conversations[:query.Limit]
+	}
+
+	return conversations, nil
+}
+
+func (md *MemoryDatabase) AddPrivateMessage(ctx context.Context, message *models.PrivateMessage) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	c, ok := md.conversations[message.Conversation]
+	if !ok {
+		return errForeignKey
+	}
+
+	return md.addMessage(c, message)
+}
+
+func (md *MemoryDatabase) message(conversation int, id int64) *models.PrivateMessage {
+	c, ok := md.conversations[conversation]
+	if !ok {
+		return nil
+	}
+	for _, m := range c.messages {
+		if m.Id == id {
+			return m
+		}
+	}
+	return nil
+}
+
+func (md *MemoryDatabase) GetPrivateMessage(ctx context.Context, conversation int, id int64) (models.PrivateMessage, int) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	m := md.message(conversation, id)
+	if m == nil {
+		return models.PrivateMessage{}, http.StatusNotFound
+	}
+
+	return *m, http.StatusOK
+}
+
+func (md *MemoryDatabase) GetPrivateMessages(ctx context.Context, query models.MessageQuery) (models.PrivateMessages, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	messages := models.PrivateMessages{}
+	c, ok := md.conversations[query.Conversation]
+	if !ok {
+		return messages, nil
+	}
+	take := func(m *models.PrivateMessage) bool {
+		if !md.blocked(query.Reader, m.Author) {
+			messages = append(messages, *m)
+		}
+		return len(messages) < query.Limit
+	}
+	if query.Desc {
+		for i := len(c.messages) - 1; i >= 0; i-- {
+			if m := c.messages[i]; (query.Since == 0 || m.Id < query.Since) && !take(m) {
+				break
+			}
+		}
+	} else {
+		for _, m := range c.messages {
+			if m.Id > query.Since && !take(m) {
+				break
+			}
+		}
+	}
+
+	return messages, nil
+}
+
+func (md *MemoryDatabase) EditPrivateMessage(ctx context.Context, conversation int, id int64, message string) (models.PrivateMessage, int) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	m := md.message(conversation, id)
+	if m == nil {
+		return models.PrivateMessage{}, http.StatusNotFound
+	}
+	editedAt := strfmt.DateTime(time.Now())
+	m.Message = message
+	m.IsEdited = true
+	m.EditedAt = &editedAt
+
+	return *m, http.StatusOK
+}
+
+func (md *MemoryDatabase) MarkConversationRead(ctx context.Context, conversation int, nickname string, upTo int64) (models.ConversationRead, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	c, ok := md.conversations[conversation]
+	if !ok {
+		return models.ConversationRead{}, errNoRows
+	}
+	p := c.participant(nickname)
+	if p == nil {
+		return models.ConversationRead{}, errNoRows
+	}
+	if upTo == 0 {
+		upTo = c.LastMessage
+	}
+	if upTo > p.LastRead {
+		p.LastRead = upTo
+	}
+
+	return models.ConversationRead{
+		Conversation: conversation,
+		Nickname:     nickname,
+		LastRead:     p.LastRead,
+		Unread:       md.viewConversation(c, p).Unread,
+	}, nil
+}
+
+func (md *MemoryDatabase) Block(ctx context.Context, nickname string, blocked string) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	user, other := md.user(nickname), md.user(blocked)
+	if user == nil || other == nil {
+		return errForeignKey
+	}
+	blocks, ok := md.blocks[fold(nickname)]
+	if !ok {
+		blocks = make(map[string]*models.Block)
+		md.blocks[fold(nickname)] = blocks
+	}
+	if _, ok := blocks[fold(blocked)]; !ok {
+		blocks[fold(blocked)] = &models.Block{
+			Nickname: user.Nickname, Blocked: other.Nickname, Created: strfmt.DateTime(time.Now())}
+	}
+
+	return nil
+}
+
+func (md *MemoryDatabase) Unblock(ctx context.Context, nickname string, blocked string) int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	blocks := md.blocks[fold(nickname)]
+	if _, ok := blocks[fold(blocked)]; !ok {
+		return http.StatusNotFound
+	}
+	delete(blocks, fold(blocked))
+
+	return http.StatusOK
+}
+
+func (md *MemoryDatabase) GetBlocks(ctx context.Context, nickname string) (models.Blocks, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	blocks := models.Blocks{}
+	for _, b := range md.blocks[fold(nickname)] {
+		blocks = append(blocks, *b)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return fold(blocks[i].Blocked) < fold(blocks[j].Blocked)
+	})
+
+	return blocks, nil
+}
+
+func (md *MemoryDatabase) Blocking(ctx context.Context, nickname string, others []string) ([]string, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	blocking := []string{}
+	for _, other := range others {
+		if md.blocked(nickname, other) || md.blocked(other, nickname) {
+			blocking = append(blocking, other)
+		}
+	}
+
+	return blocking, nil
+}
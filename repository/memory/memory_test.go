@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	smth "subd"
+	"subd/repository/repotest"
+)
+
+func TestMemoryDatabase(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) smth.Repository {
+		return NewMemoryDatabase()
+	})
+}
@@ -2,12 +2,17 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/georgysavva/scany/pgxscan"
 	"github.com/go-openapi/strfmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	event "subd"
 	"subd/models"
@@ -22,13 +27,22 @@ func NewSomeDatabase(conn *pgxpool.Pool) event.Repository {
 	return &SomeDatabase{pool: conn}
 }
 
-func (sd SomeDatabase) CheckThread(slug string) (bool, error) {
+// postMessage hides the message of a tombstone. The row keeps it, so that
+// the post can be restored.
+const postMessage = `CASE WHEN posts.deleted_at IS NULL THEN posts.message ELSE '' END AS message`
+
+// postColumns selects a models.Post.
+const postColumns = `posts.id, posts.author, posts.created, posts.forum, posts.is_edited, ` + postMessage + `,
+	posts.parent, posts.thread, posts.deleted_at IS NOT NULL AS is_deleted,
+	posts.edit_count, posts.last_edited_at`
+
+func (sd SomeDatabase) CheckThread(ctx context.Context, slug string) (bool, error) {
 	var id []uint64
-	err := pgxscan.Select(context.Background(), sd.pool, &id,
+	err := pgxscan.Select(ctx, sd.pool, &id,
 		`SELECT 1 FROM threads
 	WHERE slug = $1 LIMIT 1`, slug)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(id) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(id) == 0 {
 		return false, nil
 	}
 
@@ -38,13 +52,13 @@ func (sd SomeDatabase) CheckThread(slug string) (bool, error) {
 	return true, nil
 }
 
-func (sd SomeDatabase) CheckThreadById(id int) (bool, error) {
+func (sd SomeDatabase) CheckThreadById(ctx context.Context, id int) (bool, error) {
 	var ids []uint64
-	err := pgxscan.Select(context.Background(), sd.pool, &ids,
+	err := pgxscan.Select(ctx, sd.pool, &ids,
 		`SELECT 1 FROM threads
 	WHERE id = $1 LIMIT 1`, id)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(ids) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(ids) == 0 {
 		return false, nil
 	}
 
@@ -54,13 +68,13 @@ func (sd SomeDatabase) CheckThreadById(id int) (bool, error) {
 	return true, nil
 }
 
-func (sd SomeDatabase) CheckForum(slug string) (bool, error) {
+func (sd SomeDatabase) CheckForum(ctx context.Context, slug string) (bool, error) {
 	var id []uint64
-	err := pgxscan.Select(context.Background(), sd.pool, &id,
+	err := pgxscan.Select(ctx, sd.pool, &id,
 		`SELECT 1 FROM forums
 	WHERE slug = $1 LIMIT 1`, slug)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(id) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(id) == 0 {
 		return false, nil
 	}
 
@@ -70,13 +84,13 @@ func (sd SomeDatabase) CheckForum(slug string) (bool, error) {
 	return true, nil
 }
 
-func (sd SomeDatabase) CheckUser(user string) (bool, error) {
+func (sd SomeDatabase) CheckUser(ctx context.Context, user string) (bool, error) {
 	var id []uint64
-	err := pgxscan.Select(context.Background(), sd.pool, &id,
+	err := pgxscan.Select(ctx, sd.pool, &id,
 		`SELECT 1 FROM users
 	WHERE nickname = $1 LIMIT 1`, user)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(id) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(id) == 0 {
 		return false, nil
 	}
 
@@ -86,13 +100,13 @@ func (sd SomeDatabase) CheckUser(user string) (bool, error) {
 	return true, nil
 }
 
-func (sd SomeDatabase) CheckUserByEmail(email string) (bool, error) {
+func (sd SomeDatabase) CheckUserByEmail(ctx context.Context, email string) (bool, error) {
 	var id []uint64
-	err := pgxscan.Select(context.Background(), sd.pool, &id,
+	err := pgxscan.Select(ctx, sd.pool, &id,
 		`SELECT 1 FROM users
 	WHERE email = $1 LIMIT 1`, email)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(id) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(id) == 0 {
 		return false, nil
 	}
 
@@ -102,13 +116,13 @@ func (sd SomeDatabase) CheckUserByEmail(email string) (bool, error) {
 	return true, nil
 }
 
-func (sd SomeDatabase) CheckUserByNicknameOrEmail(nickname string, email string) (bool, error) {
+func (sd SomeDatabase) CheckUserByNicknameOrEmail(ctx context.Context, nickname string, email string) (bool, error) {
 	var id []uint64
-	err := pgxscan.Select(context.Background(), sd.pool, &id,
+	err := pgxscan.Select(ctx, sd.pool, &id,
 		`SELECT 1 FROM users
 	WHERE nickname = $1 OR email = $2 LIMIT 1`, nickname, email)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(id) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(id) == 0 {
 		return false, nil
 	}
 
@@ -118,13 +132,13 @@ func (sd SomeDatabase) CheckUserByNicknameOrEmail(nickname string, email string)
 	return true, nil
 }
 
-func (sd SomeDatabase) CheckPost(id int) (bool, error) {
+func (sd SomeDatabase) CheckPost(ctx context.Context, id int) (bool, error) {
 	var ids []uint64
-	err := pgxscan.Select(context.Background(), sd.pool, &ids,
+	err := pgxscan.Select(ctx, sd.pool, &ids,
 		`SELECT 1 FROM posts
 	WHERE id = $1 LIMIT 1`, id)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(ids) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(ids) == 0 {
 		return false, nil
 	}
 
@@ -134,13 +148,13 @@ func (sd SomeDatabase) CheckPost(id int) (bool, error) {
 	return true, nil
 }
 
-func (sd SomeDatabase) CheckVote(id int, nickname string) (bool, error) {
+func (sd SomeDatabase) CheckVote(ctx context.Context, id int, nickname string) (bool, error) {
 	var ids []uint64
-	err := pgxscan.Select(context.Background(), sd.pool, &ids,
+	err := pgxscan.Select(ctx, sd.pool, &ids,
 		`SELECT 1 FROM votes
 	WHERE thread = $1 AND nickname = $2 LIMIT 1`, id, nickname)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(ids) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(ids) == 0 {
 		return false, nil
 	}
 
@@ -150,8 +164,8 @@ func (sd SomeDatabase) CheckVote(id int, nickname string) (bool, error) {
 	return true, nil
 }
 
-func (sd SomeDatabase) AddNewForum(newForum *models.Forum) (error, bool) {
-	resp, err := sd.pool.Exec(context.Background(),
+func (sd SomeDatabase) AddNewForum(ctx context.Context, newForum *models.Forum) (error, bool) {
+	resp, err := sd.pool.Exec(ctx,
 		`INSERT INTO forums 
 		VALUES (default, $1, $2, default, default, $3)`,
 		newForum.Title, newForum.Owner, newForum.Slug)
@@ -165,8 +179,14 @@ func (sd SomeDatabase) AddNewForum(newForum *models.Forum) (error, bool) {
 	return nil, false
 }
 
-func (sd SomeDatabase) AddVote(id int, vote models.Vote) error {
-	_, err := sd.pool.Exec(context.Background(),
+func (sd SomeDatabase) AddVote(ctx context.Context, id int, vote models.Vote) error {
+	tx, err := sd.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
 		`INSERT INTO votes 
 		VALUES ($1, $2, $3)`,
 		id, vote.Voice, vote.Nickname)
@@ -174,12 +194,28 @@ func (sd SomeDatabase) AddVote(id int, vote models.Vote) error {
 		return err
 	}
 
-	return nil
+	if err = addVoteEvent(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// addVoteEvent adds the event of a vote, which carries the thread as the
+// vote triggers left it.
+func addVoteEvent(ctx context.Context, tx pgx.Tx, id int) error {
+	var thread models.ThreadSQL
+	err := pgxscan.Get(ctx, tx, &thread, `SELECT * FROM threads WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return addEvent(ctx, tx, models.EventThreadVoted, thread.Forum, id, models.ConvertThread(thread))
 }
 
-func (sd SomeDatabase) GetForumCounts(slug string) (uint64, uint64, error) {
+func (sd SomeDatabase) GetForumCounts(ctx context.Context, slug string) (uint64, uint64, error) {
 	var posts, threads uint64
-	err := sd.pool.QueryRow(context.Background(),
+	err := sd.pool.QueryRow(ctx,
 			`SELECT posts, threads FROM forums WHERE slug = $1`, slug).Scan(&posts, &threads)
 
 	if err != nil {
@@ -189,12 +225,12 @@ func (sd SomeDatabase) GetForumCounts(slug string) (uint64, uint64, error) {
 	return posts, threads, nil
 }
 
-func (sd SomeDatabase) GetForum(slug string) (models.Forum, int) {
+func (sd SomeDatabase) GetForum(ctx context.Context, slug string) (models.Forum, int) {
 	var forum []models.Forum
-	err := pgxscan.Select(context.Background(), sd.pool, &forum,
+	err := pgxscan.Select(ctx, sd.pool, &forum,
 		`SELECT title, owner, posts, threads, slug FROM forums WHERE slug = $1`, slug)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(forum) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(forum) == 0 {
 		return models.Forum{}, http.StatusNotFound
 	}
 
@@ -205,12 +241,12 @@ func (sd SomeDatabase) GetForum(slug string) (models.Forum, int) {
 	return forum[0], http.StatusOK
 }
 
-func (sd SomeDatabase) GetPost(id int) (models.Post, int) {
+func (sd SomeDatabase) GetPost(ctx context.Context, id int) (models.Post, int) {
 	var post []models.Post
-	err := pgxscan.Select(context.Background(), sd.pool, &post,
-		`SELECT id, author, created, forum, is_edited, message, parent, thread FROM posts WHERE id = $1`, id)
+	err := pgxscan.Select(ctx, sd.pool, &post,
+		`SELECT ` + postColumns + ` FROM posts WHERE id = $1`, id)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(post) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(post) == 0 {
 		return models.Post{}, http.StatusNotFound
 	}
 
@@ -221,12 +257,15 @@ func (sd SomeDatabase) GetPost(id int) (models.Post, int) {
 	return post[0], http.StatusOK
 }
 
-func (sd SomeDatabase) GetPostNull(id int) (models.PostNullMessage, int) {
+func (sd SomeDatabase) GetPostNull(ctx context.Context, id int) (models.PostNullMessage, int) {
 	var post []models.PostNullMessage
-	err := pgxscan.Select(context.Background(), sd.pool, &post,
-		`SELECT id, author, created, forum, message, parent, thread FROM posts WHERE id = $1`, id)
+	err := pgxscan.Select(ctx, sd.pool, &post,
+		`SELECT posts.id, posts.author, posts.created, posts.forum, ` + postMessage + `,
+			posts.parent, posts.thread, posts.deleted_at IS NOT NULL AS is_deleted,
+			posts.edit_count, posts.last_edited_at
+			FROM posts WHERE id = $1`, id)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(post) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(post) == 0 {
 		return models.PostNullMessage{}, http.StatusNotFound
 	}
 
@@ -237,12 +276,12 @@ func (sd SomeDatabase) GetPostNull(id int) (models.PostNullMessage, int) {
 	return post[0], http.StatusOK
 }
 
-func (sd SomeDatabase) GetUser(name string) (models.User, int) {
+func (sd SomeDatabase) GetUser(ctx context.Context, name string) (models.User, int) {
 	var user []models.User
-	err := pgxscan.Select(context.Background(), sd.pool, &user,
+	err := pgxscan.Select(ctx, sd.pool, &user,
 		`SELECT nickname, fullname, about, email FROM users WHERE nickname = $1`, name)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(user) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(user) == 0 {
 		return models.User{}, http.StatusNotFound
 	}
 
@@ -253,12 +292,12 @@ func (sd SomeDatabase) GetUser(name string) (models.User, int) {
 	return user[0], http.StatusOK
 }
 
-func (sd SomeDatabase) GetThreadById(id int) (models.Thread, int) {
+func (sd SomeDatabase) GetThreadById(ctx context.Context, id int) (models.Thread, int) {
 	var thread []models.ThreadSQL
-	err := pgxscan.Select(context.Background(), sd.pool, &thread,
+	err := pgxscan.Select(ctx, sd.pool, &thread,
 		`SELECT * FROM threads WHERE id = $1`, id)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(thread) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(thread) == 0 {
 		return models.Thread{}, http.StatusNotFound
 	}
 
@@ -269,12 +308,12 @@ func (sd SomeDatabase) GetThreadById(id int) (models.Thread, int) {
 	return models.ConvertThread(thread[0]), http.StatusOK
 }
 
-func (sd SomeDatabase) GetThreadStatus(slug string) (models.Thread, int) {
+func (sd SomeDatabase) GetThreadStatus(ctx context.Context, slug string) (models.Thread, int) {
 	var thread []models.ThreadSQL
-	err := pgxscan.Select(context.Background(), sd.pool, &thread,
+	err := pgxscan.Select(ctx, sd.pool, &thread,
 		`SELECT * FROM threads WHERE slug = $1`, slug)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(thread) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(thread) == 0 {
 		return models.Thread{}, http.StatusNotFound
 	}
 
@@ -285,12 +324,12 @@ func (sd SomeDatabase) GetThreadStatus(slug string) (models.Thread, int) {
 	return models.ConvertThread(thread[0]), http.StatusOK
 }
 
-func (sd SomeDatabase) GetThread(slug string) (models.Thread, error) {
+func (sd SomeDatabase) GetThread(ctx context.Context, slug string) (models.Thread, error) {
 	var ev []models.ThreadSQL
-	err := pgxscan.Select(context.Background(), sd.pool, &ev,
+	err := pgxscan.Select(ctx, sd.pool, &ev,
 		`SELECT * FROM threads WHERE slug = $1`, slug)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(ev) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(ev) == 0 {
 		return models.Thread{}, nil
 	}
 
@@ -301,9 +340,9 @@ func (sd SomeDatabase) GetThread(slug string) (models.Thread, error) {
 	return models.ConvertThread(ev[0]), nil
 }
 
-func (sd SomeDatabase) GetValueVote(id int, nickname string) (int, error) {
+func (sd SomeDatabase) GetValueVote(ctx context.Context, id int, nickname string) (int, error) {
 	var num []int
-	err := pgxscan.Select(context.Background(), sd.pool, &num,
+	err := pgxscan.Select(ctx, sd.pool, &num,
 		`SELECT voice FROM votes WHERE thread = $1 AND nickname = $2`, id, nickname)
 
 	if err != nil {
@@ -313,17 +352,38 @@ func (sd SomeDatabase) GetValueVote(id int, nickname string) (int, error) {
 	return num[0], nil
 }
 
-func (sd SomeDatabase) AddNewThread(newThread models.Thread) (uint64, error) {
+// countThread counts a new thread in its forum and makes its author a
+// user of the forum, in the statement adding it.
+const countThread = `, counted AS (
+		UPDATE forums SET threads = threads + 1 WHERE slug = (SELECT forum FROM thread)
+	), member AS (
+		INSERT INTO forum_users (forum, nickname) SELECT forum, author FROM thread
+		ON CONFLICT DO NOTHING
+	)`
+
+// notifySubscribers follows the insert of a thread, named thread, and
+// notifies the subscribers of its forum in the same statement.
+const notifySubscribers = `, notified AS (
+		INSERT INTO notifications (nickname, type, actor, forum, thread)
+		SELECT s.nickname, 'thread', thread.author, thread.forum, thread.id
+		FROM thread JOIN forum_subscriptions s ON s.forum = thread.forum
+		WHERE s.nickname <> thread.author
+	)
+	SELECT id FROM thread`
+
+func (sd SomeDatabase) AddNewThread(ctx context.Context, newThread models.Thread) (uint64, error) {
 	var id uint64
 	var err error
 	if newThread.Slug == ""{
-		err = sd.pool.QueryRow(context.Background(),
-			`INSERT INTO threads VALUES (default, $1, $2, $3, $4, null, $5, default) RETURNING id`,
+		err = sd.pool.QueryRow(ctx,
+			`WITH thread AS (INSERT INTO threads VALUES (default, $1, $2, $3, $4, null, $5, default)
+				RETURNING id, author, forum)`+countThread+notifySubscribers,
 			newThread.Author, newThread.Created, newThread.Forum, newThread.Message,
 			newThread.Title).Scan(&id)
 	} else {
-		err = sd.pool.QueryRow(context.Background(),
-			`INSERT INTO threads VALUES (default, $1, $2, $3, $4, $5, $6, default) RETURNING id`,
+		err = sd.pool.QueryRow(ctx,
+			`WITH thread AS (INSERT INTO threads VALUES (default, $1, $2, $3, $4, $5, $6, default)
+				RETURNING id, author, forum)`+countThread+notifySubscribers,
 			newThread.Author, newThread.Created, newThread.Forum, newThread.Message,
 			newThread.Slug, newThread.Title).Scan(&id)
 	}
@@ -334,67 +394,174 @@ func (sd SomeDatabase) AddNewThread(newThread models.Thread) (uint64, error) {
 	return id, nil
 }
 
-func (sd SomeDatabase) AddPost(newPosts []*models.Post, thread models.Thread, now time.Time) int {
+func (sd SomeDatabase) AddPost(ctx context.Context, newPosts []*models.Post, thread models.Thread, now time.Time) int {
+	tx, err := sd.pool.Begin(ctx)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	defer tx.Rollback(ctx)
+
+	var ids []int64
+	err = pgxscan.Select(ctx, tx, &ids,
+		`SELECT nextval(pg_get_serial_sequence('posts', 'id')) FROM generate_series(1, $1)`, len(newPosts))
+	if err != nil || len(ids) != len(newPosts) {
+		return http.StatusInternalServerError
+	}
+
+	rows := make([][]interface{}, len(newPosts))
+	authors := make([]string, 0, len(newPosts))
+	seen := make(map[string]bool, len(newPosts))
 	for i := range newPosts {
+		newPosts[i].Id = int(ids[i])
 		newPosts[i].Thread = int(thread.Id)
 		newPosts[i].Forum = thread.Forum
 		newPosts[i].Created = strfmt.DateTime(now)
-		err := sd.pool.QueryRow(context.Background(),
-			`INSERT INTO posts VALUES (default, $1, $2, $3, default, $4, $5, $6) RETURNING id`,
-			newPosts[i].Author, newPosts[i].Created, newPosts[i].Forum,
-			newPosts[i].Message, newPosts[i].Parent, newPosts[i].Thread).Scan(&newPosts[i].Id)
-		if err != nil {
-			if strings.Contains(err.Error(), "parent_thread_id is not equal to this one") {
+		rows[i] = []interface{}{ids[i], newPosts[i].Author, now, newPosts[i].Forum,
+			newPosts[i].Message, int32(newPosts[i].Parent), int32(newPosts[i].Thread)}
+
+		key := strings.ToLower(newPosts[i].Author)
+		if !seen[key] {
+			seen[key] = true
+			authors = append(authors, key)
+		}
+	}
+	// Sorted so that concurrent batches lock forum_users rows in the same order.
+	sort.Strings(authors)
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"posts"},
+		[]string{"id", "author", "created", "forum", "message", "parent", "thread"},
+		pgx.CopyFromRows(rows))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case pgerrcode.RaiseException:
 				return http.StatusConflict
-			} else {
+			case pgerrcode.ForeignKeyViolation:
 				return http.StatusNotFound
 			}
 		}
+		return http.StatusInternalServerError
+	}
 
-		_, err = sd.pool.Exec(context.Background(),
-			`UPDATE forums SET posts = posts + 1 WHERE slug = $1`, newPosts[i].Forum)
-		_, err = sd.pool.Exec(context.Background(),
-			`INSERT INTO forum_users 
-		VALUES ($1, $2)`,
-			newPosts[i].Forum, newPosts[i].Author)
+	_, err = tx.Exec(ctx,
+		`UPDATE forums SET posts = posts + $1 WHERE slug = $2`, len(newPosts), thread.Forum)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE threads SET post_count = post_count + $1, last_post_at = $2 WHERE id = $3`,
+		len(newPosts), now, thread.Id)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO forum_users (forum, nickname)
+		SELECT $1, users.nickname FROM users WHERE users.nickname = ANY($2::text[]::citext[])
+		ORDER BY users.nickname
+		ON CONFLICT DO NOTHING`,
+		thread.Forum, authors)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	if status := notifyPosts(ctx, tx, newPosts, ids); status != http.StatusOK {
+		return status
+	}
+
+	err = addEvent(ctx, tx, models.EventPostsCreated, thread.Forum, int(thread.Id), newPosts)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return http.StatusInternalServerError
 	}
 
 	return http.StatusCreated
 }
 
-func (sd SomeDatabase) GetForumUsers(slug string, limit int, since string, desc bool) (models.Users, error) {
+// notifyPosts notifies the authors of the parents of new posts and the
+// users they mention. Replying to someone is not also a mention of them,
+// and nobody is notified of their own posts.
+func notifyPosts(ctx context.Context, tx pgx.Tx, newPosts []*models.Post, ids []int64) int {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO notifications (nickname, type, actor, forum, thread, post, created)
+		SELECT parent.author, 'reply', p.author, p.forum, p.thread, p.id, p.created
+		FROM posts p JOIN posts parent ON parent.id = p.parent
+		WHERE p.id = ANY($1::bigint[]) AND parent.author <> p.author
+		ORDER BY p.id`, ids)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	var mentionPosts []int64
+	var mentioned []string
+	for i := range newPosts {
+		for _, nickname := range models.Mentions(newPosts[i].Message) {
+			mentionPosts = append(mentionPosts, ids[i])
+			mentioned = append(mentioned, nickname)
+		}
+	}
+	if len(mentioned) == 0 {
+		return http.StatusOK
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO notifications (nickname, type, actor, forum, thread, post, created)
+		SELECT u.nickname, 'mention', p.author, p.forum, p.thread, p.id, p.created
+		FROM unnest($1::bigint[], $2::text[]) AS m (post, nickname)
+			JOIN posts p ON p.id = m.post
+			JOIN users u ON u.nickname = m.nickname::citext
+		WHERE u.nickname <> p.author
+			AND NOT EXISTS (SELECT 1 FROM posts parent WHERE parent.id = p.parent AND parent.author = u.nickname)
+		ORDER BY p.id`, mentionPosts, mentioned)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	return http.StatusOK
+}
+
+func (sd SomeDatabase) GetForumUsers(ctx context.Context, slug string, limit int, since string, desc bool, excludeBanned bool) (models.Users, error) {
 	var users models.Users
 	var err error
+	filter := ""
+	if excludeBanned {
+		filter = `AND NOT EXISTS (SELECT 1 FROM bans WHERE bans.nickname = forum_users.nickname
+			AND (bans.forum IS NULL OR bans.forum = $1) AND ` + banActive + `)`
+	}
 	if since != "" {
 		if desc == true {
-			err = pgxscan.Select(context.Background(), sd.pool, &users,
+			err = pgxscan.Select(ctx, sd.pool, &users,
 				`SELECT users.nickname, users.fullname, users.email, users.about FROM forum_users JOIN users
 			ON forum_users.nickname = users.nickname
-			WHERE forum_users.forum = $1 AND users.nickname < $2 
+			WHERE forum_users.forum = $1 AND users.nickname < $2 ` + filter + `
 			ORDER BY users.nickname DESC LIMIT $3`, slug, since, limit)
 		} else {
-			err = pgxscan.Select(context.Background(), sd.pool, &users,
+			err = pgxscan.Select(ctx, sd.pool, &users,
 				`SELECT users.nickname, users.fullname, users.email, users.about FROM forum_users JOIN users
 			ON forum_users.nickname = users.nickname
-			WHERE forum_users.forum = $1 AND users.nickname > $2 
+			WHERE forum_users.forum = $1 AND users.nickname > $2 ` + filter + `
 			ORDER BY users.nickname LIMIT $3`, slug, since, limit)
 		}
 	} else {
 		if desc == true {
-			err = pgxscan.Select(context.Background(), sd.pool, &users,
+			err = pgxscan.Select(ctx, sd.pool, &users,
 				`SELECT users.nickname, users.fullname, users.email, users.about FROM forum_users JOIN users
 			ON forum_users.nickname = users.nickname
-			WHERE forum_users.forum = $1 
+			WHERE forum_users.forum = $1 ` + filter + `
 			ORDER BY users.nickname DESC LIMIT $2`, slug, limit)
 		} else {
-			err = pgxscan.Select(context.Background(), sd.pool, &users,
+			err = pgxscan.Select(ctx, sd.pool, &users,
 				`SELECT users.nickname, users.fullname, users.email, users.about FROM forum_users JOIN users
 			ON forum_users.nickname = users.nickname
-			WHERE forum_users.forum = $1 
+			WHERE forum_users.forum = $1 ` + filter + `
 			ORDER BY users.nickname LIMIT $2`, slug, limit)
 		}
 	}
-	if errors.As(err, &pgx.ErrNoRows) || len(users) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(users) == 0 {
 		return models.Users{}, nil
 	}
 
@@ -405,14 +572,31 @@ func (sd SomeDatabase) GetForumUsers(slug string, limit int, since string, desc
 	return users, nil
 }
 
-func (sd SomeDatabase) GetPostsFlat(id int ,limit int, since int) (models.Posts, error) {
+// keyCreated is the creation time of the post at a position of the flat
+// sort.
+func keyCreated(key *models.PostKey) time.Time {
+	if key.Created == nil {
+		return time.Time{}
+	}
+	return *key.Created
+}
+
+func (sd SomeDatabase) GetPostsFlat(ctx context.Context, id int ,limit int, after *models.PostKey) (models.Posts, error) {
 	var posts models.Posts
-		err := pgxscan.Select(context.Background(), sd.pool, &posts,
-			`SELECT id, author, created, forum, is_edited, message, parent, thread 
-			FROM posts WHERE thread = $1 AND id > $2 
-			ORDER BY created, id LIMIT $3`, id, since, limit)
+	var err error
+	if after != nil {
+		err = pgxscan.Select(ctx, sd.pool, &posts,
+			`SELECT ` + postColumns + ` 
+			FROM posts WHERE thread = $1 AND (created, id) > ($2, $3)
+			ORDER BY created, id LIMIT $4`, id, keyCreated(after), after.Id, limit)
+	} else {
+		err = pgxscan.Select(ctx, sd.pool, &posts,
+			`SELECT ` + postColumns + ` 
+			FROM posts WHERE thread = $1
+			ORDER BY created, id LIMIT $2`, id, limit)
+	}
 
-	if errors.As(err, &pgx.ErrNoRows) || len(posts) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(posts) == 0 {
 		return models.Posts{}, nil
 	}
 
@@ -423,22 +607,22 @@ func (sd SomeDatabase) GetPostsFlat(id int ,limit int, since int) (models.Posts,
 	return posts, nil
 }
 
-func (sd SomeDatabase) GetPostsFlatDesc(id int ,limit int, since int) (models.Posts, error) {
+func (sd SomeDatabase) GetPostsFlatDesc(ctx context.Context, id int ,limit int, after *models.PostKey) (models.Posts, error) {
 	var posts models.Posts
 	var err error
-	if since != 0 {
-		err = pgxscan.Select(context.Background(), sd.pool, &posts,
-			`SELECT id, author, created, forum, is_edited, message, parent, thread
-			FROM posts WHERE thread = $1 AND id < $2 
-			ORDER BY created DESC, id DESC LIMIT $3`, id, since, limit)
+	if after != nil {
+		err = pgxscan.Select(ctx, sd.pool, &posts,
+			`SELECT ` + postColumns + `
+			FROM posts WHERE thread = $1 AND (created, id) < ($2, $3)
+			ORDER BY created DESC, id DESC LIMIT $4`, id, keyCreated(after), after.Id, limit)
 	} else {
-		err = pgxscan.Select(context.Background(), sd.pool, &posts,
-			`SELECT id, author, created, forum, is_edited, message, parent, thread
+		err = pgxscan.Select(ctx, sd.pool, &posts,
+			`SELECT ` + postColumns + `
 			FROM posts WHERE thread = $1 
 			ORDER BY created DESC, id DESC LIMIT $2`, id, limit)
 	}
 
-	if errors.As(err, &pgx.ErrNoRows) || len(posts) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(posts) == 0 {
 		return models.Posts{}, nil
 	}
 
@@ -449,17 +633,16 @@ func (sd SomeDatabase) GetPostsFlatDesc(id int ,limit int, since int) (models.Po
 	return posts, nil
 }
 
-func (sd SomeDatabase) GetPostsParentTree(id int ,limit int) (models.Posts, error) {
+func (sd SomeDatabase) GetPostsParentTree(ctx context.Context, id int ,limit int) (models.Posts, error) {
 	var posts models.Posts
-	err := pgxscan.Select(context.Background(), sd.pool, &posts,
-		`SELECT posts.id, posts.author, posts.created, posts.forum,
-			posts.is_edited, posts.message, posts.parent, posts.thread 
+	err := pgxscan.Select(ctx, sd.pool, &posts,
+		`SELECT ` + postColumns + ` 
 			FROM (SELECT * FROM posts a WHERE a.parent = 0 AND a.thread = $1
 			ORDER BY a.path LIMIT $2) AS b
 			JOIN posts ON b.path[1] = posts.path[1]
 			ORDER BY posts.path[1], posts.path`, id, limit)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(posts) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(posts) == 0 {
 		return models.Posts{}, nil
 	}
 
@@ -470,17 +653,16 @@ func (sd SomeDatabase) GetPostsParentTree(id int ,limit int) (models.Posts, erro
 	return posts, nil
 }
 
-func (sd SomeDatabase) GetPostsParentTreeDesc(id int ,limit int) (models.Posts, error) {
+func (sd SomeDatabase) GetPostsParentTreeDesc(ctx context.Context, id int ,limit int) (models.Posts, error) {
 	var posts models.Posts
-	err := pgxscan.Select(context.Background(), sd.pool, &posts,
-		`SELECT posts.id, posts.author, posts.created, posts.forum,
-			posts.is_edited, posts.message, posts.parent, posts.thread 
+	err := pgxscan.Select(ctx, sd.pool, &posts,
+		`SELECT ` + postColumns + ` 
 			FROM (SELECT * FROM posts a WHERE a.parent = 0 AND a.thread = $1
 			ORDER BY a.path DESC LIMIT $2) AS b
 			JOIN posts ON b.path[1] = posts.path[1]
 			ORDER BY posts.path[1] DESC, posts.path`, id, limit)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(posts) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(posts) == 0 {
 		return models.Posts{}, nil
 	}
 
@@ -491,18 +673,17 @@ func (sd SomeDatabase) GetPostsParentTreeDesc(id int ,limit int) (models.Posts,
 	return posts, nil
 }
 
-func (sd SomeDatabase) GetPostsParentTreeSince(id int ,limit int, since int) (models.Posts, error) {
+func (sd SomeDatabase) GetPostsParentTreeSince(ctx context.Context, id int ,limit int, since int) (models.Posts, error) {
 	var posts models.Posts
-	err := pgxscan.Select(context.Background(), sd.pool, &posts,
-		`SELECT posts.id, posts.author, posts.created, posts.forum,
-			posts.is_edited, posts.message, posts.parent, posts.thread 
+	err := pgxscan.Select(ctx, sd.pool, &posts,
+		`SELECT ` + postColumns + ` 
 			FROM (SELECT * FROM posts a WHERE a.parent = 0 AND a.thread = $1
 			AND a.path[1] > (SELECT path[1] FROM posts WHERE id = $2)
 			ORDER BY a.path LIMIT $3) AS b
 			JOIN posts ON b.path[1] = posts.path[1]
 			ORDER BY posts.path[1], posts.path`, id, since, limit)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(posts) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(posts) == 0 {
 		return models.Posts{}, nil
 	}
 
@@ -513,18 +694,17 @@ func (sd SomeDatabase) GetPostsParentTreeSince(id int ,limit int, since int) (mo
 	return posts, nil
 }
 
-func (sd SomeDatabase) GetPostsParentTreeSinceDesc(id int ,limit int, since int) (models.Posts, error) {
+func (sd SomeDatabase) GetPostsParentTreeSinceDesc(ctx context.Context, id int ,limit int, since int) (models.Posts, error) {
 	var posts models.Posts
-	err := pgxscan.Select(context.Background(), sd.pool, &posts,
-		`SELECT posts.id, posts.author, posts.created, posts.forum,
-			posts.is_edited, posts.message, posts.parent, posts.thread 
+	err := pgxscan.Select(ctx, sd.pool, &posts,
+		`SELECT ` + postColumns + ` 
 			FROM (SELECT * FROM posts a WHERE a.parent = 0 AND a.thread = $1
 			AND a.path[1] < (SELECT path[1] FROM posts WHERE id = $2)
 			ORDER BY a.path DESC LIMIT $3) AS b
 			JOIN posts ON b.path[1] = posts.path[1]
 			ORDER BY posts.path[1] DESC, posts.path`, id, since, limit)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(posts) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(posts) == 0 {
 		return models.Posts{}, nil
 	}
 
@@ -535,14 +715,14 @@ func (sd SomeDatabase) GetPostsParentTreeSinceDesc(id int ,limit int, since int)
 	return posts, nil
 }
 
-func (sd SomeDatabase) GetPostsTree(id int ,limit int) (models.Posts, error) {
+func (sd SomeDatabase) GetPostsTree(ctx context.Context, id int ,limit int) (models.Posts, error) {
 	var posts models.Posts
-	err := pgxscan.Select(context.Background(), sd.pool, &posts,
-		`SELECT id, author, created, forum, is_edited, message, parent, thread
+	err := pgxscan.Select(ctx, sd.pool, &posts,
+		`SELECT ` + postColumns + `
 			FROM posts WHERE thread = $1
 			ORDER BY path LIMIT $2`, id, limit)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(posts) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(posts) == 0 {
 		return models.Posts{}, nil
 	}
 
@@ -553,14 +733,14 @@ func (sd SomeDatabase) GetPostsTree(id int ,limit int) (models.Posts, error) {
 	return posts, nil
 }
 
-func (sd SomeDatabase) GetPostsTreeDesc(id int ,limit int) (models.Posts, error) {
+func (sd SomeDatabase) GetPostsTreeDesc(ctx context.Context, id int ,limit int) (models.Posts, error) {
 	var posts models.Posts
-	err := pgxscan.Select(context.Background(), sd.pool, &posts,
-		`SELECT id, author, created, forum, is_edited, message, parent, thread
+	err := pgxscan.Select(ctx, sd.pool, &posts,
+		`SELECT ` + postColumns + `
 			FROM posts WHERE thread = $1
 			ORDER BY path DESC LIMIT $2`, id, limit)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(posts) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(posts) == 0 {
 		return models.Posts{}, nil
 	}
 
@@ -571,14 +751,14 @@ func (sd SomeDatabase) GetPostsTreeDesc(id int ,limit int) (models.Posts, error)
 	return posts, nil
 }
 
-func (sd SomeDatabase) GetPostsTreeSince(id int ,limit int, since int) (models.Posts, error) {
+func (sd SomeDatabase) GetPostsTreeSince(ctx context.Context, id int ,limit int, since int) (models.Posts, error) {
 	var posts models.Posts
-	err := pgxscan.Select(context.Background(), sd.pool, &posts,
-		`SELECT id, author, created, forum, is_edited, message, parent, thread
+	err := pgxscan.Select(ctx, sd.pool, &posts,
+		`SELECT ` + postColumns + `
 			FROM posts WHERE thread = $1 AND path > (SELECT path FROM posts WHERE id = $2)
 			ORDER BY path LIMIT $3`, id, since, limit)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(posts) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(posts) == 0 {
 		return models.Posts{}, nil
 	}
 
@@ -589,14 +769,14 @@ func (sd SomeDatabase) GetPostsTreeSince(id int ,limit int, since int) (models.P
 	return posts, nil
 }
 
-func (sd SomeDatabase) GetPostsTreeSinceDesc(id int ,limit int, since int) (models.Posts, error) {
+func (sd SomeDatabase) GetPostsTreeSinceDesc(ctx context.Context, id int ,limit int, since int) (models.Posts, error) {
 	var posts models.Posts
-	err := pgxscan.Select(context.Background(), sd.pool, &posts,
-		`SELECT id, author, created, forum, is_edited, message, parent, thread
+	err := pgxscan.Select(ctx, sd.pool, &posts,
+		`SELECT ` + postColumns + `
 			FROM posts WHERE thread = $1 AND path < (SELECT path FROM posts WHERE id = $2)
 			ORDER BY path DESC LIMIT $3`, id, since, limit)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(posts) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(posts) == 0 {
 		return models.Posts{}, nil
 	}
 
@@ -607,8 +787,8 @@ func (sd SomeDatabase) GetPostsTreeSinceDesc(id int ,limit int, since int) (mode
 	return posts, nil
 }
 
-func (sd SomeDatabase) AddForumUsers(slug string, author string) error {
-	_, err := sd.pool.Exec(context.Background(),
+func (sd SomeDatabase) AddForumUsers(ctx context.Context, slug string, author string) error {
+	_, err := sd.pool.Exec(ctx,
 		`INSERT INTO forum_users 
 		VALUES ($1, $2)`,
 		slug, author)
@@ -619,31 +799,68 @@ func (sd SomeDatabase) AddForumUsers(slug string, author string) error {
 	return nil
 }
 
-func (sd SomeDatabase) GetForumThreads(slug string, limit int, since string, desc bool) (models.Threads, error) {
-	var threads []models.ThreadSQL
-	var err error
-	if since == "" {
-		if desc == true {
-			err = pgxscan.Select(context.Background(), sd.pool, &threads,
-				`SELECT * FROM threads WHERE forum = $1
-				ORDER BY created DESC LIMIT $2`, slug, limit)
-		} else {
-			err = pgxscan.Select(context.Background(), sd.pool, &threads,
-				`SELECT * FROM threads WHERE forum = $1
-				ORDER BY created LIMIT $2`, slug, limit)
-		}
-	} else {
-		if desc == true {
-			err = pgxscan.Select(context.Background(), sd.pool, &threads,
-				`SELECT * FROM threads WHERE forum = $1 AND created <= $2
-				ORDER BY created DESC LIMIT $3`, slug, since, limit)
-		} else {
-			err = pgxscan.Select(context.Background(), sd.pool, &threads,
-				`SELECT * FROM threads WHERE forum = $1 AND created >= $2
-				ORDER BY created LIMIT $3`, slug, since, limit)
+// threadOrder holds the column each thread order sorts by.
+var threadOrder = map[string]string{
+	models.ThreadsByCreated:  `created`,
+	models.ThreadsByVotes:    `votes`,
+	models.ThreadsByPosts:    `post_count`,
+	models.ThreadsByActivity: `COALESCE(last_post_at, created)`,
+}
+
+// GetForumThreads orders threads by the column of the query's order with
+// the id as a tiebreaker, so that a keyset after a thread neither repeats
+// nor skips threads that tie.
+func (sd SomeDatabase) GetForumThreads(ctx context.Context, query models.ThreadQuery) (models.Threads, error) {
+	args := []interface{}{query.Forum}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	column, ok := threadOrder[query.Sort]
+	if !ok {
+		column = threadOrder[models.ThreadsByCreated]
+	}
+	order, after, since := column+`, id`, `>`, `>=`
+	if query.Desc {
+		order, after, since = column+` DESC, id DESC`, `<`, `<=`
+	}
+	where := `forum = $1`
+	if query.Author != "" {
+		where += ` AND author = ` + arg(query.Author)
+	}
+	if query.Title != "" {
+		where += ` AND title::text ILIKE ` + arg("%"+likeEscape(query.Title)+"%")
+	}
+	if query.From != nil {
+		where += ` AND created >= ` + arg(*query.From)
+	}
+	if query.To != nil {
+		where += ` AND created <= ` + arg(*query.To)
+	}
+	if key := query.After; key != nil {
+		var value interface{}
+		switch query.Sort {
+		case models.ThreadsByVotes:
+			value = key.Votes
+		case models.ThreadsByPosts:
+			value = key.Posts
+		case models.ThreadsByActivity:
+			value = key.Activity
+		default:
+			value = key.Created
 		}
+		p := arg(key.Pinned)
+		where += ` AND (pinned < ` + p + ` OR pinned = ` + p + ` AND (` + column + `, id) ` + after +
+			` (` + arg(value) + `, ` + arg(key.Id) + `))`
+	} else if query.Since != "" && column == threadOrder[models.ThreadsByCreated] {
+		where += ` AND created ` + since + ` ` + arg(query.Since)
 	}
-	if errors.As(err, &pgx.ErrNoRows) || len(threads) == 0 {
+
+	var threads []models.ThreadSQL
+	err := pgxscan.Select(ctx, sd.pool, &threads,
+		`SELECT * FROM threads WHERE `+where+` ORDER BY pinned DESC, `+order+` LIMIT `+arg(query.Limit), args...)
+	if errors.Is(err, pgx.ErrNoRows) || len(threads) == 0 {
 		return models.Threads{}, nil
 	}
 
@@ -658,61 +875,292 @@ func (sd SomeDatabase) GetForumThreads(slug string, limit int, since string, des
 	return threads2, nil
 }
 
-func (sd SomeDatabase) EditMessage(id int, message string) error {
-	_, err := sd.pool.Exec(context.Background(),
-		`UPDATE posts SET is_edited = true, message = $1 WHERE id = $2`, message, id)
+// EditMessage replaces the message of a post and keeps the replaced one as
+// a revision. An empty editor is stored as NULL.
+func (sd SomeDatabase) EditMessage(ctx context.Context, id int, message string, editor string) error {
+	tx, err := sd.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
+	var old string
+	var edits int
+	err = tx.QueryRow(ctx,
+		`SELECT message, edit_count FROM posts WHERE id = $1 FOR UPDATE`, id).Scan(&old, &edits)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	_, err = tx.Exec(ctx,
+		`INSERT INTO post_revisions (post, revision, message, editor) VALUES ($1, $2, $3, NULLIF($4, ''))`,
+		id, edits+1, old, editor)
+	if err != nil {
+		return err
+	}
+
+	var post models.Post
+	err = pgxscan.Get(ctx, tx, &post,
+		`UPDATE posts SET is_edited = true, message = $1, edit_count = edit_count + 1, last_edited_at = now()
+			WHERE id = $2 RETURNING `+postColumns, message, id)
+	if err != nil {
+		return err
+	}
+
+	err = addEvent(ctx, tx, models.EventPostEdited, post.Forum, post.Thread, post)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
-func (sd SomeDatabase) UpdateThread(slugOrId string, thread models.Thread) (models.Thread, error) {
-	err := sd.pool.QueryRow(context.Background(),
-		`UPDATE threads SET message = $1, title = $2 WHERE slug = $3
-			RETURNING threads.id, threads.author, threads.created, threads.forum,
-			threads.message, threads.slug, threads.title, threads.votes`, thread.Message,
-			thread.Title, slugOrId).Scan(&thread.Id, &thread.Author, &thread.Created,
-				&thread.Forum, &thread.Message, &thread.Slug, &thread.Title, &thread.Votes)
+func (sd SomeDatabase) GetPostRevisions(ctx context.Context, id int) (models.Revisions, error) {
+	revisions := models.Revisions{}
+	err := pgxscan.Select(ctx, sd.pool, &revisions,
+		`SELECT revision, message, COALESCE(editor, '') AS editor, created
+			FROM post_revisions WHERE post = $1 ORDER BY revision`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// DeletePost turns a post into a tombstone; deleting a tombstone again
+// changes nothing.
+func (sd SomeDatabase) DeletePost(ctx context.Context, id int) int {
+	return sd.setPostDeleted(ctx, id, true)
+}
+
+func (sd SomeDatabase) RestorePost(ctx context.Context, id int) int {
+	return sd.setPostDeleted(ctx, id, false)
+}
 
+// setPostDeleted flips the tombstone of a post and keeps the post counter
+// of its forum in step, in one transaction.
+func (sd SomeDatabase) setPostDeleted(ctx context.Context, id int, deleted bool) int {
+	tx, err := sd.pool.Begin(ctx)
 	if err != nil {
-		return models.Thread{}, err
+		return http.StatusInternalServerError
 	}
+	defer tx.Rollback(ctx)
 
-	return thread, nil
+	var forum string
+	var thread int
+	var wasDeleted bool
+	err = tx.QueryRow(ctx,
+		`SELECT forum, thread, deleted_at IS NOT NULL FROM posts WHERE id = $1 FOR UPDATE`, id).Scan(&forum, &thread, &wasDeleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return http.StatusNotFound
+	}
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	if wasDeleted == deleted {
+		return http.StatusOK
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE posts SET deleted_at = CASE WHEN $1::boolean THEN now() END WHERE id = $2`, deleted, id)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	delta := 1
+	if deleted {
+		delta = -1
+	}
+	_, err = tx.Exec(ctx,
+		`UPDATE forums SET posts = posts + $1 WHERE slug = $2`, delta, forum)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	_, err = tx.Exec(ctx,
+		`UPDATE threads SET post_count = post_count + $1 WHERE id = $2`, delta, thread)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return http.StatusInternalServerError
+	}
+
+	return http.StatusOK
+}
+
+func (sd SomeDatabase) UpdateThread(ctx context.Context, slugOrId string, thread models.Thread) (models.Thread, error) {
+	return sd.updateThread(ctx, `slug = $3`, slugOrId, thread)
+}
+
+// DeleteThread removes a thread with its posts and votes. The forum
+// counters lose the thread and its live posts, and authors that have
+// nothing else in the forum leave its users.
+func (sd SomeDatabase) DeleteThread(ctx context.Context, id int) int {
+	tx, err := sd.pool.Begin(ctx)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	defer tx.Rollback(ctx)
+
+	var forum, author string
+	err = tx.QueryRow(ctx,
+		`SELECT forum, author FROM threads WHERE id = $1 FOR UPDATE`, id).Scan(&forum, &author)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return http.StatusNotFound
+	}
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	var posts int
+	authors := []string{author}
+	rows, err := tx.Query(ctx,
+		`SELECT author, count(*) FILTER (WHERE deleted_at IS NULL) FROM posts WHERE thread = $1 GROUP BY author`, id)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	for rows.Next() {
+		var postAuthor string
+		var live int
+		if err = rows.Scan(&postAuthor, &live); err != nil {
+			rows.Close()
+			return http.StatusInternalServerError
+		}
+		authors = append(authors, postAuthor)
+		posts += live
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return http.StatusInternalServerError
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM votes WHERE thread = $1`, id)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	_, err = tx.Exec(ctx, `DELETE FROM threads WHERE id = $1`, id)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE forums SET threads = threads - 1, posts = posts - $1 WHERE slug = $2`, posts, forum)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM forum_users fu WHERE fu.forum = $1 AND fu.nickname = ANY($2::text[]::citext[])
+			AND NOT EXISTS (SELECT 1 FROM threads t WHERE t.forum = $1 AND t.author = fu.nickname)
+			AND NOT EXISTS (SELECT 1 FROM posts p WHERE p.forum = $1 AND p.author = fu.nickname)`,
+		forum, authors)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return http.StatusInternalServerError
+	}
+
+	return http.StatusOK
+}
+
+// SetThreadArchived archives or unarchives a thread; archiving an archived
+// thread keeps its original archive time.
+func (sd SomeDatabase) SetThreadArchived(ctx context.Context, id int, archived bool) int {
+	tag, err := sd.pool.Exec(ctx,
+		`UPDATE threads SET archived_at = CASE WHEN $1::boolean THEN COALESCE(archived_at, now()) END
+			WHERE id = $2`, archived, id)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	if tag.RowsAffected() == 0 {
+		return http.StatusNotFound
+	}
+
+	return http.StatusOK
+}
+
+// ModerateThread sets the flags given in m. Closing a closed thread keeps
+// its close time and replaces the reason.
+func (sd SomeDatabase) ModerateThread(ctx context.Context, id int, m models.ThreadModeration) int {
+	tag, err := sd.pool.Exec(ctx,
+		`UPDATE threads SET locked = COALESCE($2, locked), pinned = COALESCE($3, pinned),
+			closed_at = CASE WHEN $4::boolean IS NULL THEN closed_at WHEN $4 THEN COALESCE(closed_at, now()) END,
+			close_reason = CASE WHEN $4::boolean IS NULL THEN close_reason WHEN $4 THEN NULLIF($5, '') END
+			WHERE id = $1`, id, m.Locked, m.Pinned, m.Closed, m.Reason)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	if tag.RowsAffected() == 0 {
+		return http.StatusNotFound
+	}
+
+	return http.StatusOK
 }
 
-func (sd SomeDatabase) UpdateVote(id int, vote models.Vote) error {
-	_, err := sd.pool.Exec(context.Background(),
+func (sd SomeDatabase) UpdateVote(ctx context.Context, id int, vote models.Vote) error {
+	tx, err := sd.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
 		`UPDATE votes SET voice = $1 WHERE thread = $2 AND nickname = $3`, vote.Voice,
 		id, vote.Nickname)
-
 	if err != nil {
 		return err
 	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
 
-	return nil
+	if err = addVoteEvent(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (sd SomeDatabase) UpdateThreadById(ctx context.Context, id int, thread models.Thread) (models.Thread, error) {
+	return sd.updateThread(ctx, `id = $3`, id, thread)
 }
 
-func (sd SomeDatabase) UpdateThreadById(id int, thread models.Thread) (models.Thread, error) {
-	err := sd.pool.QueryRow(context.Background(),
-		`UPDATE threads SET message = $1, title = $2 WHERE id = $3
+// updateThread changes the title and message of the thread where selects
+// by key, and adds the event of the change.
+func (sd SomeDatabase) updateThread(ctx context.Context, where string, key interface{}, thread models.Thread) (models.Thread, error) {
+	tx, err := sd.pool.Begin(ctx)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx,
+		`UPDATE threads SET message = $1, title = $2 WHERE `+where+`
 			RETURNING threads.id, threads.author, threads.created, threads.forum,
 			threads.message, threads.slug, threads.title, threads.votes`, thread.Message,
-		thread.Title, id).Scan(&thread.Id, &thread.Author, &thread.Created,
+		thread.Title, key).Scan(&thread.Id, &thread.Author, &thread.Created,
 		&thread.Forum, &thread.Message, &thread.Slug, &thread.Title, &thread.Votes)
+	if err != nil {
+		return models.Thread{}, err
+	}
 
+	err = addEvent(ctx, tx, models.EventThreadUpdated, thread.Forum, int(thread.Id), thread)
 	if err != nil {
 		return models.Thread{}, err
 	}
 
+	if err = tx.Commit(ctx); err != nil {
+		return models.Thread{}, err
+	}
+
 	return thread, nil
 }
 
-func (sd SomeDatabase) IncrementThreads(forum string) error {
-	_, err := sd.pool.Exec(context.Background(),
+func (sd SomeDatabase) IncrementThreads(ctx context.Context, forum string) error {
+	_, err := sd.pool.Exec(ctx,
 		`UPDATE forums SET threads = threads + 1 WHERE slug = $1`, forum)
 
 	if err != nil {
@@ -722,8 +1170,8 @@ func (sd SomeDatabase) IncrementThreads(forum string) error {
 	return nil
 }
 
-func (sd SomeDatabase) IncrementPosts(forum string) error {
-	_, err := sd.pool.Exec(context.Background(),
+func (sd SomeDatabase) IncrementPosts(ctx context.Context, forum string) error {
+	_, err := sd.pool.Exec(ctx,
 		`UPDATE forums SET posts = posts + 1 WHERE slug = $1`, forum)
 
 	if err != nil {
@@ -733,9 +1181,11 @@ func (sd SomeDatabase) IncrementPosts(forum string) error {
 	return nil
 }
 
-func (sd SomeDatabase) Clear() error {
-	_, err := sd.pool.Exec(context.Background(),
-		`TRUNCATE users, forums, threads, posts, votes, forum_users`)
+func (sd SomeDatabase) Clear(ctx context.Context) error {
+	_, err := sd.pool.Exec(ctx,
+		`TRUNCATE users, credentials, sessions, forums, forum_moderators, bans, threads, posts, post_revisions, votes, forum_users, events,
+		webhooks, outbox, webhook_deliveries, forum_subscriptions, notifications, thread_subscriptions, thread_reads,
+		conversations, conversation_participants, messages, blocks`)
 
 	if err != nil {
 		return err
@@ -744,11 +1194,11 @@ func (sd SomeDatabase) Clear() error {
 	return nil
 }
 
-func (sd SomeDatabase) Status() (models.Status, error) {
+func (sd SomeDatabase) Status(ctx context.Context) (models.Status, error) {
 	status := models.Status{}
-	err := sd.pool.QueryRow(context.Background(),
+	err := sd.pool.QueryRow(ctx,
 		`SELECT (SELECT count(id) FROM forums) as forums, 
-			(SELECT count(id) FROM posts) as posts, 
+			(SELECT count(id) FROM posts WHERE deleted_at IS NULL) as posts, 
 			(SELECT count(id) FROM users) as users,
 			(SELECT count(id) FROM threads) as threads`).Scan(&status.Forum, &status.Post, &status.User, &status.Thread)
 
@@ -759,8 +1209,8 @@ func (sd SomeDatabase) Status() (models.Status, error) {
 	return status, nil
 }
 
-func (sd SomeDatabase) CreateUser(nickname string, user models.User) error {
-	_, err := sd.pool.Exec(context.Background(),
+func (sd SomeDatabase) CreateUser(ctx context.Context, nickname string, user models.User) error {
+	_, err := sd.pool.Exec(ctx,
 		`INSERT INTO users VALUES(default, $1, $2, $3, $4)`, nickname, user.Fullname, user.About, user.Email)
 
 	if err != nil {
@@ -770,8 +1220,8 @@ func (sd SomeDatabase) CreateUser(nickname string, user models.User) error {
 	return nil
 }
 
-func (sd SomeDatabase) UpdateUser(nickname string, user models.User) error {
-	_, err := sd.pool.Exec(context.Background(),
+func (sd SomeDatabase) UpdateUser(ctx context.Context, nickname string, user models.User) error {
+	_, err := sd.pool.Exec(ctx,
 		`UPDATE users SET fullname = $1, about = $2, email = $3 WHERE nickname = $4`,
 		user.Fullname, user.About, user.Email, nickname)
 
@@ -782,12 +1232,12 @@ func (sd SomeDatabase) UpdateUser(nickname string, user models.User) error {
 	return nil
 }
 
-func (sd SomeDatabase) GetUserByNicknameOrEmail(nickname string, email string) (models.Users, error) {
+func (sd SomeDatabase) GetUserByNicknameOrEmail(ctx context.Context, nickname string, email string) (models.Users, error) {
 	var users models.Users
-	err := pgxscan.Select(context.Background(), sd.pool, &users,
+	err := pgxscan.Select(ctx, sd.pool, &users,
 		`SELECT nickname, fullname, about, email FROM users WHERE nickname = $1 OR email = $2`, nickname, email)
 
-	if errors.As(err, &pgx.ErrNoRows) || len(users) == 0 {
+	if errors.Is(err, pgx.ErrNoRows) || len(users) == 0 {
 		return models.Users{}, nil
 	}
 
@@ -796,4 +1246,1065 @@ func (sd SomeDatabase) GetUserByNicknameOrEmail(nickname string, email string) (
 	}
 
 	return users, nil
-}
\ No newline at end of file
+}
+// SetCredentials stores the password hash of a user, keeping the role of
+// existing credentials.
+func (sd SomeDatabase) SetCredentials(ctx context.Context, nickname string, passwordHash []byte) error {
+	_, err := sd.pool.Exec(ctx,
+		`INSERT INTO credentials (nickname, password_hash) SELECT nickname, $2 FROM users WHERE nickname = $1
+			ON CONFLICT (nickname) DO UPDATE SET password_hash = excluded.password_hash, updated = now()`,
+		nickname, passwordHash)
+
+	return err
+}
+
+func (sd SomeDatabase) GetCredentials(ctx context.Context, nickname string) (models.Credentials, int) {
+	var credentials models.Credentials
+	err := sd.pool.QueryRow(ctx,
+		`SELECT nickname, password_hash, role FROM credentials WHERE nickname = $1`, nickname).Scan(
+		&credentials.Nickname, &credentials.PasswordHash, &credentials.Role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Credentials{}, http.StatusNotFound
+	}
+	if err != nil {
+		return models.Credentials{}, http.StatusInternalServerError
+	}
+
+	return credentials, http.StatusOK
+}
+
+func (sd SomeDatabase) AddSession(ctx context.Context, tokenHash []byte, nickname string, expires time.Time) error {
+	_, err := sd.pool.Exec(ctx,
+		`INSERT INTO sessions (token_hash, nickname, expires) VALUES ($1, $2, $3)`, tokenHash, nickname, expires)
+
+	return err
+}
+
+// GetSession resolves an unexpired session to its caller.
+func (sd SomeDatabase) GetSession(ctx context.Context, tokenHash []byte) (models.Caller, int) {
+	var caller models.Caller
+	err := sd.pool.QueryRow(ctx,
+		`SELECT sessions.nickname, COALESCE(credentials.role, 'member') FROM sessions
+			LEFT JOIN credentials ON credentials.nickname = sessions.nickname
+			WHERE token_hash = $1 AND expires > now()`, tokenHash).Scan(&caller.Nickname, &caller.Role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Caller{}, http.StatusNotFound
+	}
+	if err != nil {
+		return models.Caller{}, http.StatusInternalServerError
+	}
+
+	return caller, http.StatusOK
+}
+
+func (sd SomeDatabase) DeleteSession(ctx context.Context, tokenHash []byte) error {
+	_, err := sd.pool.Exec(ctx, `DELETE FROM sessions WHERE token_hash = $1`, tokenHash)
+
+	return err
+}
+
+func (sd SomeDatabase) SetRole(ctx context.Context, nickname string, role string) int {
+	tag, err := sd.pool.Exec(ctx,
+		`UPDATE credentials SET role = $2, updated = now() WHERE nickname = $1`, nickname, role)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	if tag.RowsAffected() == 0 {
+		return http.StatusNotFound
+	}
+
+	return http.StatusOK
+}
+
+func (sd SomeDatabase) AddModerator(ctx context.Context, forum string, nickname string) error {
+	_, err := sd.pool.Exec(ctx,
+		`INSERT INTO forum_moderators (forum, nickname) VALUES ($1, $2) ON CONFLICT DO NOTHING`, forum, nickname)
+
+	return err
+}
+
+func (sd SomeDatabase) RemoveModerator(ctx context.Context, forum string, nickname string) int {
+	tag, err := sd.pool.Exec(ctx,
+		`DELETE FROM forum_moderators WHERE forum = $1 AND nickname = $2`, forum, nickname)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	if tag.RowsAffected() == 0 {
+		return http.StatusNotFound
+	}
+
+	return http.StatusOK
+}
+
+func (sd SomeDatabase) GetModerators(ctx context.Context, forum string) (models.Users, error) {
+	users := models.Users{}
+	err := pgxscan.Select(ctx, sd.pool, &users,
+		`SELECT users.nickname, fullname, about, email FROM forum_moderators
+			JOIN users ON users.nickname = forum_moderators.nickname
+			WHERE forum = $1 ORDER BY users.nickname`, forum)
+	if err != nil {
+		return models.Users{}, err
+	}
+
+	return users, nil
+}
+
+func (sd SomeDatabase) IsModerator(ctx context.Context, forum string, nickname string) (bool, error) {
+	var isModerator bool
+	err := sd.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM forum_moderators WHERE forum = $1 AND nickname = $2)`,
+		forum, nickname).Scan(&isModerator)
+
+	return isModerator, err
+}
+
+// banColumns selects a models.Ban; banActive tells whether a ban is in force.
+const (
+	banColumns = `nickname, COALESCE(forum, '') AS forum, reason, COALESCE(banned_by, '') AS banned_by, created, expires`
+	banActive  = `(bans.expires IS NULL OR bans.expires > now())`
+)
+
+func (sd SomeDatabase) AddBan(ctx context.Context, ban models.Ban) error {
+	var expires *time.Time
+	if ban.Expires != nil {
+		t := time.Time(*ban.Expires)
+		expires = &t
+	}
+	_, err := sd.pool.Exec(ctx,
+		`INSERT INTO bans (nickname, forum, reason, banned_by, created, expires)
+			VALUES ($1, NULLIF($2::text, ''), $3, NULLIF($4::text, ''), $5, $6)
+			ON CONFLICT (nickname, COALESCE(forum, '')) DO UPDATE SET reason = excluded.reason,
+				banned_by = excluded.banned_by, created = excluded.created, expires = excluded.expires`,
+		ban.Nickname, ban.Forum, ban.Reason, ban.BannedBy, time.Time(ban.Created), expires)
+
+	return err
+}
+
+func (sd SomeDatabase) RemoveBan(ctx context.Context, forum string, nickname string) int {
+	tag, err := sd.pool.Exec(ctx,
+		`DELETE FROM bans WHERE nickname = $1 AND COALESCE(forum, '') = $2`, nickname, forum)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	if tag.RowsAffected() == 0 {
+		return http.StatusNotFound
+	}
+
+	return http.StatusOK
+}
+
+func (sd SomeDatabase) GetForumBans(ctx context.Context, forum string) (models.Bans, error) {
+	bans := models.Bans{}
+	err := pgxscan.Select(ctx, sd.pool, &bans,
+		`SELECT `+banColumns+` FROM bans
+			WHERE (forum IS NULL OR forum = $1) AND `+banActive+`
+			ORDER BY created DESC, nickname`, forum)
+	if err != nil {
+		return nil, err
+	}
+
+	return bans, nil
+}
+
+func (sd SomeDatabase) FindBans(ctx context.Context, forum string, nicknames []string) (models.Bans, error) {
+	bans := models.Bans{}
+	err := pgxscan.Select(ctx, sd.pool, &bans,
+		`SELECT `+banColumns+` FROM bans
+			WHERE nickname = ANY($2::text[]::citext[]) AND (forum IS NULL OR forum = $1) AND `+banActive,
+		forum, nicknames)
+	if err != nil {
+		return nil, err
+	}
+
+	return bans, nil
+}
+
+// The search vectors are the expressions of the posts_search and
+// threads_search indexes.
+const (
+	postVector   = `to_tsvector('english', posts.message)`
+	threadVector = `(setweight(to_tsvector('english', threads.title::text), 'A') ||
+		setweight(to_tsvector('english', threads.message), 'B'))`
+	headlineOptions = `'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10'`
+	// escapedBody is the body of a hit with the HTML special characters
+	// replaced by entities, which ts_headline keeps whole, so that the only
+	// markup in a snippet is the <mark> tags it adds.
+	escapedBody = `replace(replace(replace(replace(replace(body,
+		'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+)
+
+// Search ranks the matching posts and threads together. Snippets are only
+// built for the page that is returned.
+func (sd SomeDatabase) Search(ctx context.Context, query models.SearchQuery) (models.SearchHits, error) {
+	args := []interface{}{query.Text}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	filters := func(table string) string {
+		var where string
+		if query.Forum != "" {
+			where += ` AND ` + table + `.forum = ` + arg(query.Forum)
+		}
+		if query.Author != "" {
+			where += ` AND ` + table + `.author = ` + arg(query.Author)
+		}
+		if query.From != nil {
+			where += ` AND ` + table + `.created >= ` + arg(*query.From)
+		}
+		if query.To != nil {
+			where += ` AND ` + table + `.created < ` + arg(*query.To)
+		}
+		return where
+	}
+
+	var parts []string
+	if query.Kind != models.HitThread {
+		where := filters("posts")
+		if query.Thread != 0 {
+			where += ` AND posts.thread = ` + arg(query.Thread)
+		}
+		parts = append(parts, `SELECT 'post' AS kind, posts.id, posts.thread::bigint AS thread,
+				posts.forum::text AS forum, posts.author::text AS author, '' AS title, posts.message AS body,
+				ts_rank_cd(`+postVector+`, q.query) AS rank, COALESCE(posts.created, to_timestamp(0)) AS created
+			FROM posts, q WHERE `+postVector+` @@ q.query AND posts.deleted_at IS NULL`+where)
+	}
+	if query.Kind != models.HitPost {
+		where := filters("threads")
+		if query.Thread != 0 {
+			where += ` AND threads.id = ` + arg(query.Thread)
+		}
+		parts = append(parts, `SELECT 'thread' AS kind, threads.id::bigint AS id, threads.id::bigint AS thread,
+				threads.forum::text AS forum, threads.author::text AS author, threads.title::text AS title,
+				threads.title::text || ' ' || threads.message AS body,
+				ts_rank_cd(`+threadVector+`, q.query) AS rank, COALESCE(threads.created, to_timestamp(0)) AS created
+			FROM threads, q WHERE `+threadVector+` @@ q.query`+where)
+	}
+
+	after := ""
+	if query.After != nil {
+		after = `WHERE (rank, kind, id) < (` + arg(query.After.Rank) + `::real, ` +
+			arg(query.After.Kind) + `::text, ` + arg(query.After.Id) + `::bigint)`
+	}
+
+	hits := models.SearchHits{}
+	err := pgxscan.Select(ctx, sd.pool, &hits,
+		`WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query)
+		SELECT kind, id, thread, forum, author, title,
+			ts_headline('english', `+escapedBody+`, q.query, `+headlineOptions+`) AS snippet, rank, created
+		FROM (SELECT * FROM (`+strings.Join(parts, ` UNION ALL `)+`) hits `+after+`
+			ORDER BY rank DESC, kind DESC, id DESC LIMIT `+arg(query.Limit)+`) page, q
+		ORDER BY rank DESC, kind DESC, id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
+
+// likeEscape quotes the LIKE wildcards in s.
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// likePrefix makes a LIKE pattern matching strings that start with s.
+func likePrefix(s string) string {
+	return likeEscape(s) + "%"
+}
+
+// FindUsers scores prefix matches of the nickname above those of a word of
+// the full name, and both above fuzzy ones, which score their trigram
+// similarity.
+func (sd SomeDatabase) FindUsers(ctx context.Context, query models.UserQuery) ([]models.UserMatch, error) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	score := `0::real`
+	where := `TRUE`
+	if text := strings.ToLower(query.Text); text != "" {
+		q, prefix, wordPrefix := arg(text), arg(likePrefix(text)), arg("% "+likePrefix(text))
+		fullnamePrefix := `(lower(fullname::text) LIKE ` + prefix + ` OR lower(fullname::text) LIKE ` + wordPrefix + `)`
+		score = `(CASE WHEN lower(nickname::text) LIKE ` + prefix + ` THEN 2
+			WHEN ` + fullnamePrefix + ` THEN 1.5
+			ELSE greatest(similarity(lower(nickname::text), ` + q + `), similarity(lower(fullname::text), ` + q + `)) END)::real`
+		where = `(lower(nickname::text) LIKE ` + prefix + ` OR ` + fullnamePrefix + `
+			OR lower(nickname::text) % ` + q + ` OR lower(fullname::text) % ` + q + `)`
+	}
+	if query.Forum != "" {
+		where += ` AND EXISTS (SELECT 1 FROM forum_users
+			WHERE forum_users.forum = ` + arg(query.Forum) + ` AND forum_users.nickname = users.nickname)`
+	}
+
+	after := `TRUE`
+	order := `score DESC, nickname`
+	if query.Sort == models.UsersByNickname {
+		order = `nickname`
+		if query.Desc {
+			order = `nickname DESC`
+		}
+		if query.After != nil {
+			op := `>`
+			if query.Desc {
+				op = `<`
+			}
+			after = `nickname ` + op + ` ` + arg(query.After.Nickname)
+		}
+	} else if query.After != nil {
+		s, n := arg(query.After.Score), arg(query.After.Nickname)
+		after = `(score < ` + s + `::real OR score = ` + s + `::real AND nickname > ` + n + `)`
+	}
+
+	matches := []models.UserMatch{}
+	err := pgxscan.Select(ctx, sd.pool, &matches,
+		`SELECT nickname, fullname, about, email, score FROM (
+			SELECT nickname, fullname, about, email, `+score+` AS score FROM users WHERE `+where+`
+		) matches WHERE `+after+` ORDER BY `+order+` LIMIT `+arg(query.Limit), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// eventChannel is the NOTIFY channel of the event log; the payload is the
+// id of the new event.
+const eventChannel = "subd_events"
+
+// addEvent appends an event about a change to the log in the transaction
+// of the change, so that no event is lost or sent for a change that is
+// rolled back. The servers following the log hear of it on commit.
+func addEvent(ctx context.Context, tx pgx.Tx, kind string, forum string, thread int, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `SELECT events_add($1, $2, $3, $4::json)`, kind, forum, thread, string(b))
+
+	return err
+}
+
+// settledEvents bounds the ids of the events a reader may be given: events
+// commit out of id order, so the log stops short of the first gap in the
+// ids after $1 that a running transaction may still fill.
+const settledEvents = `COALESCE((SELECT min(e.id) FROM events e
+	WHERE e.id > $1 + 1 AND e.horizon > txid_snapshot_xmin(txid_current_snapshot())
+		AND NOT EXISTS (SELECT 1 FROM events p WHERE p.id = e.id - 1)), 9223372036854775807)`
+
+func (sd SomeDatabase) GetEvents(ctx context.Context, query models.EventQuery) (models.Events, error) {
+	args := []interface{}{query.After}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	where := `id > $1 AND id < ` + settledEvents
+	if query.Thread != 0 {
+		where += ` AND thread = ` + arg(query.Thread)
+	}
+	if query.Forum != "" {
+		where += ` AND forum = ` + arg(query.Forum)
+	}
+
+	var events models.Events
+	err := pgxscan.Select(ctx, sd.pool, &events,
+		`SELECT id, type, forum, thread, data, created FROM events
+		WHERE `+where+` ORDER BY id LIMIT `+arg(query.Limit), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (sd SomeDatabase) LastEventId(ctx context.Context) (int64, error) {
+	var id int64
+	err := sd.pool.QueryRow(ctx,
+		`SELECT COALESCE(max(id), 0) FROM events WHERE id < `+settledEvents, 0).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// ListenEvents holds a pooled connection for as long as it listens. The
+// connection is closed rather than released, so that no other caller gets
+// a connection with a pending LISTEN.
+func (sd SomeDatabase) ListenEvents(ctx context.Context, notify func()) error {
+	conn, err := sd.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	defer conn.Conn().Close(context.Background())
+
+	_, err = conn.Exec(ctx, `LISTEN `+eventChannel)
+	if err != nil {
+		return err
+	}
+	notify()
+
+	for {
+		_, err = conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		notify()
+	}
+}
+
+func (sd SomeDatabase) PruneEvents(ctx context.Context, before time.Time) error {
+	_, err := sd.pool.Exec(ctx, `DELETE FROM events WHERE created < $1`, before)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// webhookColumns selects a models.Webhook; deliveryColumns selects a
+// models.Delivery of webhook_deliveries d joined with the outbox o.
+const (
+	webhookColumns  = `id, url, COALESCE(forum, '') AS forum, events, secret, created`
+	deliveryColumns = `d.id, d.webhook, d.event, o.type, d.state, d.attempts,
+		CASE WHEN d.state = 'pending' THEN d.next_attempt END AS next_attempt,
+		COALESCE(d.last_status, 0) AS last_status, COALESCE(d.last_error, '') AS last_error,
+		d.created, d.delivered_at`
+)
+
+func (sd SomeDatabase) AddWebhook(ctx context.Context, hook *models.Webhook) error {
+	var created time.Time
+	err := sd.pool.QueryRow(ctx,
+		`INSERT INTO webhooks (url, forum, events, secret) VALUES ($1, NULLIF($2::text, ''), $3, $4)
+		RETURNING id, created`,
+		hook.Url, hook.Forum, hook.Events, hook.Secret).Scan(&hook.Id, &created)
+	if err != nil {
+		return err
+	}
+	hook.Created = strfmt.DateTime(created)
+
+	return nil
+}
+
+func (sd SomeDatabase) GetWebhooks(ctx context.Context, forum string) (models.Webhooks, error) {
+	hooks := models.Webhooks{}
+	err := pgxscan.Select(ctx, sd.pool, &hooks,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE $1::text = '' OR forum = $1::citext ORDER BY id`, forum)
+	if err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+func (sd SomeDatabase) GetWebhook(ctx context.Context, id int) (models.Webhook, int) {
+	var hook models.Webhook
+	err := pgxscan.Get(ctx, sd.pool, &hook, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Webhook{}, http.StatusNotFound
+	}
+	if err != nil {
+		return models.Webhook{}, http.StatusInternalServerError
+	}
+
+	return hook, http.StatusOK
+}
+
+func (sd SomeDatabase) DeleteWebhook(ctx context.Context, id int) int {
+	tag, err := sd.pool.Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	if tag.RowsAffected() == 0 {
+		return http.StatusNotFound
+	}
+
+	return http.StatusOK
+}
+
+// DispatchOutbox skips the events another dispatcher is taking.
+func (sd SomeDatabase) DispatchOutbox(ctx context.Context, limit int) (int, error) {
+	var taken int
+	err := sd.pool.QueryRow(ctx,
+		`WITH batch AS (
+			UPDATE outbox SET dispatched = TRUE
+			WHERE id IN (SELECT id FROM outbox WHERE NOT dispatched ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED)
+			RETURNING id, type, forum
+		), deliveries AS (
+			INSERT INTO webhook_deliveries (webhook, event)
+			SELECT w.id, batch.id FROM batch JOIN webhooks w
+				ON (w.forum IS NULL OR w.forum = batch.forum)
+				AND (cardinality(w.events) = 0 OR batch.type = ANY (w.events))
+			ORDER BY batch.id, w.id
+		)
+		SELECT count(*) FROM batch`, limit).Scan(&taken)
+	if err != nil {
+		return 0, err
+	}
+
+	return taken, nil
+}
+
+func (sd SomeDatabase) ClaimDeliveries(ctx context.Context, now time.Time, until time.Time, limit int) ([]models.DueDelivery, error) {
+	rows, err := sd.pool.Query(ctx,
+		`WITH due AS (
+			UPDATE webhook_deliveries SET next_attempt = $2
+			WHERE id IN (SELECT id FROM webhook_deliveries
+				WHERE state = 'pending' AND next_attempt <= $1
+				ORDER BY next_attempt LIMIT $3 FOR UPDATE SKIP LOCKED)
+			RETURNING id, webhook, event, attempts
+		)
+		SELECT due.id, due.attempts, w.url, w.secret, o.id, o.type, COALESCE(o.forum, ''), o.data, o.created
+		FROM due JOIN webhooks w ON w.id = due.webhook JOIN outbox o ON o.id = due.event
+		ORDER BY due.id`, now, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.DueDelivery
+	for rows.Next() {
+		var d models.DueDelivery
+		var data []byte
+		var created time.Time
+		err = rows.Scan(&d.Id, &d.Attempts, &d.Url, &d.Secret,
+			&d.Event.Id, &d.Event.Type, &d.Event.Forum, &data, &created)
+		if err != nil {
+			return nil, err
+		}
+		d.Event.Data = data
+		d.Event.Created = strfmt.DateTime(created)
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (sd SomeDatabase) RecordDelivery(ctx context.Context, id int64, result models.DeliveryResult) error {
+	state := models.DeliveryPending
+	switch {
+	case result.Delivered:
+		state = models.DeliveryDelivered
+	case result.Dead:
+		state = models.DeliveryDead
+	}
+	_, err := sd.pool.Exec(ctx,
+		`UPDATE webhook_deliveries SET state = $2, attempts = attempts + 1, next_attempt = $3,
+			last_status = NULLIF($4, 0), last_error = NULLIF($5, ''),
+			delivered_at = CASE WHEN $6 THEN now() END
+		WHERE id = $1`,
+		id, state, result.NextAttempt, result.Status, result.Error, result.Delivered)
+
+	return err
+}
+
+func (sd SomeDatabase) GetDeliveries(ctx context.Context, webhook int, state string, limit int) (models.Deliveries, error) {
+	deliveries := models.Deliveries{}
+	err := pgxscan.Select(ctx, sd.pool, &deliveries,
+		`SELECT `+deliveryColumns+` FROM webhook_deliveries d JOIN outbox o ON o.id = d.event
+		WHERE d.webhook = $1 AND ($2 = '' OR d.state = $2)
+		ORDER BY d.id DESC LIMIT $3`, webhook, state, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (sd SomeDatabase) Redeliver(ctx context.Context, webhook int, id int64) (models.Delivery, int) {
+	var delivery models.Delivery
+	err := pgxscan.Get(ctx, sd.pool, &delivery,
+		`WITH d AS (
+			UPDATE webhook_deliveries SET state = 'pending', attempts = 0, next_attempt = now()
+			WHERE id = $1 AND webhook = $2
+			RETURNING *
+		)
+		SELECT `+deliveryColumns+` FROM d JOIN outbox o ON o.id = d.event`, id, webhook)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Delivery{}, http.StatusNotFound
+	}
+	if err != nil {
+		return models.Delivery{}, http.StatusInternalServerError
+	}
+
+	return delivery, http.StatusOK
+}
+
+func (sd SomeDatabase) PruneDeliveries(ctx context.Context, before time.Time) error {
+	_, err := sd.pool.Exec(ctx,
+		`DELETE FROM webhook_deliveries WHERE state <> 'pending' AND created < $1`, before)
+	if err != nil {
+		return err
+	}
+	_, err = sd.pool.Exec(ctx,
+		`DELETE FROM outbox WHERE dispatched AND created < $1
+			AND NOT EXISTS (SELECT 1 FROM webhook_deliveries d WHERE d.event = outbox.id)`, before)
+
+	return err
+}
+
+func (sd SomeDatabase) SubscribeForum(ctx context.Context, forum string, nickname string) error {
+	_, err := sd.pool.Exec(ctx,
+		`INSERT INTO forum_subscriptions (forum, nickname) VALUES ($1, $2) ON CONFLICT DO NOTHING`, forum, nickname)
+
+	return err
+}
+
+func (sd SomeDatabase) UnsubscribeForum(ctx context.Context, forum string, nickname string) int {
+	tag, err := sd.pool.Exec(ctx,
+		`DELETE FROM forum_subscriptions WHERE forum = $1 AND nickname = $2`, forum, nickname)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	if tag.RowsAffected() == 0 {
+		return http.StatusNotFound
+	}
+
+	return http.StatusOK
+}
+
+func (sd SomeDatabase) GetNotifications(ctx context.Context, query models.NotificationQuery) (models.Notifications, error) {
+	args := []interface{}{query.Nickname}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	where := `nickname = $1`
+	if query.Unread {
+		where += ` AND read_at IS NULL`
+	}
+	if query.After != 0 {
+		where += ` AND id < ` + arg(query.After)
+	}
+
+	notifications := models.Notifications{}
+	err := pgxscan.Select(ctx, sd.pool, &notifications,
+		`SELECT id, type, actor, forum, thread, COALESCE(post, 0) AS post, read_at IS NOT NULL AS read, created
+		FROM notifications WHERE `+where+` ORDER BY id DESC LIMIT `+arg(query.Limit), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (sd SomeDatabase) CountUnreadNotifications(ctx context.Context, nickname string) (map[string]int, error) {
+	rows, err := sd.pool.Query(ctx,
+		`SELECT type, count(*) FROM notifications WHERE nickname = $1 AND read_at IS NULL GROUP BY type`, nickname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var kind string
+		var count int
+		if err := rows.Scan(&kind, &count); err != nil {
+			return nil, err
+		}
+		counts[kind] = count
+	}
+
+	return counts, rows.Err()
+}
+
+func (sd SomeDatabase) MarkNotificationRead(ctx context.Context, nickname string, id int64) int {
+	tag, err := sd.pool.Exec(ctx,
+		`UPDATE notifications SET read_at = COALESCE(read_at, now()) WHERE id = $1 AND nickname = $2`, id, nickname)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	if tag.RowsAffected() == 0 {
+		return http.StatusNotFound
+	}
+
+	return http.StatusOK
+}
+
+func (sd SomeDatabase) MarkNotificationsRead(ctx context.Context, nickname string, upTo int64) (int, error) {
+	tag, err := sd.pool.Exec(ctx,
+		`UPDATE notifications SET read_at = now()
+		WHERE nickname = $1 AND read_at IS NULL AND ($2::bigint = 0 OR id <= $2)`, nickname, upTo)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+func (sd SomeDatabase) SubscribeThread(ctx context.Context, thread int, nickname string) error {
+	_, err := sd.pool.Exec(ctx,
+		`INSERT INTO thread_subscriptions (thread, nickname) VALUES ($1, $2) ON CONFLICT DO NOTHING`, thread, nickname)
+
+	return err
+}
+
+func (sd SomeDatabase) UnsubscribeThread(ctx context.Context, thread int, nickname string) int {
+	tag, err := sd.pool.Exec(ctx,
+		`DELETE FROM thread_subscriptions WHERE thread = $1 AND nickname = $2`, thread, nickname)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	if tag.RowsAffected() == 0 {
+		return http.StatusNotFound
+	}
+
+	return http.StatusOK
+}
+
+// unreadPosts counts the live posts of others in thread t after the last
+// one nickname read, r.last_read.
+const unreadPosts = `(SELECT count(*) FROM posts p
+	WHERE p.thread = t.id AND p.id > COALESCE(r.last_read, 0) AND p.deleted_at IS NULL AND p.author <> $1)`
+
+func (sd SomeDatabase) GetSubscriptions(ctx context.Context, nickname string) (models.Subscriptions, error) {
+	subscriptions := models.Subscriptions{Forums: []models.ForumSubscription{}, Threads: []models.ThreadSubscription{}}
+	err := pgxscan.Select(ctx, sd.pool, &subscriptions.Forums,
+		`SELECT f.slug AS forum, f.title, s.created,
+			(SELECT count(*) FROM notifications n
+			WHERE n.nickname = $1 AND n.forum = f.slug AND n.type = 'thread' AND n.read_at IS NULL) AS unread
+		FROM forum_subscriptions s JOIN forums f ON f.slug = s.forum
+		WHERE s.nickname = $1 ORDER BY f.slug`, nickname)
+	if err != nil {
+		return models.Subscriptions{}, err
+	}
+	err = pgxscan.Select(ctx, sd.pool, &subscriptions.Threads,
+		`SELECT t.id AS thread, COALESCE(t.slug, '') AS slug, t.title, t.forum, s.created,
+			COALESCE(r.last_read, 0) AS last_read, `+unreadPosts+` AS unread
+		FROM thread_subscriptions s JOIN threads t ON t.id = s.thread
+			LEFT JOIN thread_reads r ON r.nickname = s.nickname AND r.thread = s.thread
+		WHERE s.nickname = $1 ORDER BY t.id`, nickname)
+	if err != nil {
+		return models.Subscriptions{}, err
+	}
+
+	return subscriptions, nil
+}
+
+func (sd SomeDatabase) GetLastRead(ctx context.Context, thread int, nickname string) (int, error) {
+	var lastRead int
+	err := sd.pool.QueryRow(ctx,
+		`SELECT last_read FROM thread_reads WHERE nickname = $1 AND thread = $2`, nickname, thread).Scan(&lastRead)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return lastRead, nil
+}
+
+func (sd SomeDatabase) MarkThreadRead(ctx context.Context, thread int, nickname string, post int) (models.ThreadRead, error) {
+	tx, err := sd.pool.Begin(ctx)
+	if err != nil {
+		return models.ThreadRead{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	read := models.ThreadRead{Thread: thread, Nickname: nickname}
+	err = tx.QueryRow(ctx,
+		`INSERT INTO thread_reads (nickname, thread, last_read)
+		VALUES ($1, $2, CASE WHEN $3::bigint = 0 THEN (SELECT COALESCE(max(id), 0) FROM posts WHERE thread = $2) ELSE $3 END)
+		ON CONFLICT (nickname, thread) DO UPDATE
+			SET last_read = GREATEST(thread_reads.last_read, excluded.last_read), updated = now()
+		RETURNING last_read`, nickname, thread, post).Scan(&read.LastRead)
+	if err != nil {
+		return models.ThreadRead{}, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE notifications SET read_at = now()
+		WHERE nickname = $1 AND thread = $2 AND read_at IS NULL AND (post IS NULL OR post <= $3)`,
+		nickname, thread, read.LastRead)
+	if err != nil {
+		return models.ThreadRead{}, err
+	}
+
+	err = tx.QueryRow(ctx,
+		`SELECT `+unreadPosts+` FROM threads t, thread_reads r
+		WHERE t.id = $2 AND r.nickname = $1 AND r.thread = t.id`, nickname, thread).Scan(&read.Unread)
+	if err != nil {
+		return models.ThreadRead{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.ThreadRead{}, err
+	}
+
+	return read, nil
+}
+
+// conversationColumns selects a models.Conversation of conversations c as
+// participant p sees it. unreadMessages counts the messages of others
+// after the last one p read, leaving out those of users p blocked.
+const (
+	unreadMessages = `(SELECT count(*) FROM messages m
+		WHERE m.conversation = c.id AND m.id > p.last_read AND m.author <> p.nickname
+			AND NOT EXISTS (SELECT 1 FROM blocks b WHERE b.nickname = p.nickname AND b.blocked = m.author))`
+	conversationColumns = `c.id, c.title, c.creator, c.last_message, c.created, ` + unreadMessages + ` AS unread`
+	messageColumns      = `id, conversation, author, message, edited_at IS NOT NULL AS is_edited, created, edited_at`
+)
+
+// addMessage writes a message, makes it the last of its conversation and
+// moves the read marker of its author to it.
+func addMessage(ctx context.Context, tx pgx.Tx, message *models.PrivateMessage) error {
+	var created time.Time
+	err := tx.QueryRow(ctx,
+		`INSERT INTO messages (conversation, author, message) VALUES ($1, $2, $3) RETURNING id, created`,
+		message.Conversation, message.Author, message.Message).Scan(&message.Id, &created)
+	if err != nil {
+		return err
+	}
+	message.Created = strfmt.DateTime(created)
+
+	_, err = tx.Exec(ctx,
+		`UPDATE conversations SET last_message = GREATEST(last_message, $2) WHERE id = $1`,
+		message.Conversation, message.Id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx,
+		`UPDATE conversation_participants SET last_read = GREATEST(last_read, $3)
+		WHERE conversation = $1 AND nickname = $2`,
+		message.Conversation, message.Author, message.Id)
+
+	return err
+}
+
+// participants fills in the participants of conversations.
+func (sd SomeDatabase) participants(ctx context.Context, conversations models.Conversations) error {
+	ids := make([]int, len(conversations))
+	index := make(map[int]int, len(conversations))
+	for i := range conversations {
+		ids[i] = conversations[i].Id
+		index[conversations[i].Id] = i
+		conversations[i].Participants = []models.Participant{}
+	}
+
+	rows, err := sd.pool.Query(ctx,
+		`SELECT conversation, nickname, last_read, joined FROM conversation_participants
+		WHERE conversation = ANY($1) ORDER BY conversation, nickname`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var p models.Participant
+		var joined time.Time
+		if err := rows.Scan(&id, &p.Nickname, &p.LastRead, &joined); err != nil {
+			return err
+		}
+		p.Joined = strfmt.DateTime(joined)
+		c := &conversations[index[id]]
+		c.Participants = append(c.Participants, p)
+	}
+
+	return rows.Err()
+}
+
+func (sd SomeDatabase) AddConversation(ctx context.Context, conversation *models.Conversation, message *models.PrivateMessage) error {
+	tx, err := sd.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var created time.Time
+	err = tx.QueryRow(ctx,
+		`INSERT INTO conversations (title, creator) VALUES ($1, $2) RETURNING id, created`,
+		conversation.Title, conversation.Creator).Scan(&conversation.Id, &created)
+	if err != nil {
+		return err
+	}
+	conversation.Created = strfmt.DateTime(created)
+
+	nicknames := make([]string, len(conversation.Participants))
+	for i, p := range conversation.Participants {
+		nicknames[i] = p.Nickname
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO conversation_participants (conversation, nickname, joined)
+		SELECT $1, nickname, $3 FROM unnest($2::text[]) AS nickname`, conversation.Id, nicknames, created)
+	if err != nil {
+		return err
+	}
+
+	message.Conversation = conversation.Id
+	message.Author = conversation.Creator
+	if err := addMessage(ctx, tx, message); err != nil {
+		return err
+	}
+	conversation.LastMessage = message.Id
+	for i := range conversation.Participants {
+		conversation.Participants[i].Joined = conversation.Created
+		if conversation.Participants[i].Nickname == conversation.Creator {
+			conversation.Participants[i].LastRead = message.Id
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (sd SomeDatabase) GetConversation(ctx context.Context, id int, nickname string) (models.Conversation, int) {
+	conversations := models.Conversations{}
+	err := pgxscan.Select(ctx, sd.pool, &conversations,
+		`SELECT `+conversationColumns+`
+		FROM conversations c JOIN conversation_participants p ON p.conversation = c.id
+		WHERE c.id = $1 AND p.nickname = $2`, id, nickname)
+	if err != nil {
+		return models.Conversation{}, http.StatusInternalServerError
+	}
+	if len(conversations) == 0 {
+		return models.Conversation{}, http.StatusNotFound
+	}
+	if err := sd.participants(ctx, conversations); err != nil {
+		return models.Conversation{}, http.StatusInternalServerError
+	}
+
+	return conversations[0], http.StatusOK
+}
+
+func (sd SomeDatabase) GetConversations(ctx context.Context, query models.ConversationQuery) (models.Conversations, error) {
+	conversations := models.Conversations{}
+	err := pgxscan.Select(ctx, sd.pool, &conversations,
+		`SELECT `+conversationColumns+`
+		FROM conversations c JOIN conversation_participants p ON p.conversation = c.id
+		WHERE p.nickname = $1 AND ($2::bigint = 0 OR c.last_message < $2)
+		ORDER BY c.last_message DESC LIMIT $3`, query.Nickname, query.After, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := sd.participants(ctx, conversations); err != nil {
+		return nil, err
+	}
+
+	return conversations, nil
+}
+
+func (sd SomeDatabase) AddPrivateMessage(ctx context.Context, message *models.PrivateMessage) error {
+	tx, err := sd.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := addMessage(ctx, tx, message); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (sd SomeDatabase) GetPrivateMessage(ctx context.Context, conversation int, id int64) (models.PrivateMessage, int) {
+	var message models.PrivateMessage
+	err := pgxscan.Get(ctx, sd.pool, &message,
+		`SELECT `+messageColumns+` FROM messages WHERE id = $1 AND conversation = $2`, id, conversation)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.PrivateMessage{}, http.StatusNotFound
+	}
+	if err != nil {
+		return models.PrivateMessage{}, http.StatusInternalServerError
+	}
+
+	return message, http.StatusOK
+}
+
+func (sd SomeDatabase) GetPrivateMessages(ctx context.Context, query models.MessageQuery) (models.PrivateMessages, error) {
+	where, order := `id > $3`, `id`
+	if query.Desc {
+		where, order = `($3::bigint = 0 OR id < $3)`, `id DESC`
+	}
+
+	messages := models.PrivateMessages{}
+	err := pgxscan.Select(ctx, sd.pool, &messages,
+		`SELECT `+messageColumns+` FROM messages m
+		WHERE conversation = $1 AND `+where+`
+			AND NOT EXISTS (SELECT 1 FROM blocks b WHERE b.nickname = $2 AND b.blocked = m.author)
+		ORDER BY `+order+` LIMIT $4`, query.Conversation, query.Reader, query.Since, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (sd SomeDatabase) EditPrivateMessage(ctx context.Context, conversation int, id int64, message string) (models.PrivateMessage, int) {
+	var edited models.PrivateMessage
+	err := pgxscan.Get(ctx, sd.pool, &edited,
+		`UPDATE messages SET message = $3, edited_at = now() WHERE id = $1 AND conversation = $2
+		RETURNING `+messageColumns, id, conversation, message)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.PrivateMessage{}, http.StatusNotFound
+	}
+	if err != nil {
+		return models.PrivateMessage{}, http.StatusInternalServerError
+	}
+
+	return edited, http.StatusOK
+}
+
+func (sd SomeDatabase) MarkConversationRead(ctx context.Context, conversation int, nickname string, upTo int64) (models.ConversationRead, error) {
+	read := models.ConversationRead{Conversation: conversation, Nickname: nickname}
+	err := sd.pool.QueryRow(ctx,
+		`UPDATE conversation_participants p
+		SET last_read = GREATEST(p.last_read, CASE WHEN $3::bigint = 0 THEN c.last_message ELSE $3 END)
+		FROM conversations c
+		WHERE c.id = p.conversation AND p.conversation = $1 AND p.nickname = $2
+		RETURNING p.last_read, `+unreadMessages, conversation, nickname, upTo).Scan(&read.LastRead, &read.Unread)
+	if err != nil {
+		return models.ConversationRead{}, err
+	}
+
+	return read, nil
+}
+
+func (sd SomeDatabase) Block(ctx context.Context, nickname string, blocked string) error {
+	_, err := sd.pool.Exec(ctx,
+		`INSERT INTO blocks (nickname, blocked) VALUES ($1, $2) ON CONFLICT DO NOTHING`, nickname, blocked)
+
+	return err
+}
+
+func (sd SomeDatabase) Unblock(ctx context.Context, nickname string, blocked string) int {
+	tag, err := sd.pool.Exec(ctx, `DELETE FROM blocks WHERE nickname = $1 AND blocked = $2`, nickname, blocked)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	if tag.RowsAffected() == 0 {
+		return http.StatusNotFound
+	}
+
+	return http.StatusOK
+}
+
+func (sd SomeDatabase) GetBlocks(ctx context.Context, nickname string) (models.Blocks, error) {
+	blocks := models.Blocks{}
+	err := pgxscan.Select(ctx, sd.pool, &blocks,
+		`SELECT nickname, blocked, created FROM blocks WHERE nickname = $1 ORDER BY blocked`, nickname)
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+func (sd SomeDatabase) Blocking(ctx context.Context, nickname string, others []string) ([]string, error) {
+	blocking := []string{}
+	err := pgxscan.Select(ctx, sd.pool, &blocking,
+		`SELECT blocked::text FROM blocks WHERE nickname = $1 AND blocked = ANY($2::citext[])
+		UNION
+		SELECT nickname::text FROM blocks WHERE blocked = $1 AND nickname = ANY($2::citext[])`, nickname, others)
+	if err != nil {
+		return nil, err
+	}
+
+	return blocking, nil
+}
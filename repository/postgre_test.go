@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	smth "subd"
+	"subd/migrations"
+	"subd/models"
+	"subd/repository/repotest"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// testDatabase connects to the database SUBD_TEST_DSN names, migrates it
+// and empties it. Tests needing PostgreSQL are skipped without one; the
+// database is cleared, so never point it at one that matters.
+func testDatabase(t *testing.T) *SomeDatabase {
+	t.Helper()
+	dsn := os.Getenv("SUBD_TEST_DSN")
+	if dsn == "" {
+		t.Skip("SUBD_TEST_DSN is not set")
+	}
+	ctx := context.Background()
+
+	pool, err := pgxpool.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connecting to %s: %v", dsn, err)
+	}
+	t.Cleanup(pool.Close)
+
+	migrator, err := migrations.NewMigrator(pool, migrations.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	sd := &SomeDatabase{pool: pool}
+	if err := sd.Clear(ctx); err != nil {
+		t.Fatalf("clearing: %v", err)
+	}
+
+	return sd
+}
+
+// postsFixture makes the users alice and bob, alice's forum and a thread
+// in it with a single post, which new posts may reply to.
+func postsFixture(t *testing.T, sd *SomeDatabase) (models.Thread, *models.Post) {
+	t.Helper()
+	ctx := context.Background()
+
+	for _, nickname := range []string{"alice", "bob"} {
+		err := sd.CreateUser(ctx, nickname, models.User{Fullname: nickname, Email: strfmt.Email(nickname + "@example.com")})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err, _ := sd.AddNewForum(ctx, &models.Forum{Title: "Forum", Owner: "alice", Slug: "forum"}); err != nil {
+		t.Fatal(err)
+	}
+	id, err := sd.AddNewThread(ctx, models.Thread{Author: "alice", Forum: "forum", Title: "Thread", Message: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	thread, status := sd.GetThreadById(ctx, int(id))
+	if status != http.StatusOK {
+		t.Fatalf("GetThreadById = %d", status)
+	}
+
+	root := &models.Post{Author: "alice", Message: "root"}
+	if status := sd.AddPost(ctx, []*models.Post{root}, thread, time.Now()); status != http.StatusCreated {
+		t.Fatalf("AddPost = %d", status)
+	}
+
+	return thread, root
+}
+
+func TestAddPostRollsBackFailedBatches(t *testing.T) {
+	sd := testDatabase(t)
+	ctx := context.Background()
+	thread, root := postsFixture(t, sd)
+
+	// Bob writes nothing but failed batches: a failure after the posts are
+	// copied in makes the last statement of the batch fail.
+	_, err := sd.pool.Exec(ctx, `
+		CREATE OR REPLACE FUNCTION refuse_bob() RETURNS TRIGGER AS $$
+		BEGIN
+			IF new.nickname = 'bob' THEN
+				RAISE EXCEPTION 'refused' USING ERRCODE = 'check_violation';
+			END IF;
+			RETURN new;
+		END;
+		$$ LANGUAGE plpgsql;
+		CREATE TRIGGER refuse_bob BEFORE INSERT ON forum_users FOR EACH ROW EXECUTE PROCEDURE refuse_bob()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sd.pool.Exec(context.Background(), `DROP TRIGGER IF EXISTS refuse_bob ON forum_users; DROP FUNCTION IF EXISTS refuse_bob()`)
+	})
+
+	tests := []struct {
+		name   string
+		posts  []*models.Post
+		status int
+	}{
+		{
+			name: "missing parent",
+			posts: []*models.Post{
+				{Author: "alice", Message: "fine", Parent: root.Id},
+				{Author: "alice", Message: "orphan", Parent: root.Id + 1000},
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "missing author",
+			posts: []*models.Post{
+				{Author: "alice", Message: "fine"},
+				{Author: "nobody", Message: "ghost"},
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "failure partway",
+			posts: []*models.Post{
+				{Author: "alice", Message: "fine"},
+				{Author: "bob", Message: "refused"},
+			},
+			status: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := sd.AddPost(ctx, tt.posts, thread, time.Now()); status != tt.status {
+				t.Fatalf("AddPost = %d, want %d", status, tt.status)
+			}
+
+			posts, err := sd.GetPostsFlat(ctx, int(thread.Id), 100, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(posts) != 1 || posts[0].Id != root.Id {
+				t.Errorf("thread has %d posts after the failed batch, want only the root", len(posts))
+			}
+			forum, status := sd.GetForum(ctx, "forum")
+			if status != http.StatusOK {
+				t.Fatalf("GetForum = %d", status)
+			}
+			if forum.Posts != 1 {
+				t.Errorf("forum counts %d posts, want 1", forum.Posts)
+			}
+			after, _ := sd.GetThreadById(ctx, int(thread.Id))
+			if after.PostCount != 1 {
+				t.Errorf("thread counts %d posts, want 1", after.PostCount)
+			}
+			var notifications int
+			if err := sd.pool.QueryRow(ctx, `SELECT count(*) FROM notifications`).Scan(&notifications); err != nil {
+				t.Fatal(err)
+			}
+			if notifications != 0 {
+				t.Errorf("%d notifications were left behind", notifications)
+			}
+		})
+	}
+}
+
+func TestSomeDatabase(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) smth.Repository {
+		return testDatabase(t)
+	})
+}
@@ -0,0 +1,976 @@
+// Package repotest holds the scenarios every smth.Repository must pass, so
+// that the in-memory storage keeps behaving like PostgreSQL.
+package repotest
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	smth "subd"
+	"subd/models"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// Run runs every scenario against a repository open returns. open is
+// called once per scenario and must return an empty repository.
+func Run(t *testing.T, open func(t *testing.T) smth.Repository) {
+	scenarios := []struct {
+		name string
+		test func(t *testing.T, repo smth.Repository)
+	}{
+		{"PostParents", testPostParents},
+		{"PostAuthors", testPostAuthors},
+		{"Votes", testVotes},
+		{"PostPages", testPostPages},
+		{"ThreadPages", testThreadPages},
+		{"ThreadSorts", testThreadSorts},
+		{"ThreadFilters", testThreadFilters},
+		{"ForumUserPages", testForumUserPages},
+		{"Counters", testCounters},
+		{"SearchSnippets", testSearchSnippets},
+		{"SearchPages", testSearchPages},
+		{"UserDirectory", testUserDirectory},
+		{"Mentions", testMentions},
+		{"ReadMarkers", testReadMarkers},
+		{"Events", testEvents},
+	}
+	for _, scenario := range scenarios {
+		scenario := scenario
+		t.Run(scenario.name, func(t *testing.T) {
+			scenario.test(t, open(t))
+		})
+	}
+}
+
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fixture makes the users and a forum of the first of them holding a
+// thread, and returns the thread.
+func fixture(t *testing.T, repo smth.Repository, nicknames ...string) models.Thread {
+	t.Helper()
+	ctx := context.Background()
+
+	for _, nickname := range nicknames {
+		user := models.User{Nickname: nickname, Fullname: nickname, Email: strfmt.Email(nickname + "@example.com")}
+		if err := repo.CreateUser(ctx, nickname, user); err != nil {
+			t.Fatalf("CreateUser(%s): %v", nickname, err)
+		}
+	}
+	if err, _ := repo.AddNewForum(ctx, &models.Forum{Title: "Forum", Owner: nicknames[0], Slug: "forum"}); err != nil {
+		t.Fatalf("AddNewForum: %v", err)
+	}
+
+	return addThread(t, repo, nicknames[0], "thread", epoch)
+}
+
+func addThread(t *testing.T, repo smth.Repository, author string, title string, created time.Time) models.Thread {
+	t.Helper()
+	ctx := context.Background()
+
+	id, err := repo.AddNewThread(ctx, models.Thread{Author: author, Forum: "forum", Title: title,
+		Message: title, Created: strfmt.DateTime(created)})
+	if err != nil {
+		t.Fatalf("AddNewThread: %v", err)
+	}
+	thread, status := repo.GetThreadById(ctx, int(id))
+	if status != http.StatusOK {
+		t.Fatalf("GetThreadById = %d", status)
+	}
+
+	return thread
+}
+
+func addPosts(t *testing.T, repo smth.Repository, thread models.Thread, posts ...*models.Post) {
+	t.Helper()
+	if status := repo.AddPost(context.Background(), posts, thread, epoch); status != http.StatusCreated {
+		t.Fatalf("AddPost = %d, want %d", status, http.StatusCreated)
+	}
+}
+
+func postIds(posts models.Posts) []int {
+	ids := make([]int, len(posts))
+	for i := range posts {
+		ids[i] = posts[i].Id
+	}
+	return ids
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPostCount checks the post counters of the forum and the thread.
+func checkPostCount(t *testing.T, repo smth.Repository, thread models.Thread, want int) {
+	t.Helper()
+	ctx := context.Background()
+
+	forum, status := repo.GetForum(ctx, "forum")
+	if status != http.StatusOK {
+		t.Fatalf("GetForum = %d", status)
+	}
+	if forum.Posts != uint64(want) {
+		t.Errorf("forum counts %d posts, want %d", forum.Posts, want)
+	}
+	after, status := repo.GetThreadById(ctx, int(thread.Id))
+	if status != http.StatusOK {
+		t.Fatalf("GetThreadById = %d", status)
+	}
+	if after.PostCount != want {
+		t.Errorf("thread counts %d posts, want %d", after.PostCount, want)
+	}
+}
+
+func testPostParents(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	thread := fixture(t, repo, "alice")
+	other := addThread(t, repo, "alice", "other", epoch)
+
+	root := &models.Post{Author: "alice", Message: "root"}
+	elsewhere := &models.Post{Author: "alice", Message: "elsewhere"}
+	addPosts(t, repo, thread, root)
+	addPosts(t, repo, other, elsewhere)
+
+	tests := []struct {
+		name   string
+		parent int
+		status int
+	}{
+		{"missing", root.Id + 1000, http.StatusConflict},
+		{"in another thread", elsewhere.Id, http.StatusConflict},
+		{"in the thread", root.Id, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		posts := []*models.Post{
+			{Author: "alice", Message: "sibling", Parent: root.Id},
+			{Author: "alice", Message: tt.name, Parent: tt.parent},
+		}
+		if status := repo.AddPost(ctx, posts, thread, epoch); status != tt.status {
+			t.Errorf("%s parent: AddPost = %d, want %d", tt.name, status, tt.status)
+		}
+	}
+
+	flat, err := repo.GetPostsFlat(ctx, int(thread.Id), 100, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flat) != 3 {
+		t.Errorf("thread has %d posts, want the root and the last batch", len(flat))
+	}
+}
+
+func testPostAuthors(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	thread := fixture(t, repo, "alice")
+
+	posts := []*models.Post{
+		{Author: "alice", Message: "fine"},
+		{Author: "nobody", Message: "ghost"},
+	}
+	if status := repo.AddPost(ctx, posts, thread, epoch); status != http.StatusNotFound {
+		t.Errorf("AddPost = %d, want %d", status, http.StatusNotFound)
+	}
+	flat, err := repo.GetPostsFlat(ctx, int(thread.Id), 100, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flat) != 0 {
+		t.Errorf("thread has %d posts after the failed batch, want none", len(flat))
+	}
+	checkPostCount(t, repo, thread, 0)
+
+	// Authors are matched case-insensitively and join the forum.
+	addPosts(t, repo, thread, &models.Post{Author: "ALICE", Message: "shouting"})
+	users, err := repo.GetForumUsers(ctx, "forum", 10, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Nickname != "alice" {
+		t.Errorf("forum users = %v, want alice", users)
+	}
+}
+
+func testVotes(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	thread := fixture(t, repo, "alice", "bob")
+	id := int(thread.Id)
+
+	votes := func() int {
+		t.Helper()
+		thread, status := repo.GetThreadById(ctx, id)
+		if status != http.StatusOK {
+			t.Fatalf("GetThreadById = %d", status)
+		}
+		return thread.Votes
+	}
+
+	if exists, err := repo.CheckVote(ctx, id, "alice"); err != nil || exists {
+		t.Fatalf("CheckVote before voting = %v, %v", exists, err)
+	}
+	if err := repo.AddVote(ctx, id, models.Vote{Nickname: "alice", Voice: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AddVote(ctx, id, models.Vote{Nickname: "bob", Voice: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got := votes(); got != 2 {
+		t.Errorf("votes = %d after two upvotes, want 2", got)
+	}
+
+	// A second vote of the same user is refused; changing it is an update.
+	if err := repo.AddVote(ctx, id, models.Vote{Nickname: "ALICE", Voice: -1}); err == nil {
+		t.Error("AddVote accepted a second vote of the same user")
+	}
+	if exists, err := repo.CheckVote(ctx, id, "alice"); err != nil || !exists {
+		t.Fatalf("CheckVote after voting = %v, %v", exists, err)
+	}
+	if err := repo.UpdateVote(ctx, id, models.Vote{Nickname: "alice", Voice: -1}); err != nil {
+		t.Fatal(err)
+	}
+	if voice, err := repo.GetValueVote(ctx, id, "alice"); err != nil || voice != -1 {
+		t.Errorf("GetValueVote = %d, %v, want -1", voice, err)
+	}
+	if got := votes(); got != 0 {
+		t.Errorf("votes = %d after a changed vote, want 0", got)
+	}
+
+	if err := repo.AddVote(ctx, id, models.Vote{Nickname: "nobody", Voice: 1}); err == nil {
+		t.Error("AddVote accepted a vote of a missing user")
+	}
+	if err := repo.AddVote(ctx, id+1000, models.Vote{Nickname: "bob", Voice: 1}); err == nil {
+		t.Error("AddVote accepted a vote on a missing thread")
+	}
+	if got := votes(); got != 0 {
+		t.Errorf("votes = %d after refused votes, want 0", got)
+	}
+}
+
+func testPostPages(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	thread := fixture(t, repo, "alice")
+
+	// Clients set the creation time of their posts, so ids and times need
+	// not agree: the flat sort lists a, b and c, then d and e.
+	byName := map[string]models.Post{}
+	for _, p := range []struct {
+		name    string
+		created time.Duration
+	}{
+		{"d", 2 * time.Second},
+		{"a", 0},
+		{"b", 0},
+		{"e", 2 * time.Second},
+		{"c", time.Second},
+	} {
+		post := &models.Post{Author: "alice", Message: p.name}
+		if status := repo.AddPost(ctx, []*models.Post{post}, thread, epoch.Add(p.created)); status != http.StatusCreated {
+			t.Fatalf("AddPost = %d, want %d", status, http.StatusCreated)
+		}
+		byName[p.name] = *post
+	}
+	ids := func(names string) []int {
+		var ids []int
+		for _, name := range names {
+			ids = append(ids, byName[string(name)].Id)
+		}
+		return ids
+	}
+	key := func(name string) *models.PostKey {
+		key := byName[name].Key(models.PostsFlat, false)
+		return &key
+	}
+
+	tests := []struct {
+		name  string
+		get   func(ctx context.Context, id int, limit int, after *models.PostKey) (models.Posts, error)
+		limit int
+		after *models.PostKey
+		want  []int
+	}{
+		{"first page", repo.GetPostsFlat, 2, nil, ids("ab")},
+		{"next page", repo.GetPostsFlat, 2, key("b"), ids("cd")},
+		{"last page", repo.GetPostsFlat, 2, key("d"), ids("e")},
+		{"past the end", repo.GetPostsFlat, 2, key("e"), nil},
+		{"first page descending", repo.GetPostsFlatDesc, 2, nil, ids("ed")},
+		{"next page descending", repo.GetPostsFlatDesc, 2, key("d"), ids("cb")},
+		{"last page descending", repo.GetPostsFlatDesc, 2, key("b"), ids("a")},
+	}
+	for _, tt := range tests {
+		posts, err := tt.get(ctx, int(thread.Id), tt.limit, tt.after)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := postIds(posts); !equalInts(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testThreadPages(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	first := fixture(t, repo, "alice")
+	second := addThread(t, repo, "alice", "second", epoch.Add(time.Hour))
+	third := addThread(t, repo, "alice", "third", epoch.Add(2*time.Hour))
+
+	since := epoch.Add(time.Hour).Format(time.RFC3339Nano)
+	tests := []struct {
+		name  string
+		query models.ThreadQuery
+		want  []uint64
+	}{
+		{"first page", models.ThreadQuery{Forum: "forum", Limit: 2}, []uint64{first.Id, second.Id}},
+		{"since", models.ThreadQuery{Forum: "forum", Limit: 2, Since: since}, []uint64{second.Id, third.Id}},
+		{"descending", models.ThreadQuery{Forum: "forum", Limit: 2, Desc: true}, []uint64{third.Id, second.Id}},
+		{"since descending", models.ThreadQuery{Forum: "forum", Limit: 2, Since: since, Desc: true},
+			[]uint64{second.Id, first.Id}},
+		{"after a key", models.ThreadQuery{Forum: "forum", Limit: 2,
+			After: keyOf(second, models.ThreadsByCreated)}, []uint64{third.Id}},
+	}
+	for _, tt := range tests {
+		threads, err := repo.GetForumThreads(ctx, tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []uint64
+		for _, thread := range threads {
+			got = append(got, thread.Id)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func keyOf(thread models.Thread, order string) *models.ThreadKey {
+	key := thread.Key(order)
+	return &key
+}
+
+// threadTitles lists the titles of the threads query finds.
+func threadTitles(t *testing.T, repo smth.Repository, query models.ThreadQuery) []string {
+	t.Helper()
+	threads, err := repo.GetForumThreads(context.Background(), query)
+	if err != nil {
+		t.Fatalf("GetForumThreads: %v", err)
+	}
+	titles := make([]string, len(threads))
+	for i, thread := range threads {
+		titles[i] = thread.Title
+	}
+	return titles
+}
+
+// testThreadSorts lists the threads of a forum in every order, whole and
+// a thread at a time, pinned threads first.
+func testThreadSorts(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	thread := fixture(t, repo, "alice", "bob")
+	a := addThread(t, repo, "alice", "a", epoch.Add(time.Hour))
+	b := addThread(t, repo, "bob", "b", epoch.Add(2*time.Hour))
+	c := addThread(t, repo, "alice", "c", epoch.Add(3*time.Hour))
+
+	// a and c are pinned. Votes: a 2, b -1, c and thread 0. Posts: a 0,
+	// c and thread 1, b 2. Activity: a 1h, c 4h, b 5h, thread 6h.
+	pinned := true
+	for _, id := range []uint64{a.Id, c.Id} {
+		if status := repo.ModerateThread(ctx, int(id), models.ThreadModeration{Pinned: &pinned}); status != http.StatusOK {
+			t.Fatalf("ModerateThread = %d", status)
+		}
+	}
+	votes := []struct {
+		thread uint64
+		vote   models.Vote
+	}{
+		{a.Id, models.Vote{Nickname: "alice", Voice: 1}},
+		{a.Id, models.Vote{Nickname: "bob", Voice: 1}},
+		{b.Id, models.Vote{Nickname: "alice", Voice: -1}},
+	}
+	for _, v := range votes {
+		if err := repo.AddVote(ctx, int(v.thread), v.vote); err != nil {
+			t.Fatal(err)
+		}
+	}
+	posts := []struct {
+		thread models.Thread
+		count  int
+		at     time.Duration
+	}{
+		{c, 1, 4 * time.Hour},
+		{b, 2, 5 * time.Hour},
+		{thread, 1, 6 * time.Hour},
+	}
+	for _, p := range posts {
+		batch := make([]*models.Post, p.count)
+		for i := range batch {
+			batch[i] = &models.Post{Author: "bob", Message: "reply"}
+		}
+		if status := repo.AddPost(ctx, batch, p.thread, epoch.Add(p.at)); status != http.StatusCreated {
+			t.Fatalf("AddPost = %d", status)
+		}
+	}
+
+	tests := []struct {
+		sort string
+		desc bool
+		want string
+	}{
+		{models.ThreadsByCreated, false, "a c thread b"},
+		{models.ThreadsByCreated, true, "c a b thread"},
+		{models.ThreadsByVotes, false, "c a b thread"},
+		{models.ThreadsByVotes, true, "a c thread b"},
+		{models.ThreadsByPosts, false, "a c thread b"},
+		{models.ThreadsByPosts, true, "c a b thread"},
+		{models.ThreadsByActivity, false, "a c b thread"},
+		{models.ThreadsByActivity, true, "c a thread b"},
+	}
+	for _, tt := range tests {
+		name := tt.sort
+		if tt.desc {
+			name += " descending"
+		}
+		t.Run(name, func(t *testing.T) {
+			query := models.ThreadQuery{Forum: "forum", Sort: tt.sort, Desc: tt.desc, Limit: 10}
+			if got := strings.Join(threadTitles(t, repo, query), " "); got != tt.want {
+				t.Errorf("threads = %s, want %s", got, tt.want)
+			}
+
+			query.Limit = 1
+			var paged []string
+			for i := 0; i < 5; i++ {
+				threads, err := repo.GetForumThreads(ctx, query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(threads) == 0 {
+					break
+				}
+				paged = append(paged, threads[0].Title)
+				query.After = keyOf(threads[0], tt.sort)
+			}
+			if got := strings.Join(paged, " "); got != tt.want {
+				t.Errorf("pages = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// testThreadFilters narrows the threads of a forum by author, title and
+// creation time; LIKE wildcards in the title are taken literally.
+func testThreadFilters(t *testing.T, repo smth.Repository) {
+	fixture(t, repo, "alice", "bob")
+	threads := []struct {
+		author string
+		title  string
+	}{
+		{"alice", "50% off"},
+		{"bob", "50 percent off"},
+		{"bob", "snake_case"},
+		{"alice", "snakeXcase"},
+		{"alice", `back\slash`},
+	}
+	for i, thread := range threads {
+		addThread(t, repo, thread.author, thread.title, epoch.Add(time.Duration(i+1)*time.Hour))
+	}
+	at := func(hours int) *time.Time {
+		at := epoch.Add(time.Duration(hours) * time.Hour)
+		return &at
+	}
+
+	tests := []struct {
+		name  string
+		query models.ThreadQuery
+		want  []string
+	}{
+		{"author", models.ThreadQuery{Author: "bob"}, []string{"50 percent off", "snake_case"}},
+		{"author in another case", models.ThreadQuery{Author: "BOB"}, []string{"50 percent off", "snake_case"}},
+		{"title in another case", models.ThreadQuery{Title: "SNAKE"}, []string{"snake_case", "snakeXcase"}},
+		{"percent sign", models.ThreadQuery{Title: "%"}, []string{"50% off"}},
+		{"underscore", models.ThreadQuery{Title: "e_c"}, []string{"snake_case"}},
+		{"backslash", models.ThreadQuery{Title: `\`}, []string{`back\slash`}},
+		{"author and title", models.ThreadQuery{Author: "alice", Title: "off"}, []string{"50% off"}},
+		{"from and to", models.ThreadQuery{From: at(1), To: at(3)}, []string{"50% off", "50 percent off", "snake_case"}},
+		{"from", models.ThreadQuery{From: at(4)}, []string{"snakeXcase", `back\slash`}},
+		{"to", models.ThreadQuery{To: at(0)}, []string{"thread"}},
+		{"nothing", models.ThreadQuery{Author: "bob", From: at(4)}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Forum, tt.query.Limit = "forum", 10
+			got := threadTitles(t, repo, tt.query)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("threads = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func testForumUserPages(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	thread := fixture(t, repo, "alice", "bob", "carol", "dave")
+	for _, nickname := range []string{"dave", "bob", "carol", "alice"} {
+		addPosts(t, repo, thread, &models.Post{Author: nickname, Message: "hello"})
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		since string
+		desc  bool
+		want  []string
+	}{
+		{"first page", 2, "", false, []string{"alice", "bob"}},
+		{"next page", 2, "bob", false, []string{"carol", "dave"}},
+		{"descending", 3, "", true, []string{"dave", "carol", "bob"}},
+		{"next page descending", 3, "bob", true, []string{"alice"}},
+	}
+	for _, tt := range tests {
+		users, err := repo.GetForumUsers(ctx, "forum", tt.limit, tt.since, tt.desc, false)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, user := range users {
+			got = append(got, user.Nickname)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func testCounters(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	thread := fixture(t, repo, "alice", "bob")
+
+	// Adding a thread counts it and makes its author a user of the forum.
+	_, threads, err := repo.GetForumCounts(ctx, "forum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := repo.GetForumUsers(ctx, "forum", 10, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if threads != 1 || len(users) != 1 || users[0].Nickname != "alice" {
+		t.Errorf("forum counts %d threads and %d users, want 1 and alice", threads, len(users))
+	}
+
+	first := &models.Post{Author: "alice", Message: "first"}
+	addPosts(t, repo, thread, first, &models.Post{Author: "bob", Message: "second"})
+	checkPostCount(t, repo, thread, 2)
+
+	if status := repo.DeletePost(ctx, first.Id); status != http.StatusOK {
+		t.Fatalf("DeletePost = %d", status)
+	}
+	if status := repo.DeletePost(ctx, first.Id); status != http.StatusOK {
+		t.Fatalf("DeletePost again = %d", status)
+	}
+	checkPostCount(t, repo, thread, 1)
+	if status := repo.RestorePost(ctx, first.Id); status != http.StatusOK {
+		t.Fatalf("RestorePost = %d", status)
+	}
+	checkPostCount(t, repo, thread, 2)
+
+	other := addThread(t, repo, "bob", "other", epoch)
+	addPosts(t, repo, other, &models.Post{Author: "bob", Message: "elsewhere"})
+	posts, threads, err := repo.GetForumCounts(ctx, "forum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posts != 3 || threads != 2 {
+		t.Errorf("forum counts %d posts and %d threads, want 3 and 2", posts, threads)
+	}
+
+	if status := repo.DeleteThread(ctx, int(other.Id)); status != http.StatusOK {
+		t.Fatalf("DeleteThread = %d", status)
+	}
+	posts, threads, err = repo.GetForumCounts(ctx, "forum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posts != 2 || threads != 1 {
+		t.Errorf("forum counts %d posts and %d threads after deleting a thread, want 2 and 1", posts, threads)
+	}
+
+	status, err := repo.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Forum != 1 || status.Thread != 1 || status.Post != 2 || status.User != 2 {
+		t.Errorf("Status = %+v", status)
+	}
+}
+
+func testSearchSnippets(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	thread := fixture(t, repo, "alice")
+	addPosts(t, repo, thread, &models.Post{Author: "alice",
+		Message: `<script>alert("x")</script> hello & 'goodbye' <img src=x onerror=alert(1)>`})
+
+	hits, err := repo.Search(ctx, models.SearchQuery{Text: "hello", Kind: models.HitPost, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("found %d hits, want 1", len(hits))
+	}
+	snippet := hits[0].Snippet
+	if strings.Contains(snippet, "<script") || strings.Contains(snippet, "<img") {
+		t.Errorf("snippet %q carries the markup of the post", snippet)
+	}
+	for _, want := range []string{"&lt;script&gt;", "<mark>hello</mark>", "&amp;", "&lt;img"} {
+		if !strings.Contains(snippet, want) {
+			t.Errorf("snippet %q lacks %q", snippet, want)
+		}
+	}
+}
+
+// testSearchPages filters the hits of a search and pages through them a
+// hit at a time, ties in rank included.
+func testSearchPages(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	thread := fixture(t, repo, "alice", "bob")
+	if err, _ := repo.AddNewForum(ctx, &models.Forum{Title: "Other", Owner: "bob", Slug: "other"}); err != nil {
+		t.Fatalf("AddNewForum: %v", err)
+	}
+	id, err := repo.AddNewThread(ctx, models.Thread{Author: "bob", Forum: "other", Title: "apple orchard",
+		Message: "trees", Created: strfmt.DateTime(epoch.Add(3 * time.Hour))})
+	if err != nil {
+		t.Fatalf("AddNewThread: %v", err)
+	}
+	orchard, status := repo.GetThreadById(ctx, int(id))
+	if status != http.StatusOK {
+		t.Fatalf("GetThreadById = %d", status)
+	}
+
+	labels := map[string]string{models.HitThread + ":" + strconv.Itoa(int(id)): "orchard"}
+	posts := []struct {
+		label  string
+		thread models.Thread
+		post   models.Post
+		at     time.Duration
+	}{
+		{"pie", thread, models.Post{Author: "alice", Message: "apple pie"}, time.Hour},
+		{"tart", thread, models.Post{Author: "bob", Message: "apple tart"}, 2 * time.Hour},
+		{"banana", thread, models.Post{Author: "alice", Message: "banana"}, 2 * time.Hour},
+		{"plain", thread, models.Post{Author: "bob", Message: "apple"}, 3 * time.Hour},
+		{"jam", orchard, models.Post{Author: "alice", Message: "apple jam"}, 4 * time.Hour},
+	}
+	for _, p := range posts {
+		post := p.post
+		if status := repo.AddPost(ctx, []*models.Post{&post}, p.thread, epoch.Add(p.at)); status != http.StatusCreated {
+			t.Fatalf("AddPost = %d", status)
+		}
+		labels[models.HitPost+":"+strconv.Itoa(post.Id)] = p.label
+	}
+	label := func(hit models.SearchHit) string {
+		return labels[hit.Kind+":"+strconv.FormatInt(hit.Id, 10)]
+	}
+	at := func(hours int) *time.Time {
+		at := epoch.Add(time.Duration(hours) * time.Hour)
+		return &at
+	}
+
+	tests := []struct {
+		name  string
+		query models.SearchQuery
+		want  string
+	}{
+		{"everything", models.SearchQuery{}, "jam orchard pie plain tart"},
+		{"posts", models.SearchQuery{Kind: models.HitPost}, "jam pie plain tart"},
+		{"threads", models.SearchQuery{Kind: models.HitThread}, "orchard"},
+		{"forum", models.SearchQuery{Forum: "other"}, "jam orchard"},
+		{"author", models.SearchQuery{Author: "BOB"}, "orchard plain tart"},
+		{"thread", models.SearchQuery{Thread: int(thread.Id)}, "pie plain tart"},
+		{"from and to", models.SearchQuery{From: at(2), To: at(4)}, "orchard plain tart"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			query.Text, query.Limit = "apple", 10
+			hits, err := repo.Search(ctx, query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var all []string
+			for _, hit := range hits {
+				all = append(all, label(hit))
+			}
+			found := append([]string(nil), all...)
+			sort.Strings(found)
+			if got := strings.Join(found, " "); got != tt.want {
+				t.Errorf("found %s, want %s", got, tt.want)
+			}
+
+			query.Limit = 1
+			var paged []string
+			for i := 0; i <= len(all); i++ {
+				hits, err := repo.Search(ctx, query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(hits) == 0 {
+					break
+				}
+				paged = append(paged, label(hits[0]))
+				key := hits[0].Key()
+				query.After = &key
+			}
+			if strings.Join(paged, " ") != strings.Join(all, " ") {
+				t.Errorf("pages = %v, want %v", paged, all)
+			}
+		})
+	}
+}
+
+// testUserDirectory finds users by nickname, word of the full name and
+// similarity, and pages through them a user at a time.
+func testUserDirectory(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	for _, user := range []models.User{
+		{Nickname: "alice", Fullname: "Alice Liddell"},
+		{Nickname: "alicia", Fullname: "Alicia Keys"},
+		{Nickname: "bob", Fullname: "Bob Alistair"},
+		{Nickname: "carol", Fullname: "Carol Danvers"},
+		{Nickname: "dave", Fullname: "Dave"},
+	} {
+		user.Email = strfmt.Email(user.Nickname + "@example.com")
+		if err := repo.CreateUser(ctx, user.Nickname, user); err != nil {
+			t.Fatalf("CreateUser(%s): %v", user.Nickname, err)
+		}
+	}
+	if err, _ := repo.AddNewForum(ctx, &models.Forum{Title: "Forum", Owner: "carol", Slug: "forum"}); err != nil {
+		t.Fatalf("AddNewForum: %v", err)
+	}
+	thread := addThread(t, repo, "carol", "thread", epoch)
+	addPosts(t, repo, thread, &models.Post{Author: "alice", Message: "hello"})
+
+	tests := []struct {
+		name  string
+		query models.UserQuery
+		want  string
+	}{
+		{"everyone", models.UserQuery{Sort: models.UsersByNickname}, "alice alicia bob carol dave"},
+		{"everyone descending", models.UserQuery{Sort: models.UsersByNickname, Desc: true}, "dave carol bob alicia alice"},
+		{"prefixes", models.UserQuery{Text: "ALI", Sort: models.UsersByRelevance}, "alice alicia bob"},
+		{"similar", models.UserQuery{Text: "alice", Sort: models.UsersByRelevance}, "alice alicia"},
+		{"prefixes by nickname", models.UserQuery{Text: "ali", Sort: models.UsersByNickname, Desc: true},
+			"bob alicia alice"},
+		{"forum", models.UserQuery{Forum: "forum", Sort: models.UsersByNickname}, "alice carol"},
+		{"forum and text", models.UserQuery{Text: "ali", Forum: "forum", Sort: models.UsersByRelevance}, "alice"},
+		{"nobody", models.UserQuery{Text: "zzz", Sort: models.UsersByRelevance}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			query.Limit = 10
+			matches, err := repo.FindUsers(ctx, query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var all []string
+			for _, m := range matches {
+				all = append(all, m.Nickname)
+			}
+			if got := strings.Join(all, " "); got != tt.want {
+				t.Errorf("found %s, want %s", got, tt.want)
+			}
+
+			query.Limit = 1
+			var paged []string
+			for i := 0; i <= len(all); i++ {
+				matches, err := repo.FindUsers(ctx, query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(matches) == 0 {
+					break
+				}
+				paged = append(paged, matches[0].Nickname)
+				key := matches[0].Key()
+				query.After = &key
+			}
+			if got := strings.Join(paged, " "); got != tt.want {
+				t.Errorf("pages = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// testMentions checks who the posts of a batch notify: the author of the
+// parent by a reply and the users they mention, the author never and
+// nobody twice.
+func testMentions(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	thread := fixture(t, repo, "alice", "bob", "carol", "dave")
+	root := &models.Post{Author: "alice", Message: "root"}
+	addPosts(t, repo, thread, root)
+	reply := &models.Post{Author: "bob", Parent: root.Id, Message: "@alice @Carol @bob @nobody thanks"}
+	mention := &models.Post{Author: "dave", Message: "hey @bob, mail dave@example.com or @bob."}
+	addPosts(t, repo, thread, reply, mention)
+
+	tests := []struct {
+		nickname string
+		want     string
+	}{
+		{"alice", "reply from bob on " + strconv.Itoa(reply.Id)},
+		{"bob", "mention from dave on " + strconv.Itoa(mention.Id)},
+		{"carol", "mention from bob on " + strconv.Itoa(reply.Id)},
+		{"dave", ""},
+	}
+	for _, tt := range tests {
+		notifications, err := repo.GetNotifications(ctx, models.NotificationQuery{Nickname: tt.nickname, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, n := range notifications {
+			got = append(got, n.Type+" from "+n.Actor+" on "+strconv.Itoa(n.Post))
+		}
+		if strings.Join(got, "; ") != tt.want {
+			t.Errorf("%s is notified of %q, want %q", tt.nickname, got, tt.want)
+		}
+	}
+
+	counts, err := repo.CountUnreadNotifications(ctx, "CAROL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 1 || counts[models.NotifyMention] != 1 {
+		t.Errorf("carol has unread notifications %v, want a mention", counts)
+	}
+}
+
+// testReadMarkers moves the read marker of a user in a thread, which only
+// goes forward and reads the notifications about the posts it passes.
+func testReadMarkers(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	thread := fixture(t, repo, "alice", "bob")
+	id := int(thread.Id)
+	posts := []*models.Post{
+		{Author: "bob", Message: "one"},
+		{Author: "bob", Message: "two"},
+		{Author: "bob", Message: "three, @alice"},
+		{Author: "bob", Message: "four"},
+	}
+	for _, post := range posts {
+		addPosts(t, repo, thread, post)
+	}
+	if lastRead, err := repo.GetLastRead(ctx, id, "alice"); err != nil || lastRead != 0 {
+		t.Fatalf("GetLastRead before reading = %d, %v", lastRead, err)
+	}
+
+	tests := []struct {
+		name     string
+		post     int
+		lastRead int
+		unread   int
+		mentions int
+	}{
+		{"up to a post", posts[1].Id, posts[1].Id, 2, 1},
+		{"never back", posts[0].Id, posts[1].Id, 2, 1},
+		{"up to the latest", 0, posts[3].Id, 0, 0},
+	}
+	for _, tt := range tests {
+		read, err := repo.MarkThreadRead(ctx, id, "alice", tt.post)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if read.LastRead != tt.lastRead || read.Unread != tt.unread {
+			t.Errorf("%s: read up to %d with %d unread, want %d with %d",
+				tt.name, read.LastRead, read.Unread, tt.lastRead, tt.unread)
+		}
+		counts, err := repo.CountUnreadNotifications(ctx, "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if counts[models.NotifyMention] != tt.mentions {
+			t.Errorf("%s: %d unread mentions, want %d", tt.name, counts[models.NotifyMention], tt.mentions)
+		}
+	}
+	if lastRead, err := repo.GetLastRead(ctx, id, "ALICE"); err != nil || lastRead != posts[3].Id {
+		t.Errorf("GetLastRead = %d, %v, want %d", lastRead, err, posts[3].Id)
+	}
+}
+
+// testEvents checks that the changes clients follow add their events, and
+// that a change which fails adds none.
+func testEvents(t *testing.T, repo smth.Repository) {
+	ctx := context.Background()
+	thread := fixture(t, repo, "alice", "bob")
+	id := int(thread.Id)
+
+	root := &models.Post{Author: "alice", Message: "root"}
+	addPosts(t, repo, thread, root)
+	orphan := []*models.Post{{Author: "alice", Message: "orphan", Parent: root.Id + 1000}}
+	if status := repo.AddPost(ctx, orphan, thread, epoch); status != http.StatusConflict {
+		t.Fatalf("AddPost = %d, want %d", status, http.StatusConflict)
+	}
+	if err := repo.EditMessage(ctx, root.Id, "edited", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.UpdateThreadById(ctx, id, models.Thread{Title: "renamed", Message: "m"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AddVote(ctx, id, models.Vote{Nickname: "bob", Voice: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AddVote(ctx, id, models.Vote{Nickname: "nobody", Voice: 1}); err == nil {
+		t.Error("AddVote accepted a vote of a missing user")
+	}
+	if err := repo.UpdateVote(ctx, id, models.Vote{Nickname: "bob", Voice: -1}); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := repo.GetEvents(ctx, models.EventQuery{Thread: id, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{models.EventPostsCreated, models.EventPostEdited, models.EventThreadUpdated,
+		models.EventThreadVoted, models.EventThreadVoted}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i := range want {
+		if events[i].Type != want[i] || events[i].Forum != "forum" || events[i].Thread != id {
+			t.Errorf("event %d is %s of %s/%d, want %s of forum/%d",
+				i, events[i].Type, events[i].Forum, events[i].Thread, want[i], id)
+		}
+		if i > 0 && events[i].Id <= events[i-1].Id {
+			t.Errorf("event %d has id %d after %d", i, events[i].Id, events[i-1].Id)
+		}
+	}
+	if !strings.Contains(string(events[4].Data), `"votes":-1`) {
+		t.Errorf("last vote event carries %s, want the thread at -1 votes", events[4].Data)
+	}
+	if last, err := repo.LastEventId(ctx); err != nil || last != events[4].Id {
+		t.Errorf("LastEventId = %d, %v, want %d", last, err, events[4].Id)
+	}
+}
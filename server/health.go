@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"subd/migrations"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/echo"
+)
+
+const readinessCheckTimeout = 2 * time.Second
+
+type healthStatus struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// health serves the liveness and readiness probes. Readiness fails while the
+// server drains, when the database does not answer or when its schema is
+// not at the version this build expects.
+type health struct {
+	pool *pgxpool.Pool
+	// latest is the schema version this build expects, worked out once
+	// rather than on every probe.
+	latest   int
+	draining int32
+}
+
+func (h *health) register(e *echo.Echo) {
+	e.GET("/health/live", h.live)
+	e.GET("/health/ready", h.ready)
+}
+
+func (h *health) startDraining() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+func (h *health) live(c echo.Context) error {
+	return c.JSON(http.StatusOK, healthStatus{Status: "ok"})
+}
+
+func (h *health) ready(c echo.Context) error {
+	checks := make(map[string]string)
+	ok := true
+
+	if atomic.LoadInt32(&h.draining) == 1 {
+		checks["server"] = "shutting down"
+		ok = false
+	}
+
+	if h.pool != nil {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), readinessCheckTimeout)
+		defer cancel()
+
+		if err := h.pool.Ping(ctx); err != nil {
+			checks["database"] = err.Error()
+			ok = false
+		} else {
+			checks["database"] = "ok"
+
+			version, err := migrations.Version(ctx, h.pool)
+			switch {
+			case err != nil:
+				checks["schema"] = err.Error()
+				ok = false
+			case version != h.latest:
+				checks["schema"] = fmt.Sprintf("version %d, expected %d", version, h.latest)
+				ok = false
+			default:
+				checks["schema"] = "ok"
+			}
+		}
+	}
+
+	if !ok {
+		return c.JSON(http.StatusServiceUnavailable, healthStatus{Status: "unavailable", Checks: checks})
+	}
+	return c.JSON(http.StatusOK, healthStatus{Status: "ok", Checks: checks})
+}
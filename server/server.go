@@ -2,29 +2,73 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
-	"subd/constants"
+	smth "subd"
+	"subd/config"
 	"subd/delivery/http"
+	"subd/live"
+	"subd/metrics"
+	"subd/migrations"
 	"subd/repository"
+	"subd/repository/memory"
 	"subd/usecase"
+	"subd/webhook"
 
 	_ "github.com/jackc/pgx/stdlib"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/labstack/echo"
+	gommonlog "github.com/labstack/gommon/log"
 	_ "github.com/lib/pq"
 )
 
 type Server struct {
 	e       *echo.Echo
+	cfg     *config.Config
+	pool    *pgxpool.Pool
+	health  *health
+	hub     *live.Hub
+	hooks   *webhook.Dispatcher
 }
 
-func NewServer() *Server {
-	var server Server
+var logLevels = map[string]gommonlog.Lvl{
+	"debug": gommonlog.DEBUG,
+	"info":  gommonlog.INFO,
+	"warn":  gommonlog.WARN,
+	"error": gommonlog.ERROR,
+	"off":   gommonlog.OFF,
+}
 
-	e := echo.New()
+// requestTimeout bounds every request context but those of streams, so
+// that repository queries started by a handler are cancelled once the
+// deadline passes or the client goes away.
+func requestTimeout(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if http.Streaming(c) {
+				return next(c)
+			}
+			ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+			defer cancel()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
 
-	pool, err := pgxpool.Connect(context.Background(), constants.DBConnect)
+func connect(cfg *config.Config) *pgxpool.Pool {
+	poolConfig, err := cfg.PoolConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	pool, err := pgxpool.ConnectConfig(context.Background(), poolConfig)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -33,16 +77,182 @@ func NewServer() *Server {
 		log.Fatal(err)
 	}
 
-	newRepository := repository.NewSomeDatabase(pool)
+	return pool
+}
+
+func newMigrator(cfg *config.Config, pool *pgxpool.Pool) *migrations.Migrator {
+	migrator, err := migrations.NewMigrator(pool, migrations.Options{Unlogged: cfg.Unlogged})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return migrator
+}
+
+// newRepository also returns the pool backing the repository, nil for the
+// in-memory storage.
+func newRepository(cfg *config.Config) (smth.Repository, *pgxpool.Pool) {
+	if cfg.Storage == config.StorageMemory {
+		return memory.NewMemoryDatabase(), nil
+	}
+
+	pool := connect(cfg)
+
+	if cfg.Features.AutoMigrate {
+		if err := newMigrator(cfg, pool).Up(context.Background()); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	return repository.NewSomeDatabase(pool), pool
+}
+
+// Migrate runs the migrate subcommand: "up" (the default), "down [steps]"
+// or "status".
+func Migrate(cfg *config.Config, args []string) error {
+	pool := connect(cfg)
+	defer pool.Close()
+
+	migrator := newMigrator(cfg, pool)
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	switch command {
+	case "up":
+		return migrator.Up(context.Background())
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		return migrator.Down(context.Background(), steps)
+	case "status":
+		states, err := migrator.Status(context.Background())
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			applied := "pending"
+			if state.Applied {
+				applied = "applied " + state.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s\t%s\n", state.Version, state.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+}
+
+func NewServer(cfg *config.Config) *Server {
+	var server Server
+
+	e := echo.New()
+	e.Logger.SetLevel(logLevels[cfg.LogLevel])
+	e.HTTPErrorHandler = http.ErrorHandler
+
+	newRepository, pool := newRepository(cfg)
+
+	if cfg.Features.Metrics {
+		m := metrics.New()
+		e.Use(m.Middleware())
+		if pool != nil {
+			m.RegisterPool(pool)
+		}
+		m.RegisterStatus(newRepository)
+		e.GET("/metrics", m.Handler())
+
+		newRepository = m.InstrumentRepository(newRepository)
+	}
+	e.Use(requestTimeout(cfg.Timeouts.Request))
+
+	newUC := usecase.NewSmth(newRepository, usecase.Options{
+		RequireAuth: cfg.Auth.Required,
+		SessionTTL:  cfg.Auth.SessionTTL,
+	})
 
-	newUC := usecase.NewSmth(newRepository)
+	server.hub = live.NewHub(newRepository, cfg.Events.Retention)
+	server.hooks = webhook.NewDispatcher(newRepository, webhook.Options{
+		MaxAttempts:  cfg.Webhooks.MaxAttempts,
+		Backoff:      cfg.Webhooks.Backoff,
+		Timeout:      cfg.Webhooks.Timeout,
+		Retention:    cfg.Webhooks.Retention,
+		AllowPrivate: cfg.Webhooks.AllowPrivate,
+	})
+	http.CreateSmthHandler(e, newUC, server.hub, cfg)
 
-	http.CreateSmthHandler(e, newUC)
+	server.health = &health{pool: pool, latest: migrations.Latest()}
+	server.health.register(e)
 
 	server.e = e
+	server.cfg = cfg
+	server.pool = pool
 	return &server
 }
 
+// ListenAndServe serves until SIGINT or SIGTERM, then ends the event
+// streams, keeps serving with a failing readiness probe for the drain delay,
+// stops accepting connections, waits up to the shutdown timeout for
+// in-flight requests, stops the webhook dispatcher and closes the database
+// pool.
 func (s Server) ListenAndServe() {
-	s.e.Logger.Fatal(s.e.Start(":5000"))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	hubCtx, stopHub := context.WithCancel(context.Background())
+	defer stopHub()
+	hubDone := make(chan struct{})
+	go func() {
+		s.hub.Run(hubCtx)
+		close(hubDone)
+	}()
+	hooksCtx, stopHooks := context.WithCancel(context.Background())
+	defer stopHooks()
+	hooksDone := make(chan struct{})
+	go func() {
+		s.hooks.Run(hooksCtx)
+		close(hooksDone)
+	}()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.e.Start(s.cfg.Listen)
+	}()
+
+	select {
+	case err := <-errs:
+		s.close()
+		s.e.Logger.Fatal(err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	s.e.Logger.Info("shutting down")
+	s.health.startDraining()
+	stopHub()
+	<-hubDone
+	// Keep serving while load balancers notice the failing readiness probe
+	// and stop sending new requests.
+	time.Sleep(s.cfg.Timeouts.Drain)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeouts.Shutdown)
+	defer cancel()
+	if err := s.e.Shutdown(shutdownCtx); err != nil {
+		s.e.Logger.Error(err)
+	}
+	stopHooks()
+	<-hooksDone
+	s.close()
+}
+
+func (s Server) close() {
+	if s.pool != nil {
+		s.pool.Close()
+	}
 }
@@ -1,30 +1,79 @@
 package event
 
 import (
+	"context"
 	"subd/models"
 )
 
 //go:generate mockgen -destination=./mock/usecase_mock.go -package=mock -source=./application/event/usecase.go
 
 type UseCase interface {
-	CreateNewForum(newForum *models.Forum) (models.Forum, int)
-	CreateNewThread(newThread *models.Thread) (models.Thread, int)
-	GetForum(slug string) (models.Forum, int)
-	GetForumUsers(slug string, limit int, since string, desc bool) (models.Users, int)
-	GetThreads(slug string, limit int, since string, desc bool) (models.Threads, int)
-	GetPost(id int, related string) (models.FullPost, int)
-	EditMessage(id int, message string) (models.Post, int)
-	Clear() error
-	Status() (models.Status, error)
-	CreateUser(nickname string, user models.User) (models.Users, int)
-	GetUser(nickname string) (models.User, int)
-	UpdateUser(nickname string, user models.User) (models.User, int)
-	CreateNewPosts(newPosts []*models.Post, slugOrId string)  int
-	GetThread(slugOrId string) (models.Thread, int)
-	UpdateThread(slugOrId string, newThread models.Thread) (models.Thread, int)
-	Vote(slugOrId string, vote models.Vote) (models.Thread, int)
-	GetThreadSortFlat(slugOrId string, limit int, since int, desc bool) (models.Posts, int)
-	GetThreadSortTree(slugOrId string, limit int, since int, desc bool) (models.Posts, int)
-	GetThreadSortParentTree(slugOrId string, limit int, since int, desc bool) (models.Posts, int)
-	EditMessageNull(id int) (models.PostNullMessage, int)
+	CreateNewForum(ctx context.Context, newForum *models.Forum) (models.Forum, error)
+	CreateNewThread(ctx context.Context, newThread *models.Thread) (models.Thread, error)
+	GetForum(ctx context.Context, slug string) (models.Forum, error)
+	GetForumUsers(ctx context.Context, slug string, limit int, since string, desc bool, excludeBanned bool, after string) (models.UsersPage, error)
+	GetThreads(ctx context.Context, query models.ThreadQuery, after string) (models.ThreadsPage, error)
+	GetPost(ctx context.Context, id int, related string) (models.FullPost, error)
+	EditMessage(ctx context.Context, id int, message string, editor string) (models.Post, error)
+	Revisions(ctx context.Context, id int) (models.Revisions, error)
+	DiffRevisions(ctx context.Context, id int, from int, to int) (models.RevisionDiff, error)
+	DeletePost(ctx context.Context, id int) error
+	RestorePost(ctx context.Context, id int) error
+	Clear(ctx context.Context) error
+	Status(ctx context.Context) (models.Status, error)
+	CreateUser(ctx context.Context, nickname string, user models.User, password string) (models.Users, error)
+	GetUser(ctx context.Context, nickname string) (models.User, error)
+	UpdateUser(ctx context.Context, nickname string, user models.User) (models.User, error)
+	CreateNewPosts(ctx context.Context, newPosts []*models.Post, slugOrId string) error
+	GetThread(ctx context.Context, slugOrId string) (models.Thread, error)
+	UpdateThread(ctx context.Context, slugOrId string, newThread models.Thread) (models.Thread, error)
+	DeleteThread(ctx context.Context, slugOrId string) error
+	ArchiveThread(ctx context.Context, slugOrId string, archived bool) (models.Thread, error)
+	ModerateThread(ctx context.Context, slugOrId string, m models.ThreadModeration) (models.Thread, error)
+	Vote(ctx context.Context, slugOrId string, vote models.Vote) (models.Thread, error)
+	GetThreadSortFlat(ctx context.Context, slugOrId string, limit int, since int, desc bool, after string, unreadFor string) (models.PostsPage, error)
+	GetThreadSortTree(ctx context.Context, slugOrId string, limit int, since int, desc bool, after string) (models.PostsPage, error)
+	GetThreadSortParentTree(ctx context.Context, slugOrId string, limit int, since int, desc bool, after string) (models.PostsPage, error)
+	EditMessageNull(ctx context.Context, id int) (models.PostNullMessage, error)
+	Login(ctx context.Context, login models.Login) (models.Session, error)
+	Logout(ctx context.Context, token string) error
+	Authenticate(ctx context.Context, token string) (models.Caller, error)
+	SetRole(ctx context.Context, nickname string, role string) error
+	Moderators(ctx context.Context, slug string) (models.Users, error)
+	AppointModerator(ctx context.Context, slug string, nickname string) error
+	RevokeModerator(ctx context.Context, slug string, nickname string) error
+	Ban(ctx context.Context, ban models.Ban) (models.Ban, error)
+	Unban(ctx context.Context, forum string, nickname string) error
+	ForumBans(ctx context.Context, slug string) (models.Bans, error)
+	Search(ctx context.Context, query models.SearchQuery, after string) (models.SearchResult, error)
+	FindUsers(ctx context.Context, query models.UserQuery, after string) (models.UsersPage, error)
+	ThreadEvents(ctx context.Context, slugOrId string) (models.EventQuery, error)
+	ForumEvents(ctx context.Context, slug string) (models.EventQuery, error)
+	Events(ctx context.Context, query models.EventQuery) (models.Events, error)
+	CreateWebhook(ctx context.Context, hook models.Webhook) (models.Webhook, error)
+	Webhooks(ctx context.Context, forum string) (models.Webhooks, error)
+	Webhook(ctx context.Context, id int) (models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	Deliveries(ctx context.Context, id int, state string, limit int) (models.Deliveries, error)
+	Redeliver(ctx context.Context, id int, delivery int64) (models.Delivery, error)
+	SubscribeForum(ctx context.Context, nickname string, slug string) error
+	UnsubscribeForum(ctx context.Context, nickname string, slug string) error
+	Notifications(ctx context.Context, query models.NotificationQuery, after string) (models.NotificationsPage, error)
+	UnreadNotifications(ctx context.Context, nickname string) (models.UnreadNotifications, error)
+	MarkNotificationRead(ctx context.Context, nickname string, id int64) error
+	MarkNotificationsRead(ctx context.Context, nickname string, upTo int64) (models.NotificationsMarked, error)
+	SubscribeThread(ctx context.Context, nickname string, slugOrId string) error
+	UnsubscribeThread(ctx context.Context, nickname string, slugOrId string) error
+	Subscriptions(ctx context.Context, nickname string) (models.Subscriptions, error)
+	MarkThreadRead(ctx context.Context, slugOrId string, marker models.ReadMarker) (models.ThreadRead, error)
+	CreateConversation(ctx context.Context, nickname string, nc models.NewConversation) (models.Conversation, error)
+	Conversations(ctx context.Context, nickname string, limit int, after string) (models.ConversationsPage, error)
+	Conversation(ctx context.Context, nickname string, id int) (models.Conversation, error)
+	SendMessage(ctx context.Context, nickname string, id int, message models.PrivateMessage) (models.PrivateMessage, error)
+	Messages(ctx context.Context, nickname string, id int, limit int, since int64, desc bool, after string) (models.MessagesPage, error)
+	EditPrivateMessage(ctx context.Context, nickname string, id int, messageId int64, text string) (models.PrivateMessage, error)
+	MarkConversationRead(ctx context.Context, nickname string, id int, upTo int64) (models.ConversationRead, error)
+	Block(ctx context.Context, nickname string, blocked string) error
+	Unblock(ctx context.Context, nickname string, blocked string) error
+	Blocks(ctx context.Context, nickname string) (models.Blocks, error)
 }
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"subd/auth"
+	"subd/domain"
+	"subd/models"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// anonymous answers a request without a caller: it goes through unless
+// authentication is required.
+func (s Smth) anonymous(action string) error {
+	if s.opts.RequireAuth {
+		return domain.ErrUnauthenticated.With("Sign in to " + action)
+	}
+	return nil
+}
+
+func banned(caller models.Caller) error {
+	return domain.ErrUserBanned.With(caller.Nickname+" is banned", "nickname", caller.Nickname)
+}
+
+// authorize checks that the caller may act as nickname: admins act as
+// anyone, other callers only as themselves.
+func (s Smth) authorize(ctx context.Context, nickname string) error {
+	caller, ok := auth.CallerFrom(ctx)
+	if !ok {
+		return s.anonymous("act as " + nickname)
+	}
+	if caller.IsBanned() {
+		return banned(caller)
+	}
+	if caller.IsAdmin() || strings.EqualFold(caller.Nickname, nickname) {
+		return nil
+	}
+
+	return domain.ErrForbidden.With("Signed in as "+caller.Nickname+", can't act as "+nickname,
+		"nickname", nickname)
+}
+
+// authorizeIn checks that the caller may change what nickname wrote in
+// forum: besides the author, the forum's owner and moderators may. Anyone
+// could claim to be the author, so anonymous requests are refused whatever
+// the settings.
+func (s Smth) authorizeIn(ctx context.Context, forum string, nickname string) error {
+	caller, ok := auth.CallerFrom(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated.With("Sign in to change what " + nickname + " wrote")
+	}
+	if caller.IsBanned() {
+		return banned(caller)
+	}
+	if caller.IsAdmin() || strings.EqualFold(caller.Nickname, nickname) {
+		return nil
+	}
+
+	return s.moderates(ctx, caller, forum)
+}
+
+// self checks that the caller is nickname or an admin. What is private to
+// a user is never shown to anonymous requests, whatever the settings.
+func (s Smth) self(ctx context.Context, nickname string) error {
+	caller, ok := auth.CallerFrom(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated.With("Sign in as " + nickname)
+	}
+	if caller.IsAdmin() || strings.EqualFold(caller.Nickname, nickname) {
+		return nil
+	}
+
+	return domain.ErrForbidden.With("Signed in as "+caller.Nickname+", can't act as "+nickname,
+		"nickname", nickname)
+}
+
+// moderator checks that the caller moderates forum. Moderation was never
+// open to anonymous requests, so they are refused whatever the settings.
+func (s Smth) moderator(ctx context.Context, forum string) error {
+	caller, ok := auth.CallerFrom(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated.With("Sign in to moderate forum " + forum)
+	}
+	if caller.IsBanned() {
+		return banned(caller)
+	}
+
+	return s.moderates(ctx, caller, forum)
+}
+
+// moderates lets admins and the owner and moderators of forum through.
+func (s Smth) moderates(ctx context.Context, caller models.Caller, forum string) error {
+	if caller.IsAdmin() {
+		return nil
+	}
+	f, status := s.repo.GetForum(ctx, forum)
+	if err := statusError(status, forumNotFound(forum)); err != nil {
+		return err
+	}
+	if strings.EqualFold(f.Owner, caller.Nickname) {
+		return nil
+	}
+	isModerator, err := s.repo.IsModerator(ctx, f.Slug, caller.Nickname)
+	if err != nil {
+		return domain.Internal(err)
+	}
+	if isModerator {
+		return nil
+	}
+
+	return domain.ErrForbidden.With(caller.Nickname+" does not moderate forum "+f.Slug, "forum", f.Slug)
+}
+
+// owner checks that the caller owns forum.
+func (s Smth) owner(ctx context.Context, forum models.Forum) error {
+	caller, ok := auth.CallerFrom(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated.With("Sign in to manage forum " + forum.Slug)
+	}
+	if caller.IsAdmin() || !caller.IsBanned() && strings.EqualFold(caller.Nickname, forum.Owner) {
+		return nil
+	}
+
+	return domain.ErrForbidden.With("Only the owner manages forum "+forum.Slug, "forum", forum.Slug)
+}
+
+// admin checks that the caller is an admin; anonymous requests are
+// refused whatever the settings.
+func (s Smth) admin(ctx context.Context, action string) error {
+	caller, ok := auth.CallerFrom(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated.With("Sign in to " + action)
+	}
+	if caller.IsAdmin() {
+		return nil
+	}
+
+	return domain.ErrForbidden.With("Only admins may " + action)
+}
+
+// Login checks a password and opens a session.
+func (s Smth) Login(ctx context.Context, login models.Login) (models.Session, error) {
+	credentials, status := s.repo.GetCredentials(ctx, login.Nickname)
+	if status != http.StatusOK && status != http.StatusNotFound {
+		return models.Session{}, statusError(status, nil)
+	}
+	if !auth.CheckPassword(credentials.PasswordHash, login.Password) {
+		return models.Session{}, domain.ErrBadCredentials.With("Wrong nickname or password")
+	}
+
+	token, err := auth.NewToken()
+	if err != nil {
+		return models.Session{}, domain.Internal(err)
+	}
+	expires := time.Now().Add(s.opts.SessionTTL)
+	err = s.repo.AddSession(ctx, auth.TokenHash(token), credentials.Nickname, expires)
+	if err != nil {
+		return models.Session{}, domain.Internal(err)
+	}
+
+	return models.Session{Token: token, Nickname: credentials.Nickname, Expires: strfmt.DateTime(expires)}, nil
+}
+
+func (s Smth) Logout(ctx context.Context, token string) error {
+	err := s.repo.DeleteSession(ctx, auth.TokenHash(token))
+	if err != nil {
+		return domain.Internal(err)
+	}
+
+	return nil
+}
+
+// Authenticate resolves a bearer token to its caller.
+func (s Smth) Authenticate(ctx context.Context, token string) (models.Caller, error) {
+	caller, status := s.repo.GetSession(ctx, auth.TokenHash(token))
+	if err := statusError(status, domain.ErrUnauthenticated.With("Session is invalid or expired")); err != nil {
+		return models.Caller{}, err
+	}
+
+	return caller, nil
+}
+
+// SetRole changes the site role of an account.
+func (s Smth) SetRole(ctx context.Context, nickname string, role string) error {
+	if err := s.admin(ctx, "set roles"); err != nil {
+		return err
+	}
+
+	status := s.repo.SetRole(ctx, nickname, role)
+	return statusError(status, domain.ErrUserNotFound.With("User "+nickname+" has no account", "nickname", nickname))
+}
+
+func (s Smth) Moderators(ctx context.Context, slug string) (models.Users, error) {
+	forum, status := s.repo.GetForum(ctx, slug)
+	if err := statusError(status, forumNotFound(slug)); err != nil {
+		return models.Users{}, err
+	}
+
+	users, err := s.repo.GetModerators(ctx, forum.Slug)
+	if err != nil {
+		return models.Users{}, domain.Internal(err)
+	}
+
+	return users, nil
+}
+
+// AppointModerator lets nickname moderate a forum; only its owner and
+// admins may appoint moderators.
+func (s Smth) AppointModerator(ctx context.Context, slug string, nickname string) error {
+	forum, status := s.repo.GetForum(ctx, slug)
+	if err := statusError(status, forumNotFound(slug)); err != nil {
+		return err
+	}
+	if err := s.owner(ctx, forum); err != nil {
+		return err
+	}
+	user, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return err
+	}
+
+	if err := s.repo.AddModerator(ctx, forum.Slug, user.Nickname); err != nil {
+		return domain.Internal(err)
+	}
+
+	return nil
+}
+
+func (s Smth) RevokeModerator(ctx context.Context, slug string, nickname string) error {
+	forum, status := s.repo.GetForum(ctx, slug)
+	if err := statusError(status, forumNotFound(slug)); err != nil {
+		return err
+	}
+	if err := s.owner(ctx, forum); err != nil {
+		return err
+	}
+
+	status = s.repo.RemoveModerator(ctx, forum.Slug, nickname)
+	return statusError(status, domain.ErrModeratorNotFound.With(nickname+" does not moderate forum "+forum.Slug,
+		"nickname", nickname, "forum", forum.Slug))
+}
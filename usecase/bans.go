@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"subd/auth"
+	"subd/domain"
+	"subd/models"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// checkBans refuses the request if any of nicknames is banned in forum.
+func (s Smth) checkBans(ctx context.Context, forum string, nicknames ...string) error {
+	bans, err := s.repo.FindBans(ctx, forum, nicknames)
+	if err != nil {
+		return domain.Internal(err)
+	}
+	if len(bans) == 0 {
+		return nil
+	}
+
+	ban := bans[0]
+	message := ban.Nickname + " is banned"
+	if ban.Forum != "" {
+		message += " from forum " + ban.Forum
+	}
+	details := []interface{}{"nickname", ban.Nickname}
+	if ban.Forum != "" {
+		details = append(details, "forum", ban.Forum)
+	}
+	if ban.Reason != "" {
+		details = append(details, "reason", ban.Reason)
+	}
+	if ban.Expires != nil {
+		details = append(details, "expires", ban.Expires.String())
+	}
+
+	return domain.ErrUserBanned.With(message, details...)
+}
+
+// Ban bans a user from a forum, or everywhere when ban.Forum is empty, and
+// replaces an earlier ban of the same scope. Forum bans are up to the
+// forum's moderators; site-wide ones are administrative requests.
+func (s Smth) Ban(ctx context.Context, ban models.Ban) (models.Ban, error) {
+	user, status := s.repo.GetUser(ctx, ban.Nickname)
+	if err := statusError(status, userNotFound(ban.Nickname)); err != nil {
+		return models.Ban{}, err
+	}
+	ban.Nickname = user.Nickname
+
+	if ban.Forum != "" {
+		forum, status := s.repo.GetForum(ctx, ban.Forum)
+		if err := statusError(status, forumNotFound(ban.Forum)); err != nil {
+			return models.Ban{}, err
+		}
+		ban.Forum = forum.Slug
+		if err := s.moderator(ctx, ban.Forum); err != nil {
+			return models.Ban{}, err
+		}
+	} else if err := s.admin(ctx, "ban users site-wide"); err != nil {
+		return models.Ban{}, err
+	}
+
+	now := time.Now()
+	if ban.Expires != nil && !time.Time(*ban.Expires).After(now) {
+		return models.Ban{}, domain.ErrValidation.With("Request does not pass validation",
+			"fields", models.ValidationError{{Field: "expires", Rule: "future", Message: "must be in the future"}})
+	}
+	ban.Created = strfmt.DateTime(now)
+	ban.BannedBy = ""
+	if caller, ok := auth.CallerFrom(ctx); ok {
+		ban.BannedBy = caller.Nickname
+	}
+
+	if err := s.repo.AddBan(ctx, ban); err != nil {
+		return models.Ban{}, domain.Internal(err)
+	}
+
+	return ban, nil
+}
+
+// Unban lifts the ban of nickname in forum, or the site-wide one when
+// forum is empty.
+func (s Smth) Unban(ctx context.Context, forum string, nickname string) error {
+	if forum != "" {
+		f, status := s.repo.GetForum(ctx, forum)
+		if err := statusError(status, forumNotFound(forum)); err != nil {
+			return err
+		}
+		forum = f.Slug
+		if err := s.moderator(ctx, forum); err != nil {
+			return err
+		}
+	} else if err := s.admin(ctx, "lift site-wide bans"); err != nil {
+		return err
+	}
+
+	status := s.repo.RemoveBan(ctx, forum, nickname)
+	return statusError(status, domain.ErrBanNotFound.With(nickname+" is not banned", "nickname", nickname))
+}
+
+// ForumBans lists the bans in force in a forum, site-wide ones included.
+func (s Smth) ForumBans(ctx context.Context, slug string) (models.Bans, error) {
+	forum, status := s.repo.GetForum(ctx, slug)
+	if err := statusError(status, forumNotFound(slug)); err != nil {
+		return models.Bans{}, err
+	}
+
+	bans, err := s.repo.GetForumBans(ctx, forum.Slug)
+	if err != nil {
+		return models.Bans{}, domain.Internal(err)
+	}
+
+	return bans, nil
+}
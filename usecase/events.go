@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+
+	"subd/domain"
+	"subd/models"
+)
+
+// ThreadEvents selects the events of a thread.
+func (s Smth) ThreadEvents(ctx context.Context, slugOrId string) (models.EventQuery, error) {
+	thread, err := s.thread(ctx, slugOrId)
+	if err != nil {
+		return models.EventQuery{}, err
+	}
+
+	return models.EventQuery{Thread: int(thread.Id)}, nil
+}
+
+// ForumEvents selects the events of every thread in a forum.
+func (s Smth) ForumEvents(ctx context.Context, slug string) (models.EventQuery, error) {
+	forum, status := s.repo.GetForum(ctx, slug)
+	if err := statusError(status, forumNotFound(slug)); err != nil {
+		return models.EventQuery{}, err
+	}
+
+	return models.EventQuery{Forum: forum.Slug}, nil
+}
+
+// Events replays the events a client missed, as far back as the log goes.
+func (s Smth) Events(ctx context.Context, query models.EventQuery) (models.Events, error) {
+	events, err := s.repo.GetEvents(ctx, query)
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,342 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"subd/auth"
+	"subd/cursor"
+	"subd/domain"
+	"subd/models"
+)
+
+func conversationNotFound(id int) error {
+	return domain.ErrConversationNotFound.With("Can't find conversation "+fmt.Sprint(id), "id", id)
+}
+
+func messageNotFound(conversation int, id int64) error {
+	return domain.ErrMessageNotFound.With(
+		"Can't find message "+fmt.Sprint(id)+" in conversation "+fmt.Sprint(conversation),
+		"conversation", conversation, "id", id)
+}
+
+// correspondent checks that the caller writes private messages as
+// nickname: they are private, so anonymous requests are refused whatever
+// the settings, and so are banned users.
+func (s Smth) correspondent(ctx context.Context, nickname string) error {
+	if err := s.self(ctx, nickname); err != nil {
+		return err
+	}
+	if caller, _ := auth.CallerFrom(ctx); caller.IsBanned() {
+		return banned(caller)
+	}
+	return nil
+}
+
+// conversation returns a conversation as nickname sees it. Those who do not
+// take part in it are told it does not exist.
+func (s Smth) conversation(ctx context.Context, id int, nickname string) (models.Conversation, error) {
+	conversation, status := s.repo.GetConversation(ctx, id, nickname)
+	if err := statusError(status, conversationNotFound(id)); err != nil {
+		return models.Conversation{}, err
+	}
+	return conversation, nil
+}
+
+// participant returns the nickname of a participant as stored.
+func participant(conversation models.Conversation, nickname string) string {
+	for _, p := range conversation.Participants {
+		if strings.EqualFold(p.Nickname, nickname) {
+			return p.Nickname
+		}
+	}
+	return nickname
+}
+
+// blocking refuses private messages from nickname to others when one of
+// them blocked the other.
+func (s Smth) blocking(ctx context.Context, nickname string, others []string) error {
+	blocking, err := s.repo.Blocking(ctx, nickname, others)
+	if err != nil {
+		return domain.Internal(err)
+	}
+	if len(blocking) > 0 {
+		return domain.ErrUserBlocked.With(nickname+" can't write to "+strings.Join(blocking, ", "),
+			"nickname", nickname, "blocked", blocking)
+	}
+	return nil
+}
+
+// CreateConversation starts a conversation of nickname with the users
+// given, one-to-one or as a small group, with its first message.
+func (s Smth) CreateConversation(ctx context.Context, nickname string, nc models.NewConversation) (models.Conversation, error) {
+	if err := s.correspondent(ctx, nickname); err != nil {
+		return models.Conversation{}, err
+	}
+	creator, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return models.Conversation{}, err
+	}
+
+	var others []string
+	seen := map[string]bool{strings.ToLower(creator.Nickname): true}
+	for _, name := range nc.Participants {
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		user, status := s.repo.GetUser(ctx, name)
+		if err := statusError(status, userNotFound(name)); err != nil {
+			return models.Conversation{}, err
+		}
+		others = append(others, user.Nickname)
+	}
+	if len(others) == 0 || len(others)+1 > models.MaxParticipants {
+		return models.Conversation{}, domain.ErrValidation.With("Request does not pass validation",
+			"fields", models.ValidationError{{Field: "participants", Rule: "participants",
+				Message: fmt.Sprintf("must name 1 to %d other users", models.MaxParticipants-1)}})
+	}
+	if err := s.blocking(ctx, creator.Nickname, others); err != nil {
+		return models.Conversation{}, err
+	}
+
+	conversation := models.Conversation{Title: nc.Title, Creator: creator.Nickname}
+	for _, name := range append([]string{creator.Nickname}, others...) {
+		conversation.Participants = append(conversation.Participants, models.Participant{Nickname: name})
+	}
+	message := models.PrivateMessage{Message: nc.Message}
+	if err := s.repo.AddConversation(ctx, &conversation, &message); err != nil {
+		return models.Conversation{}, domain.Internal(err)
+	}
+
+	return conversation, nil
+}
+
+// Conversations pages through the conversations of a user, the one with
+// the latest message first; after is the cursor of the previous page.
+func (s Smth) Conversations(ctx context.Context, nickname string, limit int, after string) (models.ConversationsPage, error) {
+	if err := s.self(ctx, nickname); err != nil {
+		return models.ConversationsPage{}, err
+	}
+	user, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return models.ConversationsPage{}, err
+	}
+	query := models.ConversationQuery{Nickname: user.Nickname, Limit: limit + 1}
+	if after != "" {
+		var key models.ConversationKey
+		if err := decodeCursor(after, &key); err != nil {
+			return models.ConversationsPage{}, err
+		}
+		query.After = key.Last
+	}
+
+	conversations, err := s.repo.GetConversations(ctx, query)
+	if err != nil {
+		return models.ConversationsPage{}, domain.Internal(err)
+	}
+
+	page := models.ConversationsPage{Conversations: conversations}
+	if len(conversations) > limit {
+		page.Conversations = conversations[:limit]
+		next, err := cursor.Encode(models.ConversationKey{Last: conversations[limit-1].LastMessage})
+		if err != nil {
+			return models.ConversationsPage{}, domain.Internal(err)
+		}
+		page.Next = next
+	}
+
+	return page, nil
+}
+
+func (s Smth) Conversation(ctx context.Context, nickname string, id int) (models.Conversation, error) {
+	if err := s.self(ctx, nickname); err != nil {
+		return models.Conversation{}, err
+	}
+
+	return s.conversation(ctx, id, nickname)
+}
+
+// SendMessage adds a message of nickname to a conversation. Nobody writes
+// to a conversation shared with a user who blocked them, or whom they
+// blocked, be it one-to-one or a group: blocks made after the conversation
+// started count as much as those CreateConversation checks.
+func (s Smth) SendMessage(ctx context.Context, nickname string, id int, message models.PrivateMessage) (models.PrivateMessage, error) {
+	if err := s.correspondent(ctx, nickname); err != nil {
+		return models.PrivateMessage{}, err
+	}
+	conversation, err := s.conversation(ctx, id, nickname)
+	if err != nil {
+		return models.PrivateMessage{}, err
+	}
+	author := participant(conversation, nickname)
+	var others []string
+	for _, p := range conversation.Participants {
+		if p.Nickname != author {
+			others = append(others, p.Nickname)
+		}
+	}
+	if err := s.blocking(ctx, author, others); err != nil {
+		return models.PrivateMessage{}, err
+	}
+
+	message = models.PrivateMessage{Conversation: id, Author: author, Message: message.Message}
+	if err := s.repo.AddPrivateMessage(ctx, &message); err != nil {
+		return models.PrivateMessage{}, domain.Internal(err)
+	}
+
+	return message, nil
+}
+
+// Messages lists the messages of a conversation the way the flat sort lists
+// the posts of a thread, leaving out those of users nickname blocked.
+func (s Smth) Messages(ctx context.Context, nickname string, id int, limit int, since int64, desc bool, after string) (models.MessagesPage, error) {
+	if err := s.self(ctx, nickname); err != nil {
+		return models.MessagesPage{}, err
+	}
+	conversation, err := s.conversation(ctx, id, nickname)
+	if err != nil {
+		return models.MessagesPage{}, err
+	}
+	if after != "" {
+		var key models.MessageKey
+		if err := decodeCursor(after, &key); err != nil {
+			return models.MessagesPage{}, err
+		}
+		if err := checkDirection(after, key.Desc, desc); err != nil {
+			return models.MessagesPage{}, err
+		}
+		since = key.Id
+	}
+
+	messages, err := s.repo.GetPrivateMessages(ctx, models.MessageQuery{
+		Conversation: id,
+		Reader:       participant(conversation, nickname),
+		Limit:        limit + 1,
+		Since:        since,
+		Desc:         desc,
+	})
+	if err != nil {
+		return models.MessagesPage{}, domain.Internal(err)
+	}
+
+	page := models.MessagesPage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		next, err := cursor.Encode(models.MessageKey{Desc: desc, Id: messages[limit-1].Id})
+		if err != nil {
+			return models.MessagesPage{}, domain.Internal(err)
+		}
+		page.Next = next
+	}
+
+	return page, nil
+}
+
+// EditPrivateMessage changes the text of a message; only its author does.
+// An edit keeping the text as it was leaves the message untouched.
+func (s Smth) EditPrivateMessage(ctx context.Context, nickname string, id int, messageId int64, text string) (models.PrivateMessage, error) {
+	if err := s.correspondent(ctx, nickname); err != nil {
+		return models.PrivateMessage{}, err
+	}
+	if _, err := s.conversation(ctx, id, nickname); err != nil {
+		return models.PrivateMessage{}, err
+	}
+	message, status := s.repo.GetPrivateMessage(ctx, id, messageId)
+	if err := statusError(status, messageNotFound(id, messageId)); err != nil {
+		return models.PrivateMessage{}, err
+	}
+	if !strings.EqualFold(message.Author, nickname) {
+		return models.PrivateMessage{}, domain.ErrForbidden.With("Only "+message.Author+" edits their message",
+			"nickname", nickname)
+	}
+	if message.Message == text {
+		return message, nil
+	}
+
+	message, status = s.repo.EditPrivateMessage(ctx, id, messageId, text)
+	if err := statusError(status, messageNotFound(id, messageId)); err != nil {
+		return models.PrivateMessage{}, err
+	}
+
+	return message, nil
+}
+
+// MarkConversationRead moves the read marker of nickname in a conversation
+// to a message of it, or to the latest one for 0. The marker never moves
+// back.
+func (s Smth) MarkConversationRead(ctx context.Context, nickname string, id int, upTo int64) (models.ConversationRead, error) {
+	if err := s.self(ctx, nickname); err != nil {
+		return models.ConversationRead{}, err
+	}
+	conversation, err := s.conversation(ctx, id, nickname)
+	if err != nil {
+		return models.ConversationRead{}, err
+	}
+	if upTo != 0 {
+		_, status := s.repo.GetPrivateMessage(ctx, id, upTo)
+		if err := statusError(status, messageNotFound(id, upTo)); err != nil {
+			return models.ConversationRead{}, err
+		}
+	}
+
+	read, err := s.repo.MarkConversationRead(ctx, id, participant(conversation, nickname), upTo)
+	if err != nil {
+		return models.ConversationRead{}, domain.Internal(err)
+	}
+
+	return read, nil
+}
+
+// Block keeps blocked from writing to nickname; see models.Block.
+func (s Smth) Block(ctx context.Context, nickname string, blocked string) error {
+	if err := s.self(ctx, nickname); err != nil {
+		return err
+	}
+	user, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return err
+	}
+	other, status := s.repo.GetUser(ctx, blocked)
+	if err := statusError(status, userNotFound(blocked)); err != nil {
+		return err
+	}
+	if user.Nickname == other.Nickname {
+		return domain.ErrInvalid.With("Users can't block themselves", "nickname", nickname)
+	}
+
+	if err := s.repo.Block(ctx, user.Nickname, other.Nickname); err != nil {
+		return domain.Internal(err)
+	}
+
+	return nil
+}
+
+func (s Smth) Unblock(ctx context.Context, nickname string, blocked string) error {
+	if err := s.self(ctx, nickname); err != nil {
+		return err
+	}
+
+	status := s.repo.Unblock(ctx, nickname, blocked)
+	return statusError(status, domain.ErrBlockNotFound.With(nickname+" did not block "+blocked,
+		"nickname", nickname, "blocked", blocked))
+}
+
+// Blocks lists the users nickname blocked; like messages, they are private.
+func (s Smth) Blocks(ctx context.Context, nickname string) (models.Blocks, error) {
+	if err := s.self(ctx, nickname); err != nil {
+		return nil, err
+	}
+	user, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return nil, err
+	}
+
+	blocks, err := s.repo.GetBlocks(ctx, user.Nickname)
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"subd/cursor"
+	"subd/domain"
+	"subd/models"
+)
+
+// Notifications pages through the notifications of a user, newest first;
+// after is the cursor of the previous page, empty for the first one.
+func (s Smth) Notifications(ctx context.Context, query models.NotificationQuery, after string) (models.NotificationsPage, error) {
+	if err := s.self(ctx, query.Nickname); err != nil {
+		return models.NotificationsPage{}, err
+	}
+	user, status := s.repo.GetUser(ctx, query.Nickname)
+	if err := statusError(status, userNotFound(query.Nickname)); err != nil {
+		return models.NotificationsPage{}, err
+	}
+	query.Nickname = user.Nickname
+	if after != "" {
+		var key models.NotificationKey
+		if err := decodeCursor(after, &key); err != nil {
+			return models.NotificationsPage{}, err
+		}
+		query.After = key.Id
+	}
+
+	limit := query.Limit
+	query.Limit++
+	notifications, err := s.repo.GetNotifications(ctx, query)
+	if err != nil {
+		return models.NotificationsPage{}, domain.Internal(err)
+	}
+	counts, err := s.repo.CountUnreadNotifications(ctx, user.Nickname)
+	if err != nil {
+		return models.NotificationsPage{}, domain.Internal(err)
+	}
+
+	page := models.NotificationsPage{Notifications: notifications}
+	for _, count := range counts {
+		page.Unread += count
+	}
+	if len(notifications) > limit {
+		page.Notifications = notifications[:limit]
+		next, err := cursor.Encode(models.NotificationKey{Id: notifications[limit-1].Id})
+		if err != nil {
+			return models.NotificationsPage{}, domain.Internal(err)
+		}
+		page.Next = next
+	}
+
+	return page, nil
+}
+
+// UnreadNotifications counts the unread notifications of a user. Every
+// type is listed, with 0 when there are none.
+func (s Smth) UnreadNotifications(ctx context.Context, nickname string) (models.UnreadNotifications, error) {
+	if err := s.self(ctx, nickname); err != nil {
+		return models.UnreadNotifications{}, err
+	}
+	user, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return models.UnreadNotifications{}, err
+	}
+
+	counts, err := s.repo.CountUnreadNotifications(ctx, user.Nickname)
+	if err != nil {
+		return models.UnreadNotifications{}, domain.Internal(err)
+	}
+
+	unread := models.UnreadNotifications{ByType: map[string]int{
+		models.NotifyReply:   counts[models.NotifyReply],
+		models.NotifyMention: counts[models.NotifyMention],
+		models.NotifyThread:  counts[models.NotifyThread],
+	}}
+	for _, count := range counts {
+		unread.Unread += count
+	}
+
+	return unread, nil
+}
+
+func (s Smth) MarkNotificationRead(ctx context.Context, nickname string, id int64) error {
+	if err := s.self(ctx, nickname); err != nil {
+		return err
+	}
+
+	status := s.repo.MarkNotificationRead(ctx, nickname, id)
+	return statusError(status, domain.ErrNotificationNotFound.With(
+		"Can't find notification "+fmt.Sprint(id)+" of "+nickname, "nickname", nickname, "id", id))
+}
+
+// MarkNotificationsRead marks the notifications of a user as read, up to
+// the id of the latest one the client has seen or all of them for 0.
+func (s Smth) MarkNotificationsRead(ctx context.Context, nickname string, upTo int64) (models.NotificationsMarked, error) {
+	if err := s.self(ctx, nickname); err != nil {
+		return models.NotificationsMarked{}, err
+	}
+	user, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return models.NotificationsMarked{}, err
+	}
+
+	marked, err := s.repo.MarkNotificationsRead(ctx, user.Nickname, upTo)
+	if err != nil {
+		return models.NotificationsMarked{}, domain.Internal(err)
+	}
+
+	return models.NotificationsMarked{Marked: marked}, nil
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+
+	"subd/cursor"
+	"subd/domain"
+	"subd/models"
+)
+
+func decodeCursor(after string, key interface{}) error {
+	if err := cursor.Decode(after, key); err != nil {
+		return domain.ErrInvalid.With("Malformed cursor", "cursor", after)
+	}
+	return nil
+}
+
+// checkDirection refuses a cursor made by a listing in the other direction,
+// which would page back over what the client has seen.
+func checkDirection(after string, keyDesc bool, desc bool) error {
+	if keyDesc != desc {
+		return domain.ErrInvalid.With("Cursor belongs to a listing in the other direction", "cursor", after)
+	}
+	return nil
+}
+
+// Search pages through the posts and threads matching a query; after is
+// the cursor of the previous page, empty for the first one.
+func (s Smth) Search(ctx context.Context, query models.SearchQuery, after string) (models.SearchResult, error) {
+	if after != "" {
+		query.After = &models.SearchKey{}
+		if err := decodeCursor(after, query.After); err != nil {
+			return models.SearchResult{}, err
+		}
+	}
+
+	limit := query.Limit
+	query.Limit++
+	hits, err := s.repo.Search(ctx, query)
+	if err != nil {
+		return models.SearchResult{}, domain.Internal(err)
+	}
+
+	result := models.SearchResult{Hits: hits}
+	if len(hits) > limit {
+		result.Hits = hits[:limit]
+		next, err := cursor.Encode(hits[limit-1].Key())
+		if err != nil {
+			return models.SearchResult{}, domain.Internal(err)
+		}
+		result.Next = next
+	}
+
+	return result, nil
+}
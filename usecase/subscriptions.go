@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"subd/domain"
+	"subd/models"
+)
+
+// SubscribeForum notifies nickname of the new threads of a forum.
+func (s Smth) SubscribeForum(ctx context.Context, nickname string, slug string) error {
+	if err := s.authorize(ctx, nickname); err != nil {
+		return err
+	}
+	user, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return err
+	}
+	forum, status := s.repo.GetForum(ctx, slug)
+	if err := statusError(status, forumNotFound(slug)); err != nil {
+		return err
+	}
+
+	if err := s.repo.SubscribeForum(ctx, forum.Slug, user.Nickname); err != nil {
+		return domain.Internal(err)
+	}
+
+	return nil
+}
+
+func (s Smth) UnsubscribeForum(ctx context.Context, nickname string, slug string) error {
+	if err := s.authorize(ctx, nickname); err != nil {
+		return err
+	}
+	forum, status := s.repo.GetForum(ctx, slug)
+	if err := statusError(status, forumNotFound(slug)); err != nil {
+		return err
+	}
+
+	status = s.repo.UnsubscribeForum(ctx, forum.Slug, nickname)
+	return statusError(status, domain.ErrSubscriptionNotFound.With(nickname+" is not subscribed to forum "+forum.Slug,
+		"nickname", nickname, "forum", forum.Slug))
+}
+
+// SubscribeThread adds a thread to those nickname follows.
+func (s Smth) SubscribeThread(ctx context.Context, nickname string, slugOrId string) error {
+	if err := s.authorize(ctx, nickname); err != nil {
+		return err
+	}
+	user, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return err
+	}
+	thread, err := s.thread(ctx, slugOrId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SubscribeThread(ctx, int(thread.Id), user.Nickname); err != nil {
+		return domain.Internal(err)
+	}
+
+	return nil
+}
+
+func (s Smth) UnsubscribeThread(ctx context.Context, nickname string, slugOrId string) error {
+	if err := s.authorize(ctx, nickname); err != nil {
+		return err
+	}
+	thread, err := s.thread(ctx, slugOrId)
+	if err != nil {
+		return err
+	}
+
+	status := s.repo.UnsubscribeThread(ctx, int(thread.Id), nickname)
+	return statusError(status, domain.ErrSubscriptionNotFound.With(
+		nickname+" is not subscribed to thread "+fmt.Sprint(thread.Id),
+		"nickname", nickname, "thread", thread.Id))
+}
+
+// Subscriptions lists the forums and threads a user follows with what is
+// unread in them. Like notifications, they are private to the user.
+func (s Smth) Subscriptions(ctx context.Context, nickname string) (models.Subscriptions, error) {
+	if err := s.self(ctx, nickname); err != nil {
+		return models.Subscriptions{}, err
+	}
+	user, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return models.Subscriptions{}, err
+	}
+
+	subscriptions, err := s.repo.GetSubscriptions(ctx, user.Nickname)
+	if err != nil {
+		return models.Subscriptions{}, domain.Internal(err)
+	}
+
+	return subscriptions, nil
+}
+
+// MarkThreadRead records that a user read a thread up to a post of it, or
+// up to its latest post. The marker never moves back.
+func (s Smth) MarkThreadRead(ctx context.Context, slugOrId string, marker models.ReadMarker) (models.ThreadRead, error) {
+	if err := s.authorize(ctx, marker.Nickname); err != nil {
+		return models.ThreadRead{}, err
+	}
+	user, status := s.repo.GetUser(ctx, marker.Nickname)
+	if err := statusError(status, userNotFound(marker.Nickname)); err != nil {
+		return models.ThreadRead{}, err
+	}
+	thread, err := s.thread(ctx, slugOrId)
+	if err != nil {
+		return models.ThreadRead{}, err
+	}
+	if marker.Post != 0 {
+		post, status := s.repo.GetPost(ctx, marker.Post)
+		if status == http.StatusOK && post.Thread != int(thread.Id) {
+			status = http.StatusNotFound
+		}
+		err := statusError(status, domain.ErrPostNotFound.With(
+			"Can't find post "+fmt.Sprint(marker.Post)+" in thread "+fmt.Sprint(thread.Id),
+			"id", marker.Post, "thread", thread.Id))
+		if err != nil {
+			return models.ThreadRead{}, err
+		}
+	}
+
+	read, err := s.repo.MarkThreadRead(ctx, int(thread.Id), user.Nickname, marker.Post)
+	if err != nil {
+		return models.ThreadRead{}, domain.Internal(err)
+	}
+
+	return read, nil
+}
+
+// lastRead returns the last post of a thread nickname read, 0 for none.
+// Only the user knows what they read.
+func (s Smth) lastRead(ctx context.Context, thread int, nickname string) (int, error) {
+	if err := s.self(ctx, nickname); err != nil {
+		return 0, err
+	}
+	user, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return 0, err
+	}
+
+	lastRead, err := s.repo.GetLastRead(ctx, thread, user.Nickname)
+	if err != nil {
+		return 0, domain.Internal(err)
+	}
+
+	return lastRead, nil
+}
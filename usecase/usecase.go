@@ -1,186 +1,396 @@
 package usecase
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	smth "subd"
-	"subd/constants"
+	"subd/auth"
+	"subd/cursor"
+	"subd/domain"
 	"subd/models"
 	"time"
+
+	"github.com/aryann/difflib"
 )
 
+// Options configure the use cases.
+type Options struct {
+	// RequireAuth refuses anonymous writes.
+	RequireAuth bool
+	// SessionTTL is how long a login stays valid.
+	SessionTTL time.Duration
+}
+
 type Smth struct {
 	repo    smth.Repository
+	opts    Options
+}
+
+func NewSmth(e smth.Repository, opts Options) smth.UseCase {
+	return &Smth{repo: e, opts: opts}
+}
+
+func userNotFound(nickname string) error {
+	return domain.ErrUserNotFound.With("Can't find user with nickname "+nickname, "nickname", nickname)
+}
+
+func forumNotFound(slug string) error {
+	return domain.ErrForumNotFound.With("Can't find forum with slug "+slug, "slug", slug)
+}
+
+func threadNotFound(slugOrId string) error {
+	return domain.ErrThreadNotFound.With("Can't find thread with slug or id "+slugOrId, "slug_or_id", slugOrId)
+}
+
+func postDeleted(id int) error {
+	return domain.ErrPostDeleted.With("Post "+fmt.Sprint(id)+" is deleted", "id", id)
+}
+
+func threadArchived(thread models.Thread) error {
+	return domain.ErrThreadArchived.With("Thread "+fmt.Sprint(thread.Id)+" is archived", "id", thread.Id)
 }
 
-func NewSmth(e smth.Repository) smth.UseCase {
-	return &Smth{repo: e}
+func postNotFound(id int) error {
+	return domain.ErrPostNotFound.With("Can't find post with id "+fmt.Sprint(id), "id", id)
+}
+
+// statusError turns a status reported by the repository into an error,
+// nil for http.StatusOK.
+func statusError(status int, notFound error) error {
+	switch status {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return notFound
+	default:
+		return domain.Internal(fmt.Errorf("repository status %d", status))
+	}
 }
 
-func (s Smth) GetThreads(slug string, limit int, since string, desc bool) (models.Threads, int) {
-	isExisted, err := s.repo.CheckForum(slug)
+// thread resolves a slug_or_id path parameter: numbers are ids, anything
+// else is a slug.
+func (s Smth) thread(ctx context.Context, slugOrId string) (models.Thread, error) {
+	var thread models.Thread
+	var status int
+	if id, err := strconv.Atoi(slugOrId); err != nil {
+		thread, status = s.repo.GetThreadStatus(ctx, slugOrId)
+	} else {
+		thread, status = s.repo.GetThreadById(ctx, id)
+	}
+	if err := statusError(status, threadNotFound(slugOrId)); err != nil {
+		return models.Thread{}, err
+	}
+
+	return thread, nil
+}
+
+// writableThread resolves a thread that may still change.
+func (s Smth) writableThread(ctx context.Context, slugOrId string) (models.Thread, error) {
+	thread, err := s.thread(ctx, slugOrId)
 	if err != nil {
-		return models.Threads{}, http.StatusInternalServerError
+		return models.Thread{}, err
 	}
-	if !isExisted {
-		return models.Threads{}, constants.NotFound
+	if thread.IsArchived {
+		return models.Thread{}, threadArchived(thread)
 	}
 
-	threads, err := s.repo.GetForumThreads(slug, limit, since, desc)
+	return thread, nil
+}
+
+// openThread resolves a thread that takes new posts and votes.
+func (s Smth) openThread(ctx context.Context, slugOrId string) (models.Thread, error) {
+	thread, err := s.writableThread(ctx, slugOrId)
 	if err != nil {
-		return models.Threads{}, http.StatusInternalServerError
+		return models.Thread{}, err
+	}
+	if thread.IsClosed {
+		return models.Thread{}, domain.ErrThreadClosed.With("Thread "+fmt.Sprint(thread.Id)+" is closed",
+			"id", thread.Id, "reason", thread.CloseReason)
+	}
+	if thread.IsLocked {
+		return models.Thread{}, domain.ErrThreadLocked.With("Thread "+fmt.Sprint(thread.Id)+" is locked", "id", thread.Id)
 	}
 
-	return threads, http.StatusOK
+	return thread, nil
 }
 
-func (s Smth) GetUser(nickname string) (models.User, int) {
-	user, status := s.repo.GetUser(nickname)
+// writablePost refuses changes to the posts of an archived thread.
+func (s Smth) writablePost(ctx context.Context, post models.Post) error {
+	thread, status := s.repo.GetThreadById(ctx, post.Thread)
+	if err := statusError(status, threadNotFound(fmt.Sprint(post.Thread))); err != nil {
+		return err
+	}
+	if thread.IsArchived {
+		return threadArchived(thread)
+	}
 
-	if status == http.StatusNotFound {
-		return models.User{}, constants.NotFound
+	return nil
+}
+
+// GetThreads lists the threads of a forum that pass the query's filters.
+// A cursor, when given, replaces since and must come from a listing in the
+// same order.
+func (s Smth) GetThreads(ctx context.Context, query models.ThreadQuery, after string) (models.ThreadsPage, error) {
+	isExisted, err := s.repo.CheckForum(ctx, query.Forum)
+	if err != nil {
+		return models.ThreadsPage{}, domain.Internal(err)
+	}
+	if !isExisted {
+		return models.ThreadsPage{}, forumNotFound(query.Forum)
+	}
+
+	if query.Sort == "" {
+		query.Sort = models.ThreadsByCreated
+	}
+	if after != "" {
+		query.After = &models.ThreadKey{}
+		if err := decodeCursor(after, query.After); err != nil {
+			return models.ThreadsPage{}, err
+		}
+		if query.After.Sort != query.Sort {
+			return models.ThreadsPage{}, domain.ErrInvalid.With("Cursor belongs to a listing sorted by "+query.After.Sort,
+				"cursor", after)
+		}
+		if err := checkDirection(after, query.After.Desc, query.Desc); err != nil {
+			return models.ThreadsPage{}, err
+		}
 	}
 
-	return user, http.StatusOK
+	limit := query.Limit
+	query.Limit++
+	threads, err := s.repo.GetForumThreads(ctx, query)
+	if err != nil {
+		return models.ThreadsPage{}, domain.Internal(err)
+	}
+
+	page := models.ThreadsPage{Threads: threads}
+	if len(threads) > limit {
+		page.Threads = threads[:limit]
+		key := threads[limit-1].Key(query.Sort)
+		key.Desc = query.Desc
+		next, err := cursor.Encode(key)
+		if err != nil {
+			return models.ThreadsPage{}, domain.Internal(err)
+		}
+		page.Next = next
+	}
+
+	return page, nil
+}
+
+func (s Smth) GetUser(ctx context.Context, nickname string) (models.User, error) {
+	user, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
 }
 
-func (s Smth) GetForumUsers(slug string, limit int, since string, desc bool) (models.Users, int) {
-	isExisted, err := s.repo.CheckForum(slug)
+// GetForumUsers lists the users who posted in a forum. A cursor, when
+// given, replaces since.
+func (s Smth) GetForumUsers(ctx context.Context, slug string, limit int, since string, desc bool, excludeBanned bool, after string) (models.UsersPage, error) {
+	isExisted, err := s.repo.CheckForum(ctx, slug)
 	if err != nil {
-		return models.Users{}, http.StatusInternalServerError
+		return models.UsersPage{}, domain.Internal(err)
 	}
 	if !isExisted {
-		return models.Users{}, constants.NotFound
+		return models.UsersPage{}, forumNotFound(slug)
+	}
+	if after != "" {
+		var key models.UserKey
+		if err := decodeCursor(after, &key); err != nil {
+			return models.UsersPage{}, err
+		}
+		if err := checkDirection(after, key.Desc, desc); err != nil {
+			return models.UsersPage{}, err
+		}
+		since = key.Nickname
 	}
 
-	users, err := s.repo.GetForumUsers(slug, limit, since, desc)
+	users, err := s.repo.GetForumUsers(ctx, slug, limit+1, since, desc, excludeBanned)
 	if err != nil {
-		return models.Users{}, http.StatusInternalServerError
+		return models.UsersPage{}, domain.Internal(err)
+	}
+
+	page := models.UsersPage{Users: users}
+	if len(users) > limit {
+		page.Users = users[:limit]
+		next, err := cursor.Encode(models.UserKey{Desc: desc, Nickname: users[limit-1].Nickname})
+		if err != nil {
+			return models.UsersPage{}, domain.Internal(err)
+		}
+		page.Next = next
 	}
 
-	return users, http.StatusOK
+	return page, nil
 }
 
-func (s Smth) CreateNewThread(newThread *models.Thread) (models.Thread, int) {
-	user, status := s.repo.GetUser(newThread.Author)
-	if status == constants.NotFound {
-		return models.Thread{}, constants.NotFound
+// CreateNewThread returns the existing thread together with
+// domain.ErrThreadExists when the slug is taken.
+func (s Smth) CreateNewThread(ctx context.Context, newThread *models.Thread) (models.Thread, error) {
+	if err := s.authorize(ctx, newThread.Author); err != nil {
+		return models.Thread{}, err
+	}
+
+	user, status := s.repo.GetUser(ctx, newThread.Author)
+	if err := statusError(status, userNotFound(newThread.Author)); err != nil {
+		return models.Thread{}, err
 	}
 	newThread.Author = user.Nickname
 
-	forum, status := s.repo.GetForum(newThread.Forum)
-	if status == constants.NotFound {
-		return models.Thread{}, constants.NotFound
+	forum, status := s.repo.GetForum(ctx, newThread.Forum)
+	if err := statusError(status, forumNotFound(newThread.Forum)); err != nil {
+		return models.Thread{}, err
 	}
 	newThread.Forum = forum.Slug
 
+	if err := s.checkBans(ctx, newThread.Forum, newThread.Author); err != nil {
+		return models.Thread{}, err
+	}
+
 	var err error
-	newThread.Id, err = s.repo.AddNewThread(*newThread)
+	newThread.Id, err = s.repo.AddNewThread(ctx, *newThread)
 	if err != nil {
-		thread, _ := s.repo.GetThread(newThread.Slug)
-		return thread, http.StatusConflict
+		thread, _ := s.repo.GetThread(ctx, newThread.Slug)
+		if thread.Id == 0 {
+			return models.Thread{}, domain.Internal(err)
+		}
+		return thread, domain.ErrThreadExists.With("Thread with slug "+thread.Slug+" already exists", "slug", thread.Slug)
 	}
-	err = s.repo.IncrementThreads(newThread.Forum)
 
-	s.repo.AddForumUsers(newThread.Forum, newThread.Author)
+	return *newThread, nil
+}
 
-	return *newThread, http.StatusCreated
+func (s Smth) GetThread(ctx context.Context, slugOrId string) (models.Thread, error) {
+	return s.thread(ctx, slugOrId)
 }
 
-func (s Smth) GetThread(slugOrId string) (models.Thread, int) {
-	var thread models.Thread
-	var status int
-	if id, err := strconv.Atoi(slugOrId); err != nil {
-		isExist, err := s.repo.CheckThread(slugOrId)
-		if err != nil {
-			return models.Thread{}, http.StatusInternalServerError
-		}
-		if !isExist {
-			return models.Thread{}, http.StatusNotFound
-		}
-		thread, err = s.repo.GetThread(slugOrId)
-		if err != nil {
-			return models.Thread{}, http.StatusInternalServerError
-		}
-	} else {
-		thread, status = s.repo.GetThreadById(id)
-		if status == http.StatusNotFound {
-			return models.Thread{}, status
-		}
-		if status == http.StatusInternalServerError {
-			return models.Thread{}, http.StatusInternalServerError
+func (s Smth) CreateNewPosts(ctx context.Context, newPosts []*models.Post, slugOrId string) error {
+	for _, post := range newPosts {
+		if err := s.authorize(ctx, post.Author); err != nil {
+			return err
 		}
 	}
-	return thread, http.StatusOK
-}
 
-func (s Smth) CreateNewPosts(newPosts []*models.Post, slugOrId string)  int {
-	var thread models.Thread
-	var status int
-	if id, err := strconv.Atoi(slugOrId); err != nil {
-		thread, status = s.repo.GetThreadStatus(slugOrId)
-		if status == constants.NotFound {
-			return http.StatusNotFound
-		}
-	} else {
-		thread, status = s.repo.GetThreadById(id)
-		if status == http.StatusNotFound {
-			return status
-		}
-		if status == http.StatusInternalServerError {
-			return http.StatusInternalServerError
-		}
+	thread, err := s.openThread(ctx, slugOrId)
+	if err != nil {
+		return err
 	}
 
 	if len(newPosts) == 0 {
-		return http.StatusCreated
+		return nil
+	}
+
+	authors := make([]string, 0, len(newPosts))
+	for _, post := range newPosts {
+		authors = append(authors, post.Author)
+	}
+	if err := s.checkBans(ctx, thread.Forum, authors...); err != nil {
+		return err
 	}
 
 	now := time.Now()
 
-	return s.repo.AddPost(newPosts, thread, now)
+	switch status := s.repo.AddPost(ctx, newPosts, thread, now); status {
+	case http.StatusCreated:
+		return nil
+	case http.StatusConflict:
+		return s.explainParentConflict(ctx, newPosts, thread)
+	case http.StatusNotFound:
+		return s.explainMissingAuthor(ctx, newPosts)
+	default:
+		return domain.Internal(fmt.Errorf("repository status %d", status))
+	}
 }
 
-func (s Smth) CreateNewForum(newForum *models.Forum) (models.Forum, int) {
-	user, status := s.repo.GetUser(newForum.Owner)
-	if status == constants.NotFound {
-		return models.Forum{}, constants.NotFound
+// explainParentConflict finds the parent that made a batch fail. The batch
+// has been rolled back, so this only runs on the error path.
+func (s Smth) explainParentConflict(ctx context.Context, newPosts []*models.Post, thread models.Thread) error {
+	for _, post := range newPosts {
+		if post.Parent == 0 {
+			continue
+		}
+		parent, status := s.repo.GetPost(ctx, post.Parent)
+		if status != http.StatusOK || parent.Thread != int(thread.Id) {
+			return domain.ErrParentPost.With(
+				fmt.Sprintf("Parent post %d is not in thread %d", post.Parent, thread.Id),
+				"parent", post.Parent, "thread", thread.Id)
+		}
+	}
+
+	return domain.ErrParentPost.With("Parent post is not in thread "+fmt.Sprint(thread.Id), "thread", thread.Id)
+}
+
+// explainMissingAuthor finds the author that made a batch fail.
+func (s Smth) explainMissingAuthor(ctx context.Context, newPosts []*models.Post) error {
+	for _, post := range newPosts {
+		isExist, err := s.repo.CheckUser(ctx, post.Author)
+		if err != nil {
+			return domain.Internal(err)
+		}
+		if !isExist {
+			return userNotFound(post.Author)
+		}
+	}
+
+	return domain.ErrUserNotFound
+}
+
+// CreateNewForum returns the existing forum together with
+// domain.ErrForumExists when the slug is taken.
+func (s Smth) CreateNewForum(ctx context.Context, newForum *models.Forum) (models.Forum, error) {
+	if err := s.authorize(ctx, newForum.Owner); err != nil {
+		return models.Forum{}, err
+	}
+
+	user, status := s.repo.GetUser(ctx, newForum.Owner)
+	if err := statusError(status, userNotFound(newForum.Owner)); err != nil {
+		return models.Forum{}, err
 	}
 	newForum.Owner = user.Nickname
 
-	oldForum, status := s.repo.GetForum(newForum.Slug)
-	if status != constants.NotFound {
-		return oldForum, http.StatusConflict
+	oldForum, status := s.repo.GetForum(ctx, newForum.Slug)
+	if status != http.StatusNotFound {
+		if err := statusError(status, nil); err != nil {
+			return models.Forum{}, err
+		}
+		return oldForum, domain.ErrForumExists.With("Forum with slug "+oldForum.Slug+" already exists", "slug", oldForum.Slug)
 	}
-	err, _ := s.repo.AddNewForum(newForum)
+	err, _ := s.repo.AddNewForum(ctx, newForum)
 	if err != nil {
-		return models.Forum{}, http.StatusInternalServerError
+		return models.Forum{}, domain.Internal(err)
 	}
-	//s.repo.AddForumUsers(newForum.Slug, newForum.Owner)
+	//s.repo.AddForumUsers(ctx, newForum.Slug, newForum.Owner)
 
-	return *newForum, http.StatusCreated
+	return *newForum, nil
 }
 
-func (s Smth) GetForum(slug string) (models.Forum, int) {
-	forum, status := s.repo.GetForum(slug)
+func (s Smth) GetForum(ctx context.Context, slug string) (models.Forum, error) {
+	forum, status := s.repo.GetForum(ctx, slug)
+	if err := statusError(status, forumNotFound(slug)); err != nil {
+		return models.Forum{}, err
+	}
 
-	return forum, status
+	return forum, nil
 }
 
-func (s Smth) GetPost(id int, related string) (models.FullPost, int) {
+func (s Smth) GetPost(ctx context.Context, id int, related string) (models.FullPost, error) {
 	fullPost := models.FullPost{}
 	var err error
 
-	isExisted, err := s.repo.CheckPost(id)
+	isExisted, err := s.repo.CheckPost(ctx, id)
 	if err != nil {
-		return models.FullPost{}, http.StatusInternalServerError
+		return models.FullPost{}, domain.Internal(err)
 	}
 	if !isExisted {
-		return models.FullPost{}, constants.NotFound
+		return models.FullPost{}, postNotFound(id)
 	}
 
-	post, _ := s.repo.GetPost(id)
+	post, _ := s.repo.GetPost(ctx, id)
 	fullPost.Post = &post
 
 	if related != "" {
@@ -189,110 +399,306 @@ func (s Smth) GetPost(id int, related string) (models.FullPost, int) {
 		for _, elem := range split {
 			switch elem {
 			case "user":
-				user, _ := s.repo.GetUser(fullPost.Post.Author)
+				user, _ := s.repo.GetUser(ctx, fullPost.Post.Author)
 				fullPost.Author = &user
 			case "thread":
-				thread, _ := s.repo.GetThreadById(fullPost.Post.Thread)
+				thread, _ := s.repo.GetThreadById(ctx, fullPost.Post.Thread)
 				fullPost.Thread = &thread
 			case "forum":
-				forum, _ := s.repo.GetForum(fullPost.Post.Forum)
+				forum, _ := s.repo.GetForum(ctx, fullPost.Post.Forum)
 				fullPost.Forum = &forum
 			}
 		}
 	}
 
-	return fullPost, http.StatusOK
+	return fullPost, nil
 
 }
 
-func (s Smth) EditMessageNull(id int) (models.PostNullMessage, int) {
-	post, status := s.repo.GetPostNull(id)
-	if status == constants.NotFound {
-		return models.PostNullMessage{}, constants.NotFound
+func (s Smth) EditMessageNull(ctx context.Context, id int) (models.PostNullMessage, error) {
+	post, status := s.repo.GetPostNull(ctx, id)
+	if err := statusError(status, postNotFound(id)); err != nil {
+		return models.PostNullMessage{}, err
 	}
 
-
-	return post, http.StatusOK
+	return post, nil
 }
 
-func (s Smth) EditMessage(id int, message string) (models.Post, int) {
-	post, status := s.repo.GetPost(id)
-	if status == constants.NotFound {
-		return models.Post{}, constants.NotFound
+// EditMessage returns the post together with domain.ErrPostUnchanged when
+// the message is the same as the stored one. The replaced message is kept
+// as a revision attributed to editor, who may be left empty and defaults to
+// the caller.
+func (s Smth) EditMessage(ctx context.Context, id int, message string, editor string) (models.Post, error) {
+	if caller, ok := auth.CallerFrom(ctx); ok && editor == "" {
+		editor = caller.Nickname
+	}
+	if editor != "" {
+		if err := s.authorize(ctx, editor); err != nil {
+			return models.Post{}, err
+		}
+	}
+
+	post, status := s.repo.GetPost(ctx, id)
+	if err := statusError(status, postNotFound(id)); err != nil {
+		return models.Post{}, err
+	}
+	if err := s.authorizeIn(ctx, post.Forum, post.Author); err != nil {
+		return models.Post{}, err
+	}
+	if post.IsDeleted {
+		return models.Post{}, postDeleted(id)
 	}
 	if post.Message == message {
-		return post, http.StatusConflict
+		return post, domain.ErrPostUnchanged
+	}
+	if err := s.writablePost(ctx, post); err != nil {
+		return models.Post{}, err
 	}
-	post.IsEdited = true
-	post.Message = message
 
-	err := s.repo.EditMessage(id, message)
+	if editor != "" {
+		isExist, err := s.repo.CheckUser(ctx, editor)
+		if err != nil {
+			return models.Post{}, domain.Internal(err)
+		}
+		if !isExist {
+			return models.Post{}, userNotFound(editor)
+		}
+	}
+
+	err := s.repo.EditMessage(ctx, id, message, editor)
 	if err != nil {
-		return models.Post{}, http.StatusInternalServerError
+		return models.Post{}, domain.Internal(err)
+	}
+
+	post, status = s.repo.GetPost(ctx, id)
+	if err := statusError(status, postNotFound(id)); err != nil {
+		return models.Post{}, err
+	}
+
+	return post, nil
+}
+
+// history checks that the caller may read the revisions of a post. Those
+// of a deleted post are left to its author, the moderators of its forum
+// and the admins.
+func (s Smth) history(ctx context.Context, post models.Post) error {
+	if !post.IsDeleted {
+		return nil
+	}
+	if err := s.authorizeIn(ctx, post.Forum, post.Author); err != nil {
+		if domain.KindOf(err) == domain.KindInternal {
+			return err
+		}
+		return postDeleted(post.Id)
+	}
+
+	return nil
+}
+
+// Revisions lists the messages a post had before each of its edits.
+func (s Smth) Revisions(ctx context.Context, id int) (models.Revisions, error) {
+	post, status := s.repo.GetPost(ctx, id)
+	if err := statusError(status, postNotFound(id)); err != nil {
+		return models.Revisions{}, err
+	}
+	if err := s.history(ctx, post); err != nil {
+		return models.Revisions{}, err
 	}
 
+	revisions, err := s.repo.GetPostRevisions(ctx, id)
+	if err != nil {
+		return models.Revisions{}, domain.Internal(err)
+	}
 
-	return post, http.StatusOK
+	return revisions, nil
 }
 
-func (s Smth) Clear() error {
-	err := s.repo.Clear()
+// DiffRevisions compares two versions of a post line by line. Versions are
+// numbered like revisions, the current message being version editCount+1;
+// to == 0 stands for the current message.
+func (s Smth) DiffRevisions(ctx context.Context, id int, from int, to int) (models.RevisionDiff, error) {
+	post, status := s.repo.GetPost(ctx, id)
+	if err := statusError(status, postNotFound(id)); err != nil {
+		return models.RevisionDiff{}, err
+	}
+	if err := s.history(ctx, post); err != nil {
+		return models.RevisionDiff{}, err
+	}
+
+	revisions, err := s.repo.GetPostRevisions(ctx, id)
 	if err != nil {
+		return models.RevisionDiff{}, domain.Internal(err)
+	}
+	current := len(revisions) + 1
+	if to == 0 {
+		to = current
+	}
+
+	version := func(n int) (string, error) {
+		if n == current {
+			return post.Message, nil
+		}
+		if n < 1 || n > len(revisions) {
+			return "", domain.ErrRevisionNotFound.With(
+				fmt.Sprintf("Post %d has no revision %d", id, n), "id", id, "revision", n)
+		}
+		return revisions[n-1].Message, nil
+	}
+
+	older, err := version(from)
+	if err != nil {
+		return models.RevisionDiff{}, err
+	}
+	newer, err := version(to)
+	if err != nil {
+		return models.RevisionDiff{}, err
+	}
+
+	return models.RevisionDiff{Post: id, From: from, To: to, Lines: diffLines(older, newer)}, nil
+}
+
+// maxDiffCells bounds the LCS table difflib builds; larger inputs are
+// reported as a whole replacement.
+const maxDiffCells = 1 << 22
+
+func diffLines(older string, newer string) []models.DiffLine {
+	a, b := strings.Split(older, "\n"), strings.Split(newer, "\n")
+
+	lines := make([]models.DiffLine, 0, len(a)+len(b))
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			lines = append(lines, models.DiffLine{Op: "-", Text: line})
+		}
+		for _, line := range b {
+			lines = append(lines, models.DiffLine{Op: "+", Text: line})
+		}
+		return lines
+	}
+
+	for _, record := range difflib.Diff(a, b) {
+		op := "="
+		switch record.Delta {
+		case difflib.LeftOnly:
+			op = "-"
+		case difflib.RightOnly:
+			op = "+"
+		}
+		lines = append(lines, models.DiffLine{Op: op, Text: record.Payload})
+	}
+	return lines
+}
+
+// DeletePost leaves a tombstone in place of the post, so that its replies
+// keep their position in the thread tree.
+func (s Smth) DeletePost(ctx context.Context, id int) error {
+	post, status := s.repo.GetPost(ctx, id)
+	if err := statusError(status, postNotFound(id)); err != nil {
+		return err
+	}
+	if err := s.authorizeIn(ctx, post.Forum, post.Author); err != nil {
+		return err
+	}
+	if err := s.writablePost(ctx, post); err != nil {
+		return err
+	}
+
+	return statusError(s.repo.DeletePost(ctx, id), postNotFound(id))
+}
+
+func (s Smth) RestorePost(ctx context.Context, id int) error {
+	if err := s.admin(ctx, "restore posts"); err != nil {
+		return err
+	}
+
+	return statusError(s.repo.RestorePost(ctx, id), postNotFound(id))
+}
+
+func (s Smth) Clear(ctx context.Context) error {
+	if err := s.admin(ctx, "clear the service"); err != nil {
 		return err
 	}
 
+	err := s.repo.Clear(ctx)
+	if err != nil {
+		return domain.Internal(err)
+	}
+
 	return nil
 }
 
-func (s Smth) Status() (models.Status, error) {
-	status, err := s.repo.Status()
+func (s Smth) Status(ctx context.Context) (models.Status, error) {
+	status, err := s.repo.Status(ctx)
 	if err != nil {
-		return models.Status{}, err
+		return models.Status{}, domain.Internal(err)
 	}
 
 	return status, nil
 }
 
-func (s Smth) CreateUser(nickname string, user models.User) (models.Users, int) {
-	isExist, err := s.repo.CheckUserByNicknameOrEmail(nickname, user.Email.String())
+// CreateUser returns the users owning the nickname or the email together
+// with domain.ErrUserExists when either is taken. A password, when given,
+// lets the user log in.
+func (s Smth) CreateUser(ctx context.Context, nickname string, user models.User, password string) (models.Users, error) {
+	if password == "" && s.opts.RequireAuth {
+		return models.Users{}, domain.ErrValidation.With("Request does not pass validation",
+			"fields", models.ValidationError{{Field: "password", Rule: "required", Message: "is required"}})
+	}
+
+	isExist, err := s.repo.CheckUserByNicknameOrEmail(ctx, nickname, user.Email.String())
 	if err != nil {
-		return models.Users{}, http.StatusInternalServerError
+		return models.Users{}, domain.Internal(err)
 	}
 
 	var users models.Users
 
 	if isExist {
-		users, err = s.repo.GetUserByNicknameOrEmail(nickname, user.Email.String())
+		users, err = s.repo.GetUserByNicknameOrEmail(ctx, nickname, user.Email.String())
 		if err != nil {
-			return models.Users{}, http.StatusInternalServerError
+			return models.Users{}, domain.Internal(err)
 		}
-		return users, http.StatusConflict
+		return users, domain.ErrUserExists.With("User with nickname "+nickname+" or email "+user.Email.String()+" already exists",
+			"nickname", nickname, "email", user.Email.String())
 	}
 
 
-	err = s.repo.CreateUser(nickname, user)
+	err = s.repo.CreateUser(ctx, nickname, user)
 	if err != nil {
-		return models.Users{}, http.StatusInternalServerError
+		return models.Users{}, domain.Internal(err)
+	}
+	if password != "" {
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			return models.Users{}, domain.Internal(err)
+		}
+		err = s.repo.SetCredentials(ctx, nickname, hash)
+		if err != nil {
+			return models.Users{}, domain.Internal(err)
+		}
 	}
 
-	newUser, _ := s.repo.GetUser(nickname)
+	newUser, _ := s.repo.GetUser(ctx, nickname)
 	users = append(users, newUser)
 
-	return users, http.StatusCreated
+	return users, nil
 }
 
-func (s Smth) UpdateUser(nickname string, user models.User) (models.User, int) {
-	oldUser, status := s.repo.GetUser(nickname)
-	if status == constants.NotFound {
-		return models.User{}, http.StatusNotFound
+func (s Smth) UpdateUser(ctx context.Context, nickname string, user models.User) (models.User, error) {
+	if err := s.authorize(ctx, nickname); err != nil {
+		return models.User{}, err
+	}
+
+	oldUser, status := s.repo.GetUser(ctx, nickname)
+	if err := statusError(status, userNotFound(nickname)); err != nil {
+		return models.User{}, err
 	}
 
-	isExist, err := s.repo.CheckUserByEmail(user.Email.String())
+	isExist, err := s.repo.CheckUserByEmail(ctx, user.Email.String())
 	if err != nil {
-		return models.User{}, http.StatusInternalServerError
+		return models.User{}, domain.Internal(err)
 	}
 	if isExist {
-		return models.User{}, http.StatusConflict
+		return models.User{}, domain.ErrEmailTaken.With("Email "+user.Email.String()+" is used by another user",
+			"email", user.Email.String())
 	}
 
 	if user.Email == "" {
@@ -305,257 +711,358 @@ func (s Smth) UpdateUser(nickname string, user models.User) (models.User, int) {
 		user.Fullname = oldUser.Fullname
 	}
 
-	err = s.repo.UpdateUser(nickname, user)
+	err = s.repo.UpdateUser(ctx, nickname, user)
 	if err != nil {
-		return models.User{}, http.StatusInternalServerError
+		return models.User{}, domain.Internal(err)
 	}
 
-	newUser, _ := s.repo.GetUser(nickname)
+	newUser, _ := s.repo.GetUser(ctx, nickname)
 
-	return newUser, http.StatusOK
+	return newUser, nil
 }
 
-func (s Smth) UpdateThread(slugOrId string, newThread models.Thread) (models.Thread, int) {
-	var thread models.Thread
-	if id, err := strconv.Atoi(slugOrId); err != nil {
-		oldThread, status := s.repo.GetThreadStatus(slugOrId)
-		if status == constants.NotFound {
-			return models.Thread{}, http.StatusNotFound
-		}
-		if newThread.Message == "" {
-			newThread.Message = oldThread.Message
-		}
-		if newThread.Title == "" {
-			newThread.Title = oldThread.Title
-		}
-		thread, err = s.repo.UpdateThread(slugOrId, newThread)
-		if err != nil {
-			return models.Thread{}, http.StatusInternalServerError
-		}
-	} else {
-		oldThread, status := s.repo.GetThreadById(id)
-		if status == constants.NotFound {
-			return models.Thread{}, http.StatusNotFound
-		}
-		if newThread.Message == "" {
-			newThread.Message = oldThread.Message
-		}
-		if newThread.Title == "" {
-			newThread.Title = oldThread.Title
-		}
-		thread, err = s.repo.UpdateThreadById(id, newThread)
+// DeleteThread removes a thread with its posts and votes.
+func (s Smth) DeleteThread(ctx context.Context, slugOrId string) error {
+	thread, err := s.thread(ctx, slugOrId)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeIn(ctx, thread.Forum, thread.Author); err != nil {
+		return err
 	}
 
-	return thread, http.StatusOK
+	return statusError(s.repo.DeleteThread(ctx, int(thread.Id)), threadNotFound(slugOrId))
 }
 
-func (s Smth) Vote(slugOrId string, vote models.Vote) (models.Thread, int) {
-	var thread models.Thread
-	var status int
-	isExist, err := s.repo.CheckUser(vote.Nickname)
+// ArchiveThread makes a thread read-only, or writable again.
+func (s Smth) ArchiveThread(ctx context.Context, slugOrId string, archived bool) (models.Thread, error) {
+	thread, err := s.thread(ctx, slugOrId)
 	if err != nil {
-		return models.Thread{}, http.StatusInternalServerError
+		return models.Thread{}, err
+	}
+	if err := s.authorizeIn(ctx, thread.Forum, thread.Author); err != nil {
+		return models.Thread{}, err
+	}
+
+	err = statusError(s.repo.SetThreadArchived(ctx, int(thread.Id), archived), threadNotFound(slugOrId))
+	if err != nil {
+		return models.Thread{}, err
+	}
+	thread.IsArchived = archived
+
+	return thread, nil
+}
+
+// ModerateThread changes the locked, pinned and closed flags of a thread.
+func (s Smth) ModerateThread(ctx context.Context, slugOrId string, m models.ThreadModeration) (models.Thread, error) {
+	thread, err := s.thread(ctx, slugOrId)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	if err := s.moderator(ctx, thread.Forum); err != nil {
+		return models.Thread{}, err
+	}
+
+	err = statusError(s.repo.ModerateThread(ctx, int(thread.Id), m), threadNotFound(slugOrId))
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	thread, status := s.repo.GetThreadById(ctx, int(thread.Id))
+	if err := statusError(status, threadNotFound(slugOrId)); err != nil {
+		return models.Thread{}, err
+	}
+
+	return thread, nil
+}
+
+func (s Smth) UpdateThread(ctx context.Context, slugOrId string, newThread models.Thread) (models.Thread, error) {
+	oldThread, err := s.writableThread(ctx, slugOrId)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	if err := s.authorizeIn(ctx, oldThread.Forum, oldThread.Author); err != nil {
+		return models.Thread{}, err
+	}
+	if newThread.Message == "" {
+		newThread.Message = oldThread.Message
+	}
+	if newThread.Title == "" {
+		newThread.Title = oldThread.Title
+	}
+
+	thread, err := s.repo.UpdateThreadById(ctx, int(oldThread.Id), newThread)
+	if err != nil {
+		return models.Thread{}, domain.Internal(err)
+	}
+
+	return thread, nil
+}
+
+func (s Smth) Vote(ctx context.Context, slugOrId string, vote models.Vote) (models.Thread, error) {
+	if err := s.authorize(ctx, vote.Nickname); err != nil {
+		return models.Thread{}, err
+	}
+
+	isExist, err := s.repo.CheckUser(ctx, vote.Nickname)
+	if err != nil {
+		return models.Thread{}, domain.Internal(err)
 	}
 	if !isExist {
-		return models.Thread{}, http.StatusNotFound
+		return models.Thread{}, userNotFound(vote.Nickname)
 	}
-	if id, err := strconv.Atoi(slugOrId); err != nil {
-		thread, status = s.repo.GetThreadStatus(slugOrId)
-		if status == constants.NotFound {
-			return models.Thread{}, http.StatusNotFound
-		}
-		isExist, err = s.repo.CheckVote(int(thread.Id), vote.Nickname)
+
+	thread, err := s.openThread(ctx, slugOrId)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	if err := s.checkBans(ctx, thread.Forum, vote.Nickname); err != nil {
+		return models.Thread{}, err
+	}
+
+	isExist, err = s.repo.CheckVote(ctx, int(thread.Id), vote.Nickname)
+	if err != nil {
+		return models.Thread{}, domain.Internal(err)
+	}
+	if !isExist {
+		err = s.repo.AddVote(ctx, int(thread.Id), vote)
 		if err != nil {
-			return models.Thread{}, http.StatusInternalServerError
-		}
-		if !isExist {
-			err = s.repo.AddVote(int(thread.Id), vote)
-			if err != nil {
-				return models.Thread{}, http.StatusInternalServerError
-			}
-			thread.Votes += vote.Voice
-		} else {
-			num, err := s.repo.GetValueVote(int(thread.Id), vote.Nickname)
-			if err != nil {
-				return models.Thread{}, http.StatusInternalServerError
-			}
-			if num != vote.Voice {
-				err = s.repo.UpdateVote(int(thread.Id), vote)
-				if err != nil {
-					return models.Thread{}, http.StatusInternalServerError
-				}
-				thread.Votes += 2 * vote.Voice
-			}
+			return models.Thread{}, domain.Internal(err)
 		}
+		thread.Votes += vote.Voice
 	} else {
-		thread, status = s.repo.GetThreadById(id)
-		if status == constants.NotFound {
-			return models.Thread{}, http.StatusNotFound
-		}
-		isExist, err = s.repo.CheckVote(id, vote.Nickname)
+		num, err := s.repo.GetValueVote(ctx, int(thread.Id), vote.Nickname)
 		if err != nil {
-			return models.Thread{}, http.StatusInternalServerError
+			return models.Thread{}, domain.Internal(err)
 		}
-		if !isExist {
-			err = s.repo.AddVote(int(thread.Id), vote)
-			if err != nil {
-				return models.Thread{}, http.StatusInternalServerError
-			}
-			thread.Votes += vote.Voice
-		} else {
-			num, err := s.repo.GetValueVote(int(thread.Id), vote.Nickname)
+		if num != vote.Voice {
+			err = s.repo.UpdateVote(ctx, int(thread.Id), vote)
 			if err != nil {
-				return models.Thread{}, http.StatusInternalServerError
-			}
-			if num != vote.Voice {
-				err = s.repo.UpdateVote(id, vote)
-				if err != nil {
-					return models.Thread{}, http.StatusInternalServerError
-				}
-				thread.Votes += 2 * vote.Voice
+				return models.Thread{}, domain.Internal(err)
 			}
+			thread.Votes += 2 * vote.Voice
 		}
 	}
 
-	return thread, http.StatusOK
+	return thread, nil
 }
 
-func (s Smth) GetThreadSortFlat(slugOrId string, limit int, since int, desc bool) (models.Posts, int) {
-	var thread models.Thread
-	var status int
-	if id, err := strconv.Atoi(slugOrId); err != nil {
-		isExist, err := s.repo.CheckThread(slugOrId)
+// postsCursor reads the cursor of a thread listing in the given sort and
+// direction, nil when there is none.
+func postsCursor(after string, sort string, desc bool) (*models.PostKey, error) {
+	if after == "" {
+		return nil, nil
+	}
+	key := &models.PostKey{}
+	if err := decodeCursor(after, key); err != nil {
+		return nil, err
+	}
+	if key.Sort != sort {
+		return nil, domain.ErrInvalid.With("Cursor belongs to a listing sorted by "+key.Sort, "cursor", after)
+	}
+	if err := checkDirection(after, key.Desc, desc); err != nil {
+		return nil, err
+	}
+	if sort == models.PostsFlat && key.Created == nil {
+		return nil, domain.ErrInvalid.With("Malformed cursor", "cursor", after)
+	}
+	return key, nil
+}
+
+// postsAfter reads the cursor of a tree listing into since.
+func postsAfter(after string, sort string, desc bool, since *int) error {
+	key, err := postsCursor(after, sort, desc)
+	if err != nil || key == nil {
+		return err
+	}
+	*since = key.Id
+	return nil
+}
+
+// postKey is the position of a post in the flat sort; the legacy since
+// parameter names the post a listing follows.
+func (s Smth) postKey(ctx context.Context, id int, desc bool) (*models.PostKey, error) {
+	post, status := s.repo.GetPost(ctx, id)
+	if err := statusError(status, postNotFound(id)); err != nil {
+		return nil, err
+	}
+	key := post.Key(models.PostsFlat, desc)
+	return &key, nil
+}
+
+// postsPage trims a listing fetched with one post too many.
+func postsPage(posts models.Posts, limit int, sort string, desc bool) (models.PostsPage, error) {
+	page := models.PostsPage{Posts: posts}
+	if len(posts) > limit {
+		page.Posts = posts[:limit]
+		next, err := cursor.Encode(posts[limit-1].Key(sort, desc))
 		if err != nil {
-			return models.Posts{}, http.StatusInternalServerError
+			return models.PostsPage{}, domain.Internal(err)
 		}
-		if !isExist {
-			return models.Posts{}, http.StatusNotFound
+		page.Next = next
+	}
+	return page, nil
+}
+
+// flatLess orders positions of the flat sort.
+func flatLess(a, b *models.PostKey) bool {
+	if !a.Created.Equal(*b.Created) {
+		return a.Created.Before(*b.Created)
+	}
+	return a.Id < b.Id
+}
+
+// GetThreadSortFlat lists the posts of a thread by creation. With
+// unreadFor, only the posts that user has not read yet are listed.
+func (s Smth) GetThreadSortFlat(ctx context.Context, slugOrId string, limit int, since int, desc bool, after string, unreadFor string) (models.PostsPage, error) {
+	thread, err := s.thread(ctx, slugOrId)
+	if err != nil {
+		return models.PostsPage{}, err
+	}
+	key, err := postsCursor(after, models.PostsFlat, desc)
+	if err != nil {
+		return models.PostsPage{}, err
+	}
+	if key == nil && since != 0 {
+		if key, err = s.postKey(ctx, since, desc); err != nil {
+			return models.PostsPage{}, err
 		}
-		thread, err = s.repo.GetThread(slugOrId)
-		if err != nil {
-			return models.Posts{}, http.StatusInternalServerError
+	}
+	lastRead := 0
+	if unreadFor != "" {
+		if lastRead, err = s.lastRead(ctx, int(thread.Id), unreadFor); err != nil {
+			return models.PostsPage{}, err
 		}
-	} else {
-		thread, status = s.repo.GetThreadById(id)
-		if status == constants.NotFound {
-			return models.Posts{}, http.StatusNotFound
+	}
+
+	if lastRead != 0 && !desc {
+		// The unread posts follow the last one read.
+		if read, status := s.repo.GetPost(ctx, lastRead); status == http.StatusOK {
+			readKey := read.Key(models.PostsFlat, desc)
+			if key == nil || flatLess(key, &readKey) {
+				key = &readKey
+			}
 		}
 	}
+
+	var posts models.Posts
 	if desc == true {
-		posts, err := s.repo.GetPostsFlatDesc(int(thread.Id) ,limit, since)
-		if err != nil {
-			return models.Posts{}, http.StatusInternalServerError
-		}
-		return posts, http.StatusOK
+		posts, err = s.repo.GetPostsFlatDesc(ctx, int(thread.Id), limit+1, key)
 	} else {
-		posts, err := s.repo.GetPostsFlat(int(thread.Id) ,limit, since)
-		if err != nil {
-			return models.Posts{}, http.StatusInternalServerError
-		}
-		return posts, http.StatusOK
+		posts, err = s.repo.GetPostsFlat(ctx, int(thread.Id), limit+1, key)
+	}
+	if err != nil {
+		return models.PostsPage{}, domain.Internal(err)
 	}
-}
 
-func (s Smth) GetThreadSortTree(slugOrId string, limit int, since int, desc bool) (models.Posts, int) {
-	var thread models.Thread
-	var status int
-	if id, err := strconv.Atoi(slugOrId); err != nil {
-		isExist, err := s.repo.CheckThread(slugOrId)
+	if lastRead == 0 {
+		return postsPage(posts, limit, models.PostsFlat, desc)
+	}
+	if !desc {
+		// A post read out of order may still follow the last one read.
+		page, err := postsPage(posts, limit, models.PostsFlat, desc)
 		if err != nil {
-			return models.Posts{}, http.StatusInternalServerError
+			return models.PostsPage{}, err
 		}
-		if !isExist {
-			return models.Posts{}, http.StatusNotFound
-		}
-		thread, err = s.repo.GetThread(slugOrId)
-		if err != nil {
-			return models.Posts{}, http.StatusInternalServerError
+		unread := page.Posts[:0]
+		for _, post := range page.Posts {
+			if post.Id > lastRead {
+				unread = append(unread, post)
+			}
 		}
-	} else {
-		thread, status = s.repo.GetThreadById(id)
-		if status == constants.NotFound {
-			return models.Posts{}, http.StatusNotFound
+		page.Posts = unread
+		return page, nil
+	}
+	// Newest first, the unread posts come before the read ones: the
+	// listing ends with the first post read.
+	unread := posts[:0]
+	for _, post := range posts {
+		if post.Id > lastRead {
+			unread = append(unread, post)
 		}
 	}
+	if len(unread) < len(posts) {
+		return models.PostsPage{Posts: unread}, nil
+	}
+	return postsPage(posts, limit, models.PostsFlat, desc)
+}
+
+func (s Smth) GetThreadSortTree(ctx context.Context, slugOrId string, limit int, since int, desc bool, after string) (models.PostsPage, error) {
+	thread, err := s.thread(ctx, slugOrId)
+	if err != nil {
+		return models.PostsPage{}, err
+	}
+	if err := postsAfter(after, models.PostsTree, desc, &since); err != nil {
+		return models.PostsPage{}, err
+	}
+
+	var posts models.Posts
 	if since != 0 {
 		if desc == true {
-			posts, err := s.repo.GetPostsTreeSinceDesc(int(thread.Id) ,limit, since)
-			if err != nil {
-				return models.Posts{}, http.StatusInternalServerError
-			}
-			return posts, http.StatusOK
+			posts, err = s.repo.GetPostsTreeSinceDesc(ctx, int(thread.Id), limit+1, since)
 		} else {
-			posts, err := s.repo.GetPostsTreeSince(int(thread.Id) ,limit, since)
-			if err != nil {
-				return models.Posts{}, http.StatusInternalServerError
-			}
-			return posts, http.StatusOK
+			posts, err = s.repo.GetPostsTreeSince(ctx, int(thread.Id), limit+1, since)
 		}
 	} else {
 		if desc == true {
-			posts, err := s.repo.GetPostsTreeDesc(int(thread.Id) ,limit)
-			if err != nil {
-				return models.Posts{}, http.StatusInternalServerError
-			}
-			return posts, http.StatusOK
+			posts, err = s.repo.GetPostsTreeDesc(ctx, int(thread.Id), limit+1)
 		} else {
-			posts, err := s.repo.GetPostsTree(int(thread.Id) ,limit)
-			if err != nil {
-				return models.Posts{}, http.StatusInternalServerError
-			}
-			return posts, http.StatusOK
+			posts, err = s.repo.GetPostsTree(ctx, int(thread.Id), limit+1)
 		}
 	}
+	if err != nil {
+		return models.PostsPage{}, domain.Internal(err)
+	}
+
+	return postsPage(posts, limit, models.PostsTree, desc)
 }
 
-func (s Smth) GetThreadSortParentTree(slugOrId string, limit int, since int, desc bool) (models.Posts, int) {
-	var thread models.Thread
-	var status int
-	if id, err := strconv.Atoi(slugOrId); err != nil {
-		isExist, err := s.repo.CheckThread(slugOrId)
-		if err != nil {
-			return models.Posts{}, http.StatusInternalServerError
-		}
-		if !isExist {
-			return models.Posts{}, http.StatusNotFound
-		}
-		thread, err = s.repo.GetThread(slugOrId)
-		if err != nil {
-			return models.Posts{}, http.StatusInternalServerError
-		}
-	} else {
-		thread, status = s.repo.GetThreadById(id)
-		if status == constants.NotFound {
-			return models.Posts{}, http.StatusNotFound
-		}
+// GetThreadSortParentTree pages by root posts: limit counts the roots, each
+// listed with all its replies.
+func (s Smth) GetThreadSortParentTree(ctx context.Context, slugOrId string, limit int, since int, desc bool, after string) (models.PostsPage, error) {
+	thread, err := s.thread(ctx, slugOrId)
+	if err != nil {
+		return models.PostsPage{}, err
+	}
+	if err := postsAfter(after, models.PostsParentTree, desc, &since); err != nil {
+		return models.PostsPage{}, err
 	}
+
+	var posts models.Posts
 	if since != 0 {
 		if desc == true {
-			posts, err := s.repo.GetPostsParentTreeSinceDesc(int(thread.Id) ,limit, since)
-			if err != nil {
-				return models.Posts{}, http.StatusInternalServerError
-			}
-			return posts, http.StatusOK
+			posts, err = s.repo.GetPostsParentTreeSinceDesc(ctx, int(thread.Id), limit+1, since)
 		} else {
-			posts, err := s.repo.GetPostsParentTreeSince(int(thread.Id) ,limit, since)
-			if err != nil {
-				return models.Posts{}, http.StatusInternalServerError
-			}
-			return posts, http.StatusOK
+			posts, err = s.repo.GetPostsParentTreeSince(ctx, int(thread.Id), limit+1, since)
 		}
 	} else {
 		if desc == true {
-			posts, err := s.repo.GetPostsParentTreeDesc(int(thread.Id) ,limit)
-			if err != nil {
-				return models.Posts{}, http.StatusInternalServerError
-			}
-			return posts, http.StatusOK
+			posts, err = s.repo.GetPostsParentTreeDesc(ctx, int(thread.Id), limit+1)
 		} else {
-			posts, err := s.repo.GetPostsParentTree(int(thread.Id) ,limit)
+			posts, err = s.repo.GetPostsParentTree(ctx, int(thread.Id), limit+1)
+		}
+	}
+	if err != nil {
+		return models.PostsPage{}, domain.Internal(err)
+	}
+
+	page := models.PostsPage{Posts: posts}
+	roots := 0
+	for i, post := range posts {
+		if post.Parent != 0 {
+			continue
+		}
+		roots++
+		if roots > limit {
+			page.Posts = posts[:i]
+			next, err := cursor.Encode(posts[i-1].Key(models.PostsParentTree, desc))
 			if err != nil {
-				return models.Posts{}, http.StatusInternalServerError
+				return models.PostsPage{}, domain.Internal(err)
 			}
-			return posts, http.StatusOK
+			page.Next = next
+			break
 		}
 	}
+
+	return page, nil
 }
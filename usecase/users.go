@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+
+	"subd/cursor"
+	"subd/domain"
+	"subd/models"
+)
+
+// FindUsers pages through the user directory; after is the cursor of the
+// previous page, empty for the first one. Users are ordered by relevance
+// when there is a query and by nickname otherwise, unless asked for.
+func (s Smth) FindUsers(ctx context.Context, query models.UserQuery, after string) (models.UsersPage, error) {
+	if query.Sort == "" {
+		query.Sort = models.UsersByNickname
+		if query.Text != "" {
+			query.Sort = models.UsersByRelevance
+		}
+	}
+	if query.Forum != "" {
+		forum, status := s.repo.GetForum(ctx, query.Forum)
+		if err := statusError(status, forumNotFound(query.Forum)); err != nil {
+			return models.UsersPage{}, err
+		}
+		query.Forum = forum.Slug
+	}
+	if after != "" {
+		query.After = &models.UserKey{}
+		if err := decodeCursor(after, query.After); err != nil {
+			return models.UsersPage{}, err
+		}
+		if err := checkDirection(after, query.After.Desc, query.Desc); err != nil {
+			return models.UsersPage{}, err
+		}
+	}
+
+	limit := query.Limit
+	query.Limit++
+	matches, err := s.repo.FindUsers(ctx, query)
+	if err != nil {
+		return models.UsersPage{}, domain.Internal(err)
+	}
+
+	page := models.UsersPage{Users: models.Users{}}
+	if len(matches) > limit {
+		matches = matches[:limit]
+		key := matches[limit-1].Key()
+		key.Desc = query.Desc
+		next, err := cursor.Encode(key)
+		if err != nil {
+			return models.UsersPage{}, domain.Internal(err)
+		}
+		page.Next = next
+	}
+	for _, m := range matches {
+		page.Users = append(page.Users, m.User)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"subd/auth"
+	"subd/domain"
+	"subd/models"
+)
+
+func webhookNotFound(id int) error {
+	return domain.ErrWebhookNotFound.With("Can't find webhook with id "+fmt.Sprint(id), "id", id)
+}
+
+// hookManager checks that the caller manages the webhooks of forum and
+// returns its slug as stored: the owner of a forum does, and admins manage
+// every webhook, site-wide ones included. Webhooks hand out every change,
+// so anonymous requests are refused whatever the settings.
+func (s Smth) hookManager(ctx context.Context, forum string) (string, error) {
+	if forum != "" {
+		f, status := s.repo.GetForum(ctx, forum)
+		if err := statusError(status, forumNotFound(forum)); err != nil {
+			return "", err
+		}
+		return f.Slug, s.owner(ctx, f)
+	}
+
+	caller, ok := auth.CallerFrom(ctx)
+	if !ok {
+		return "", domain.ErrUnauthenticated.With("Sign in to manage site-wide webhooks")
+	}
+	if caller.IsAdmin() {
+		return "", nil
+	}
+
+	return "", domain.ErrForbidden.With("Only admins manage site-wide webhooks")
+}
+
+// webhook returns a webhook the caller manages.
+func (s Smth) webhook(ctx context.Context, id int) (models.Webhook, error) {
+	hook, status := s.repo.GetWebhook(ctx, id)
+	if err := statusError(status, webhookNotFound(id)); err != nil {
+		return models.Webhook{}, err
+	}
+	if _, err := s.hookManager(ctx, hook.Forum); err != nil {
+		return models.Webhook{}, err
+	}
+	hook.Secret = ""
+
+	return hook, nil
+}
+
+// CreateWebhook subscribes a URL to the events of a forum, or of the whole
+// site without one. The secret is made up when none is given; it is only
+// returned here.
+func (s Smth) CreateWebhook(ctx context.Context, hook models.Webhook) (models.Webhook, error) {
+	forum, err := s.hookManager(ctx, hook.Forum)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	hook.Forum = forum
+
+	events := []string{}
+	seen := make(map[string]bool)
+	for _, kind := range hook.Events {
+		if !knownHook(kind) {
+			return models.Webhook{}, domain.ErrValidation.With("Request does not pass validation",
+				"fields", models.ValidationError{{Field: "events", Rule: "hooktype",
+					Message: "must be one of " + strings.Join(models.HookTypes, ", ")}})
+		}
+		if !seen[kind] {
+			seen[kind] = true
+			events = append(events, kind)
+		}
+	}
+	hook.Events = events
+
+	if hook.Secret == "" {
+		secret, err := auth.NewToken()
+		if err != nil {
+			return models.Webhook{}, domain.Internal(err)
+		}
+		hook.Secret = secret
+	}
+
+	if err := s.repo.AddWebhook(ctx, &hook); err != nil {
+		return models.Webhook{}, domain.Internal(err)
+	}
+
+	return hook, nil
+}
+
+func knownHook(kind string) bool {
+	for _, known := range models.HookTypes {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhooks lists the webhooks of a forum, or every webhook without one.
+func (s Smth) Webhooks(ctx context.Context, forum string) (models.Webhooks, error) {
+	forum, err := s.hookManager(ctx, forum)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks, err := s.repo.GetWebhooks(ctx, forum)
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+
+	return hooks, nil
+}
+
+func (s Smth) Webhook(ctx context.Context, id int) (models.Webhook, error) {
+	return s.webhook(ctx, id)
+}
+
+// DeleteWebhook drops a webhook along with its deliveries.
+func (s Smth) DeleteWebhook(ctx context.Context, id int) error {
+	if _, err := s.webhook(ctx, id); err != nil {
+		return err
+	}
+
+	return statusError(s.repo.DeleteWebhook(ctx, id), webhookNotFound(id))
+}
+
+// Deliveries lists the latest deliveries to a webhook, newest first,
+// optionally of one state.
+func (s Smth) Deliveries(ctx context.Context, id int, state string, limit int) (models.Deliveries, error) {
+	if _, err := s.webhook(ctx, id); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.repo.GetDeliveries(ctx, id, state, limit)
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver tries a delivery again from scratch, whatever its state: a
+// dead one gets all its attempts back.
+func (s Smth) Redeliver(ctx context.Context, id int, delivery int64) (models.Delivery, error) {
+	if _, err := s.webhook(ctx, id); err != nil {
+		return models.Delivery{}, err
+	}
+
+	d, status := s.repo.Redeliver(ctx, id, delivery)
+	err := statusError(status, domain.ErrDeliveryNotFound.With(
+		"Can't find delivery "+fmt.Sprint(delivery)+" of webhook "+fmt.Sprint(id),
+		"webhook", id, "id", delivery))
+	if err != nil {
+		return models.Delivery{}, err
+	}
+
+	return d, nil
+}
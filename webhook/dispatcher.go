@@ -0,0 +1,248 @@
+// Package webhook posts the events of the outbox to the webhooks taking
+// them, retrying failed deliveries with exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	smth "subd"
+	"subd/models"
+)
+
+const (
+	// batch is how many outbox events or deliveries are taken at once.
+	batch = 100
+	// workers bounds the deliveries made at the same time.
+	workers = 8
+	// poll is how often the dispatcher looks for work.
+	poll = time.Second
+	// pruneEvery is how often finished deliveries past their retention are
+	// dropped.
+	pruneEvery = time.Hour
+	// maxBackoff caps the pause between two attempts.
+	maxBackoff = 6 * time.Hour
+	// maxError is how much of a failure is kept with the delivery.
+	maxError = 512
+)
+
+// Headers of a delivery.
+const (
+	HeaderEvent     = "X-Subd-Event"
+	HeaderDelivery  = "X-Subd-Delivery"
+	HeaderTimestamp = "X-Subd-Timestamp"
+	HeaderSignature = "X-Subd-Signature"
+)
+
+type Options struct {
+	// MaxAttempts is how many times a delivery is tried before it is dead.
+	MaxAttempts int
+	// Backoff is the pause after the first failed attempt; it doubles with
+	// every further one.
+	Backoff time.Duration
+	// Timeout bounds a single attempt.
+	Timeout time.Duration
+	// Retention is how long finished deliveries are kept.
+	Retention time.Duration
+	// AllowPrivate lets deliveries reach loopback, private and link-local
+	// addresses, which are refused by default.
+	AllowPrivate bool
+}
+
+// Dispatcher turns the outbox into deliveries and makes them. Several
+// servers sharing a database may run one each: events and deliveries are
+// claimed, so that every attempt is made by a single dispatcher.
+type Dispatcher struct {
+	repo   smth.Repository
+	opts   Options
+	client *http.Client
+}
+
+func NewDispatcher(repo smth.Repository, opts Options) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		opts:   opts,
+		client: newClient(opts),
+	}
+}
+
+// Sign returns the X-Subd-Signature of a payload sent at timestamp: the
+// hex HMAC-SHA256 of "timestamp.body" keyed with the webhook secret.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	io.WriteString(mac, timestamp)
+	io.WriteString(mac, ".")
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run dispatches until ctx is done, then waits for the attempts under way.
+// A delivery cut short is claimed again once its lease runs out.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	lastPrune := time.Now()
+	for {
+		d.dispatch(ctx)
+		d.deliver(ctx)
+		if time.Since(lastPrune) >= pruneEvery {
+			lastPrune = time.Now()
+			err := d.repo.PruneDeliveries(ctx, lastPrune.Add(-d.opts.Retention))
+			if err != nil && ctx.Err() == nil {
+				log.Printf("webhook: pruning deliveries: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch empties the outbox into deliveries.
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		taken, err := d.repo.DispatchOutbox(ctx, batch)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("webhook: dispatching the outbox: %v", err)
+			}
+			return
+		}
+		if taken < batch {
+			return
+		}
+	}
+}
+
+// deliver makes the due deliveries. They are leased for twice the attempt
+// timeout, so that another dispatcher does not take them meanwhile.
+func (d *Dispatcher) deliver(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := time.Now()
+		due, err := d.repo.ClaimDeliveries(ctx, now, now.Add(2*d.opts.Timeout), batch)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("webhook: claiming deliveries: %v", err)
+			}
+			return
+		}
+
+		jobs := make(chan models.DueDelivery)
+		var wg sync.WaitGroup
+		for i := 0; i < workers && i < len(due); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for delivery := range jobs {
+					d.attempt(ctx, delivery)
+				}
+			}()
+		}
+		for _, delivery := range due {
+			jobs <- delivery
+		}
+		close(jobs)
+		wg.Wait()
+
+		if len(due) < batch {
+			return
+		}
+	}
+}
+
+// attempt posts one delivery and records how it went.
+func (d *Dispatcher) attempt(ctx context.Context, delivery models.DueDelivery) {
+	status, err := d.post(ctx, delivery)
+	if ctx.Err() != nil {
+		// Shutting down; the lease brings the delivery back.
+		return
+	}
+
+	result := models.DeliveryResult{Status: status}
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+	case status < 200 || status > 299:
+		result.Error = http.StatusText(status)
+	default:
+		result.Delivered = true
+	}
+	if len(result.Error) > maxError {
+		result.Error = result.Error[:maxError]
+	}
+	if !result.Delivered {
+		result.Dead = delivery.Attempts+1 >= d.opts.MaxAttempts
+		result.NextAttempt = time.Now().Add(d.backoff(delivery.Attempts))
+	}
+
+	if err := d.repo.RecordDelivery(context.Background(), delivery.Id, result); err != nil {
+		log.Printf("webhook: recording delivery %d: %v", delivery.Id, err)
+	}
+}
+
+// backoff is the pause after the given number of earlier failed attempts
+// and the one that just failed.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	pause := d.opts.Backoff
+	for i := 0; i < attempts && pause < maxBackoff; i++ {
+		pause *= 2
+	}
+	if pause > maxBackoff {
+		pause = maxBackoff
+	}
+	return pause
+}
+
+func (d *Dispatcher) post(ctx context.Context, delivery models.DueDelivery) (int, error) {
+	body, err := delivery.Event.MarshalJSON()
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "subd-webhook")
+	req.Header.Set(HeaderEvent, delivery.Event.Type)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.Id, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("posting the event: %w", unwrapURL(err))
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64<<10))
+
+	return res.StatusCode, nil
+}
+
+// unwrapURL drops the method and URL net/http puts in front of a failure,
+// which the delivery already shows.
+func unwrapURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
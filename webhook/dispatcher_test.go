@@ -0,0 +1,246 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	smth "subd"
+	"subd/models"
+	"subd/repository/memory"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const secret = "0123456789abcdef"
+
+// receiver records the deliveries posted to it and answers them with the
+// statuses it is given, then with 200.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+// hookFixture points a site-wide webhook at url and makes a change it
+// takes, so that the outbox holds one event for it.
+func hookFixture(t *testing.T, url string) (smth.Repository, models.Webhook) {
+	t.Helper()
+	ctx := context.Background()
+	repo := memory.NewMemoryDatabase()
+
+	hook := models.Webhook{Url: url, Secret: secret}
+	if err := repo.AddWebhook(ctx, &hook); err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{Nickname: "alice", Fullname: "Alice", Email: strfmt.Email("alice@example.com")}
+	if err := repo.CreateUser(ctx, "alice", user); err != nil {
+		t.Fatal(err)
+	}
+
+	return repo, hook
+}
+
+func testDispatcher(repo smth.Repository, allowPrivate bool) *Dispatcher {
+	return NewDispatcher(repo, Options{
+		MaxAttempts:  3,
+		Backoff:      time.Millisecond,
+		Timeout:      time.Second,
+		Retention:    time.Hour,
+		AllowPrivate: allowPrivate,
+	})
+}
+
+// round does what Run does at every tick.
+func round(d *Dispatcher) {
+	d.dispatch(context.Background())
+	d.deliver(context.Background())
+}
+
+// delivery returns the only delivery to a webhook.
+func delivery(t *testing.T, repo smth.Repository, hook models.Webhook) models.Delivery {
+	t.Helper()
+	deliveries, err := repo.GetDeliveries(context.Background(), hook.Id, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("webhook has %d deliveries, want 1", len(deliveries))
+	}
+	return deliveries[0]
+}
+
+func TestDeliverySignature(t *testing.T) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+	repo, hook := hookFixture(t, server.URL)
+
+	round(testDispatcher(repo, true))
+
+	if rc.count() != 1 {
+		t.Fatalf("receiver got %d deliveries, want 1", rc.count())
+	}
+	req, body := rc.requests[0], rc.bodies[0]
+	if got := req.Header.Get(HeaderEvent); got != models.HookUserCreated {
+		t.Errorf("%s = %q, want %q", HeaderEvent, got, models.HookUserCreated)
+	}
+	want := Sign(secret, req.Header.Get(HeaderTimestamp), body)
+	if got := req.Header.Get(HeaderSignature); got != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+	}
+	if !strings.Contains(string(body), `"alice"`) {
+		t.Errorf("body %s lacks the user", body)
+	}
+	if d := delivery(t, repo, hook); d.State != models.DeliveryDelivered || d.Attempts != 1 {
+		t.Errorf("delivery is %s after %d attempts, want delivered after 1", d.State, d.Attempts)
+	}
+}
+
+func TestDeliveryRetries(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusInternalServerError}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+	repo, hook := hookFixture(t, server.URL)
+	d := testDispatcher(repo, true)
+
+	round(d)
+	first := delivery(t, repo, hook)
+	if first.State != models.DeliveryPending || first.LastStatus != http.StatusInternalServerError {
+		t.Fatalf("delivery is %s with status %d, want pending with 500", first.State, first.LastStatus)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	round(d)
+	if rc.count() != 2 {
+		t.Fatalf("receiver got %d deliveries, want 2", rc.count())
+	}
+	if rc.requests[0].Header.Get(HeaderDelivery) != rc.requests[1].Header.Get(HeaderDelivery) {
+		t.Error("the retry carries another delivery id")
+	}
+	if second := delivery(t, repo, hook); second.State != models.DeliveryDelivered || second.Attempts != 2 {
+		t.Errorf("delivery is %s after %d attempts, want delivered after 2", second.State, second.Attempts)
+	}
+}
+
+func TestDeliveryLease(t *testing.T) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+	repo, hook := hookFixture(t, server.URL)
+	d := testDispatcher(repo, true)
+	ctx := context.Background()
+
+	// Another dispatcher claims the delivery and crashes before posting it.
+	d.dispatch(ctx)
+	now := time.Now()
+	claimed, err := repo.ClaimDeliveries(ctx, now, now.Add(50*time.Millisecond), batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 1 {
+		t.Fatalf("claimed %d deliveries, want 1", len(claimed))
+	}
+
+	d.deliver(ctx)
+	if rc.count() != 0 {
+		t.Fatal("a leased delivery was posted")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	d.deliver(ctx)
+	if rc.count() != 1 {
+		t.Fatalf("receiver got %d deliveries after the lease ran out, want 1", rc.count())
+	}
+	if got := delivery(t, repo, hook); got.State != models.DeliveryDelivered {
+		t.Errorf("delivery is %s, want delivered", got.State)
+	}
+}
+
+func TestDeliveryRefusesPrivateAddresses(t *testing.T) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+	repo, hook := hookFixture(t, server.URL)
+
+	round(testDispatcher(repo, false))
+
+	if rc.count() != 0 {
+		t.Fatal("a delivery reached a loopback address")
+	}
+	got := delivery(t, repo, hook)
+	if got.State != models.DeliveryPending || !strings.Contains(got.LastError, "not a public address") {
+		t.Errorf("delivery is %s with error %q, want pending, refused", got.State, got.LastError)
+	}
+}
+
+func TestDeliveryFollowsNoRedirect(t *testing.T) {
+	target := &receiver{}
+	inside := httptest.NewServer(target)
+	defer inside.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(inside.URL, http.StatusTemporaryRedirect))
+	defer redirect.Close()
+	repo, hook := hookFixture(t, redirect.URL)
+
+	round(testDispatcher(repo, true))
+
+	if target.count() != 0 {
+		t.Fatal("the delivery followed a redirect")
+	}
+	if got := delivery(t, repo, hook); got.State != models.DeliveryPending || got.LastStatus != http.StatusTemporaryRedirect {
+		t.Errorf("delivery is %s with status %d, want pending with 307", got.State, got.LastStatus)
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		ok      bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", true},
+		{"127.0.0.1:80", false},
+		{"10.1.2.3:80", false},
+		{"172.20.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"100.64.0.1:80", false},
+		{"0.0.0.0:80", false},
+		{"[::1]:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"[fd00::1]:80", false},
+		{"[fe80::1]:80", false},
+	}
+	for _, tt := range tests {
+		err := checkAddress("tcp", tt.address, nil)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkAddress(%s) = %v, want ok %v", tt.address, err, tt.ok)
+		}
+	}
+}